@@ -0,0 +1,99 @@
+package alertgroup
+
+import (
+	"errors"
+	"time"
+)
+
+type State string
+
+const (
+	StateFiring       State = "firing"
+	StateAcknowledged State = "acknowledged"
+	StateResolved     State = "resolved"
+	StateSilenced     State = "silenced"
+)
+
+type Action string
+
+const (
+	ActionAcknowledge   Action = "acknowledge"
+	ActionAttach        Action = "attach"
+	ActionResolve       Action = "resolve"
+	ActionSilence       Action = "silence"
+	ActionUnacknowledge Action = "unacknowledge"
+	ActionUnattach      Action = "unattach"
+	ActionUnresolve     Action = "unresolve"
+	ActionUnsilence     Action = "unsilence"
+)
+
+var (
+	ErrAlertGroupNotFound = errors.New("alert group not found")
+	ErrInvalidTransition  = errors.New("invalid alert group state transition")
+	ErrInvalidAttachment  = errors.New("invalid alert group attachment")
+)
+
+type AlertGroup struct {
+	ID            string
+	IntegrationID string
+	TeamID        string
+	Title         string
+	Message       string
+	Labels        map[string]string
+	State         State
+	// GroupingKey identifies which alerts received by the integration are
+	// grouped into the alert group.
+	GroupingKey string
+	RouteID     string
+	AlertsCount int
+
+	AcknowledgedAt *time.Time
+	AcknowledgedBy string
+	ResolvedAt     *time.Time
+	ResolvedBy     string
+	SilencedAt     *time.Time
+	SilencedBy     string
+	// SilencedUntil is nil when the alert group is silenced until it is
+	// manually unsilenced.
+	SilencedUntil *time.Time
+
+	// RootAlertGroupID is set when the alert group has been attached to
+	// another alert group, which is treated as its root cause.
+	RootAlertGroupID string
+
+	// Number is a short sequential number assigned when the alert group is
+	// created, which is easier to type than its ID in replies such as
+	// "ack 1234".
+	Number int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Transition records a single change to the state of an alert group.
+type Transition struct {
+	ID           string
+	AlertGroupID string
+	Action       Action
+	Actor        string
+	From         State
+	To           State
+	// RootAlertGroupID is the alert group attached to or detached from for
+	// ActionAttach and ActionUnattach.
+	RootAlertGroupID string
+	// SilencedUntil is set for ActionSilence when the silence expires.
+	SilencedUntil *time.Time
+	CreatedAt     time.Time
+}
+
+// IsAttached reports whether the alert group is attached to a root alert
+// group.
+func (g *AlertGroup) IsAttached() bool {
+	return g.RootAlertGroupID != ""
+}
+
+// IsActive reports whether escalations should be running for the alert
+// group.
+func (g *AlertGroup) IsActive() bool {
+	return g.State == StateFiring && !g.IsAttached()
+}
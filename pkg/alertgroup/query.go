@@ -0,0 +1,144 @@
+package alertgroup
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultQueryLimit = 25
+	MaxQueryLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid alert group cursor")
+	ErrInvalidQuery  = errors.New("invalid alert group query")
+)
+
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+)
+
+// Query filters and pages through alert groups. Empty fields do not filter.
+type Query struct {
+	States         []State
+	IntegrationIDs []string
+	TeamIDs        []string
+	// Labels must all be present on the alert group with the same value.
+	Labels map[string]string
+	// StartedAfter and StartedBefore bound when the alert group was
+	// created, inclusive and exclusive respectively.
+	StartedAfter   *time.Time
+	StartedBefore  *time.Time
+	AcknowledgedBy string
+	// InvolvedUser matches alert groups on which the user has performed any
+	// action.
+	InvolvedUser string
+	// Search matches alert groups whose title or message contain every
+	// whitespace separated term, ignoring case.
+	Search string
+
+	Sort       SortField
+	Descending bool
+	// Cursor continues a previous query from where its page ended.
+	Cursor string
+	Limit  int
+}
+
+type Page struct {
+	AlertGroups []*AlertGroup
+	// NextCursor is empty when there are no more results.
+	NextCursor string
+}
+
+// cursor identifies the position of the last alert group of a page in the
+// sort order, the ID breaking ties between equal sort values.
+type cursor struct {
+	Value time.Time
+	ID    string
+}
+
+func (q *Query) normalize() error {
+	switch q.Sort {
+	case "":
+		q.Sort = SortCreatedAt
+	case SortCreatedAt, SortUpdatedAt:
+	default:
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, q.Sort)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultQueryLimit
+	}
+
+	if q.Limit > MaxQueryLimit {
+		q.Limit = MaxQueryLimit
+	}
+
+	return nil
+}
+
+func (q *Query) sortValue(g *AlertGroup) time.Time {
+	if q.Sort == SortUpdatedAt {
+		return g.UpdatedAt
+	}
+
+	return g.CreatedAt
+}
+
+func (q *Query) cursorOf(g *AlertGroup) cursor {
+	return cursor{Value: q.sortValue(g), ID: g.ID}
+}
+
+// matchesText reports whether the alert group has the labels of the query
+// and contains its search terms, the filters which are not looked up by the
+// columns of the store.
+func (q *Query) matchesText(g *AlertGroup) bool {
+	for k, v := range q.Labels {
+		if lv, ok := g.Labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+
+	if q.Search != "" {
+		text := strings.ToLower(g.Title + "\n" + g.Message)
+		for _, term := range strings.Fields(strings.ToLower(q.Search)) {
+			if !strings.Contains(text, term) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func encodeCursor(c cursor) string {
+	raw := c.Value.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	value, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	return cursor{Value: t, ID: id}, nil
+}
@@ -0,0 +1,427 @@
+package alertgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+const (
+	// JobCreated is published when an alert group is created, with the
+	// alert group ID as argument.
+	JobCreated worker.JobType = "alert_group_created"
+
+	// JobAlertAdded is published when another alert is grouped into an
+	// alert group, with the alert group ID as argument.
+	JobAlertAdded worker.JobType = "alert_group_alert_added"
+
+	// JobStateChanged is published whenever an alert group changes state or
+	// is attached or unattached, with the alert group ID and the action as
+	// arguments. Escalations use it to stop or resume.
+	JobStateChanged worker.JobType = "alert_group_state_changed"
+
+	// JobSilenceExpired is published, delayed by the silence duration, when
+	// an alert group is silenced for a limited time.
+	JobSilenceExpired worker.JobType = "alert_group_silence_expired"
+)
+
+const (
+	// SystemActor is recorded as the actor of transitions which were not
+	// made by a user.
+	SystemActor = "system"
+
+	// SourceActor is recorded as the actor of transitions requested by the
+	// alert source, such as resolving a group when the alert clears.
+	SourceActor = "source"
+)
+
+// lockStates is the lock held while alert groups change, as transitions
+// cascade to the alert groups attached to them.
+const lockStates = "alert_group_states"
+
+type Service struct {
+	store  Store
+	worker worker.Worker
+	now    func() time.Time
+}
+
+func NewService(store Store, w worker.Worker) *Service {
+	return &Service{
+		store:  store,
+		worker: w,
+		now:    time.Now,
+	}
+}
+
+// Create stores a new firing alert group.
+func (s *Service) Create(ctx context.Context, g *AlertGroup) error {
+	now := s.now()
+
+	g.ID = uuid.NewString()
+	g.State = StateFiring
+	g.AlertsCount = 1
+	g.CreatedAt = now
+	g.UpdatedAt = now
+
+	if err := s.store.Create(ctx, g); err != nil {
+		return err
+	}
+
+	s.enqueue(ctx, 0, "publish creation", JobCreated, g.ID)
+
+	return nil
+}
+
+// FindUnresolved returns the alert group of the integration which alerts with
+// the grouping key are currently being added to.
+func (s *Service) FindUnresolved(ctx context.Context, integrationID, groupingKey string) (*AlertGroup, error) {
+	return s.store.FindUnresolved(ctx, integrationID, groupingKey)
+}
+
+// AddAlert records another alert being grouped into the alert group.
+func (s *Service) AddAlert(ctx context.Context, id string) (*AlertGroup, error) {
+	return s.lockedGroup(ctx, func(ctx context.Context) (*AlertGroup, error) {
+		g, err := s.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		g.AlertsCount++
+		g.UpdatedAt = s.now()
+
+		if err := s.store.Update(ctx, g); err != nil {
+			return nil, err
+		}
+
+		s.enqueue(ctx, 0, "publish new alert", JobAlertAdded, g.ID)
+
+		return g, nil
+	})
+}
+
+// Grouping runs fn holding the lock of the grouping key of the integration,
+// so that alerts with the grouping key are grouped one at a time by all
+// processes and only one alert group is created for them.
+func (s *Service) Grouping(ctx context.Context, integrationID, groupingKey string, fn func(ctx context.Context) error) error {
+	return s.locked(ctx, "alert_group_grouping:"+integrationID+":"+groupingKey, fn)
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*AlertGroup, error) {
+	return s.store.Get(ctx, id)
+}
+
+func (s *Service) GetByNumber(ctx context.Context, number int) (*AlertGroup, error) {
+	return s.store.GetByNumber(ctx, number)
+}
+
+func (s *Service) Search(ctx context.Context, q *Query) (*Page, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+
+	return s.store.Search(ctx, q)
+}
+
+func (s *Service) Transitions(ctx context.Context, id string) ([]*Transition, error) {
+	if _, err := s.store.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListTransitions(ctx, id)
+}
+
+func (s *Service) Acknowledge(ctx context.Context, id, actor string) (*AlertGroup, error) {
+	return s.transition(ctx, id, actor, ActionAcknowledge, nil)
+}
+
+func (s *Service) Unacknowledge(ctx context.Context, id, actor string) (*AlertGroup, error) {
+	return s.transition(ctx, id, actor, ActionUnacknowledge, nil)
+}
+
+func (s *Service) Resolve(ctx context.Context, id, actor string) (*AlertGroup, error) {
+	return s.transition(ctx, id, actor, ActionResolve, nil)
+}
+
+func (s *Service) Unresolve(ctx context.Context, id, actor string) (*AlertGroup, error) {
+	return s.transition(ctx, id, actor, ActionUnresolve, nil)
+}
+
+// Silence silences the alert group for the duration, or until it is
+// unsilenced when the duration is zero.
+func (s *Service) Silence(ctx context.Context, id, actor string, duration time.Duration) (*AlertGroup, error) {
+	if duration < 0 {
+		return nil, fmt.Errorf("%w: silence duration must not be negative", ErrInvalidTransition)
+	}
+
+	var until *time.Time
+	if duration > 0 {
+		t := s.now().Add(duration)
+		until = &t
+	}
+
+	return s.transition(ctx, id, actor, ActionSilence, until)
+}
+
+func (s *Service) Unsilence(ctx context.Context, id, actor string) (*AlertGroup, error) {
+	return s.transition(ctx, id, actor, ActionUnsilence, nil)
+}
+
+// Attach attaches the alert group to the root alert group, which stops its
+// escalation until it is unattached.
+func (s *Service) Attach(ctx context.Context, id, rootID, actor string) (*AlertGroup, error) {
+	return s.lockedGroup(ctx, func(ctx context.Context) (*AlertGroup, error) {
+		if id == rootID {
+			return nil, fmt.Errorf("%w: an alert group cannot be attached to itself", ErrInvalidAttachment)
+		}
+
+		g, err := s.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		root, err := s.store.Get(ctx, rootID)
+		if err != nil {
+			return nil, err
+		}
+
+		if g.IsAttached() {
+			return nil, fmt.Errorf("%w: alert group is already attached to %s", ErrInvalidAttachment, g.RootAlertGroupID)
+		}
+
+		if root.IsAttached() {
+			return nil, fmt.Errorf("%w: root alert group is itself attached to %s", ErrInvalidAttachment, root.RootAlertGroupID)
+		}
+
+		attached, err := s.store.ListAttached(ctx, g.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(attached) > 0 {
+			return nil, fmt.Errorf("%w: alert group has other alert groups attached to it", ErrInvalidAttachment)
+		}
+
+		g.RootAlertGroupID = root.ID
+		g.UpdatedAt = s.now()
+
+		if err := s.save(ctx, g, ActionAttach, actor, g.State); err != nil {
+			return nil, err
+		}
+
+		return g, nil
+	})
+}
+
+func (s *Service) Unattach(ctx context.Context, id, actor string) (*AlertGroup, error) {
+	return s.lockedGroup(ctx, func(ctx context.Context) (*AlertGroup, error) {
+		g, err := s.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !g.IsAttached() {
+			return nil, fmt.Errorf("%w: alert group is not attached", ErrInvalidAttachment)
+		}
+
+		rootID := g.RootAlertGroupID
+		g.RootAlertGroupID = ""
+		g.UpdatedAt = s.now()
+
+		t := &Transition{RootAlertGroupID: rootID}
+		if err := s.record(ctx, g, t, ActionUnattach, actor, g.State); err != nil {
+			return nil, err
+		}
+
+		return g, nil
+	})
+}
+
+// HandleSilenceExpired unsilences an alert group once its silence has run
+// out.
+func (s *Service) HandleSilenceExpired(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	g, err := s.store.Get(ctx, job.Args[0])
+	if err != nil {
+		fmt.Printf("Failed to expire silence of alert group %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	// The alert group may have been unsilenced, or silenced again for
+	// longer, since the job was published.
+	if g.State != StateSilenced || g.SilencedUntil == nil || s.now().Before(*g.SilencedUntil) {
+		return
+	}
+
+	if _, err := s.Unsilence(ctx, g.ID, SystemActor); err != nil && !errors.Is(err, ErrInvalidTransition) {
+		fmt.Printf("Failed to expire silence of alert group %s: %s\n", g.ID, err)
+	}
+}
+
+// transition applies the action to the alert group and then to any alert
+// groups attached to it, skipping those for which it is not valid.
+func (s *Service) transition(ctx context.Context, id, actor string, action Action, until *time.Time) (*AlertGroup, error) {
+	return s.lockedGroup(ctx, func(ctx context.Context) (*AlertGroup, error) {
+		g, err := s.store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.applyAndSave(ctx, g, action, actor, until); err != nil {
+			return nil, err
+		}
+
+		attached, err := s.store.ListAttached(ctx, g.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range attached {
+			err := s.applyAndSave(ctx, a, action, actor, until)
+			if err != nil && !errors.Is(err, ErrInvalidTransition) {
+				return nil, err
+			}
+		}
+
+		return g, nil
+	})
+}
+
+func (s *Service) applyAndSave(ctx context.Context, g *AlertGroup, action Action, actor string, until *time.Time) error {
+	from := g.State
+	if err := g.apply(action, actor, s.now(), until); err != nil {
+		return err
+	}
+
+	return s.save(ctx, g, action, actor, from)
+}
+
+func (s *Service) save(ctx context.Context, g *AlertGroup, action Action, actor string, from State) error {
+	t := &Transition{}
+	if action == ActionAttach {
+		t.RootAlertGroupID = g.RootAlertGroupID
+	}
+
+	return s.record(ctx, g, t, action, actor, from)
+}
+
+// record persists the alert group together with the transition and
+// publishes the jobs that let escalations react to it.
+func (s *Service) record(ctx context.Context, g *AlertGroup, t *Transition, action Action, actor string, from State) error {
+	if err := s.store.Update(ctx, g); err != nil {
+		return err
+	}
+
+	t.ID = uuid.NewString()
+	t.AlertGroupID = g.ID
+	t.Action = action
+	t.Actor = actor
+	t.From = from
+	t.To = g.State
+	t.CreatedAt = g.UpdatedAt
+
+	if action == ActionSilence {
+		t.SilencedUntil = g.SilencedUntil
+	}
+
+	if err := s.store.AddTransition(ctx, t); err != nil {
+		return err
+	}
+
+	s.enqueue(ctx, 0, "publish state change", JobStateChanged, g.ID, string(action))
+
+	if action == ActionSilence && g.SilencedUntil != nil {
+		s.enqueue(ctx, g.SilencedUntil.Sub(g.UpdatedAt), "schedule silence expiry", JobSilenceExpired, g.ID)
+	}
+
+	return nil
+}
+
+// pendingJob is a job enqueued in a transaction, published once the
+// transaction has been committed.
+type pendingJob struct {
+	delay  time.Duration
+	action string
+	t      worker.JobType
+	args   []string
+}
+
+type pendingJobsKey struct{}
+
+// locked runs fn in a transaction of the store holding the lock of the key,
+// so that all processes sharing the store make their changes one after the
+// other. The jobs fn enqueues are published once its changes have been
+// committed, so their handlers find them.
+func (s *Service) locked(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	// Nested calls join the outer transaction, which publishes the jobs.
+	if _, ok := ctx.Value(pendingJobsKey{}).(*[]pendingJob); ok {
+		return s.store.InTransaction(ctx, key, fn)
+	}
+
+	var pending []pendingJob
+	err := s.store.InTransaction(ctx, key, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, pendingJobsKey{}, &pending))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, j := range pending {
+		s.publish(ctx, j)
+	}
+
+	return nil
+}
+
+// lockedGroup runs fn holding the lock of the states of alert groups, and
+// returns the alert group fn returns.
+func (s *Service) lockedGroup(ctx context.Context, fn func(ctx context.Context) (*AlertGroup, error)) (*AlertGroup, error) {
+	var g *AlertGroup
+	err := s.locked(ctx, lockStates, func(ctx context.Context) error {
+		var err error
+		g, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// enqueue publishes the job of the alert group, its first argument, after
+// the delay. Within locked it is published once the transaction has been
+// committed.
+func (s *Service) enqueue(ctx context.Context, delay time.Duration, action string, t worker.JobType, args ...string) {
+	j := pendingJob{delay: delay, action: action, t: t, args: args}
+
+	if pending, ok := ctx.Value(pendingJobsKey{}).(*[]pendingJob); ok {
+		*pending = append(*pending, j)
+		return
+	}
+
+	s.publish(ctx, j)
+}
+
+// publish publishes the job. The changes it is about have been stored at this
+// point, so failing to publish is logged rather than reported back to the
+// caller.
+func (s *Service) publish(ctx context.Context, j pendingJob) {
+	var err error
+	if j.delay > 0 {
+		err = s.worker.EnqueueIn(ctx, j.delay, j.t, j.args...)
+	} else {
+		err = s.worker.Enqueue(ctx, j.t, j.args...)
+	}
+
+	if err != nil {
+		fmt.Printf("Failed to %s of alert group %s: %s\n", j.action, j.args[0], err)
+	}
+}
@@ -0,0 +1,392 @@
+package alertgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// nopWorker drops the jobs published instead of sending them anywhere.
+type nopWorker struct{}
+
+func (nopWorker) Enqueue(ctx context.Context, t worker.JobType, args ...string) error {
+	return nil
+}
+
+func (nopWorker) EnqueueIn(ctx context.Context, delay time.Duration, t worker.JobType, args ...string) error {
+	return nil
+}
+
+func (nopWorker) RegisterHandler(worker.JobType, worker.JobHandler, any) {}
+
+func (nopWorker) Stop(ctx context.Context) {}
+
+func newTestService(t *testing.T) (*Service, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+	store, err := NewSQLStore(sqlstore.InitTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+
+	s := NewService(store, nopWorker{})
+	s.now = func() time.Time { return now }
+
+	return s, &now
+}
+
+// createInState creates an alert group and moves it to the state.
+func createInState(t *testing.T, s *Service, state State) *AlertGroup {
+	t.Helper()
+
+	ctx := context.Background()
+
+	g := &AlertGroup{IntegrationID: "i", Title: string(state)}
+	if err := s.Create(ctx, g); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var err error
+	switch state {
+	case StateAcknowledged:
+		_, err = s.Acknowledge(ctx, g.ID, "alice")
+	case StateResolved:
+		_, err = s.Resolve(ctx, g.ID, "alice")
+	case StateSilenced:
+		_, err = s.Silence(ctx, g.ID, "alice", 0)
+	}
+	if err != nil {
+		t.Fatalf("moving alert group to %s: %v", state, err)
+	}
+
+	return g
+}
+
+func mustGet(t *testing.T, s *Service, id string) *AlertGroup {
+	t.Helper()
+
+	g, err := s.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	return g
+}
+
+func TestTransitionCascadesToAttached(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     State
+		action   func(s *Service, id string) (*AlertGroup, error)
+		attached []State
+		want     []State
+	}{
+		{
+			name: "acknowledge",
+			root: StateFiring,
+			action: func(s *Service, id string) (*AlertGroup, error) {
+				return s.Acknowledge(context.Background(), id, "bob")
+			},
+			attached: []State{StateFiring, StateSilenced, StateResolved},
+			want:     []State{StateAcknowledged, StateAcknowledged, StateResolved},
+		},
+		{
+			name: "resolve",
+			root: StateAcknowledged,
+			action: func(s *Service, id string) (*AlertGroup, error) {
+				return s.Resolve(context.Background(), id, "bob")
+			},
+			attached: []State{StateFiring, StateAcknowledged, StateResolved},
+			want:     []State{StateResolved, StateResolved, StateResolved},
+		},
+		{
+			name: "unresolve",
+			root: StateResolved,
+			action: func(s *Service, id string) (*AlertGroup, error) {
+				return s.Unresolve(context.Background(), id, "bob")
+			},
+			attached: []State{StateResolved, StateAcknowledged},
+			want:     []State{StateFiring, StateAcknowledged},
+		},
+		{
+			name: "silence",
+			root: StateFiring,
+			action: func(s *Service, id string) (*AlertGroup, error) {
+				return s.Silence(context.Background(), id, "bob", time.Hour)
+			},
+			attached: []State{StateFiring, StateAcknowledged, StateResolved},
+			want:     []State{StateSilenced, StateSilenced, StateResolved},
+		},
+		{
+			name: "unsilence",
+			root: StateSilenced,
+			action: func(s *Service, id string) (*AlertGroup, error) {
+				return s.Unsilence(context.Background(), id, "bob")
+			},
+			attached: []State{StateSilenced, StateFiring},
+			want:     []State{StateFiring, StateFiring},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			ctx := context.Background()
+
+			root := createInState(t, s, tt.root)
+
+			var attached []*AlertGroup
+			for _, state := range tt.attached {
+				g := createInState(t, s, state)
+				if _, err := s.Attach(ctx, g.ID, root.ID, "alice"); err != nil {
+					t.Fatalf("Attach() error = %v", err)
+				}
+				attached = append(attached, g)
+			}
+
+			if _, err := tt.action(s, root.ID); err != nil {
+				t.Fatalf("%s error = %v", tt.name, err)
+			}
+
+			for i, g := range attached {
+				if got := mustGet(t, s, g.ID).State; got != tt.want[i] {
+					t.Errorf("attached alert group which was %s is %s, want %s", tt.attached[i], got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTransitionDoesNotCascadeAfterUnattach(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	root := createInState(t, s, StateFiring)
+	g := createInState(t, s, StateFiring)
+
+	if _, err := s.Attach(ctx, g.ID, root.ID, "alice"); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+
+	if mustGet(t, s, g.ID).IsActive() {
+		t.Error("attached alert group is active")
+	}
+
+	unattached, err := s.Unattach(ctx, g.ID, "alice")
+	if err != nil {
+		t.Fatalf("Unattach() error = %v", err)
+	}
+
+	if !unattached.IsActive() {
+		t.Error("unattached firing alert group is not active")
+	}
+
+	if _, err := s.Resolve(ctx, root.ID, "bob"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if got := mustGet(t, s, g.ID).State; got != StateFiring {
+		t.Errorf("unattached alert group is %s, want %s", got, StateFiring)
+	}
+
+	transitions, err := s.Transitions(ctx, g.ID)
+	if err != nil {
+		t.Fatalf("Transitions() error = %v", err)
+	}
+
+	var actions []Action
+	for _, tr := range transitions {
+		actions = append(actions, tr.Action)
+		if tr.RootAlertGroupID != root.ID {
+			t.Errorf("%s transition has root %q, want %q", tr.Action, tr.RootAlertGroupID, root.ID)
+		}
+	}
+
+	if len(actions) != 2 || actions[0] != ActionAttach || actions[1] != ActionUnattach {
+		t.Errorf("transitions = %v, want [%s %s]", actions, ActionAttach, ActionUnattach)
+	}
+}
+
+func TestAttachErrors(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	root := createInState(t, s, StateFiring)
+	attached := createInState(t, s, StateFiring)
+	other := createInState(t, s, StateFiring)
+
+	if _, err := s.Attach(ctx, attached.ID, root.ID, "alice"); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		rootID  string
+		wantErr error
+	}{
+		{name: "to itself", id: other.ID, rootID: other.ID, wantErr: ErrInvalidAttachment},
+		{name: "already attached", id: attached.ID, rootID: other.ID, wantErr: ErrInvalidAttachment},
+		{name: "to an attached alert group", id: other.ID, rootID: attached.ID, wantErr: ErrInvalidAttachment},
+		{name: "with alert groups attached", id: root.ID, rootID: other.ID, wantErr: ErrInvalidAttachment},
+		{name: "unknown alert group", id: "missing", rootID: root.ID, wantErr: ErrAlertGroupNotFound},
+		{name: "unknown root", id: other.ID, rootID: "missing", wantErr: ErrAlertGroupNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Attach(ctx, tt.id, tt.rootID, "alice"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Attach() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := s.Unattach(ctx, other.ID, "alice"); !errors.Is(err, ErrInvalidAttachment) {
+		t.Errorf("Unattach() of an alert group which is not attached error = %v, want %v", err, ErrInvalidAttachment)
+	}
+}
+
+func TestHandleSilenceExpired(t *testing.T) {
+	tests := []struct {
+		name    string
+		silence time.Duration
+		elapsed time.Duration
+		want    State
+	}{
+		{name: "expired", silence: time.Hour, elapsed: time.Hour, want: StateFiring},
+		{name: "silenced again for longer", silence: 2 * time.Hour, elapsed: time.Hour, want: StateSilenced},
+		{name: "silenced until unsilenced", silence: 0, elapsed: time.Hour, want: StateSilenced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, now := newTestService(t)
+			ctx := context.Background()
+
+			g := createInState(t, s, StateFiring)
+			if _, err := s.Silence(ctx, g.ID, "alice", tt.silence); err != nil {
+				t.Fatalf("Silence() error = %v", err)
+			}
+
+			*now = now.Add(tt.elapsed)
+			s.HandleSilenceExpired(ctx, &worker.Job{Type: JobSilenceExpired, Args: []string{g.ID}})
+
+			if got := mustGet(t, s, g.ID).State; got != tt.want {
+				t.Errorf("state = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAlertFromSeveralProcesses(t *testing.T) {
+	ctx := context.Background()
+
+	// Each service has its own connection to the database, like the server
+	// and the standalone workers.
+	var services []*Service
+	for _, db := range sqlstore.InitTestDBs(t, 2) {
+		store, err := NewSQLStore(db)
+		if err != nil {
+			t.Fatalf("NewSQLStore() error = %v", err)
+		}
+		services = append(services, NewService(store, nopWorker{}))
+	}
+
+	g := &AlertGroup{IntegrationID: "i", Title: "many alerts"}
+	if err := services[0].Create(ctx, g); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	const perService = 20
+
+	var wg sync.WaitGroup
+	for _, s := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range perService {
+				if _, err := s.AddAlert(ctx, g.ID); err != nil {
+					t.Errorf("AddAlert() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := mustGet(t, services[1], g.ID).AlertsCount, 1+len(services)*perService; got != want {
+		t.Errorf("AlertsCount = %d, want %d", got, want)
+	}
+}
+
+// recordingWorker keeps the types of the jobs published.
+type recordingWorker struct {
+	nopWorker
+	published []worker.JobType
+}
+
+func (w *recordingWorker) Enqueue(ctx context.Context, t worker.JobType, args ...string) error {
+	w.published = append(w.published, t)
+	return nil
+}
+
+func TestGroupingPublishesOnCommit(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewSQLStore(sqlstore.InitTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+
+	w := &recordingWorker{}
+	s := NewService(store, w)
+
+	g := &AlertGroup{IntegrationID: "i", Title: "disk full"}
+	if err := s.Create(ctx, g); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	w.published = nil
+
+	failed := errors.New("failed")
+	err = s.Grouping(ctx, "i", "key", func(ctx context.Context) error {
+		if _, err := s.AddAlert(ctx, g.ID); err != nil {
+			return err
+		}
+
+		if len(w.published) > 0 {
+			t.Errorf("published %v before committing", w.published)
+		}
+
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Grouping() error = %v, want %v", err, failed)
+	}
+
+	if len(w.published) > 0 {
+		t.Errorf("published %v after rolling back", w.published)
+	}
+
+	if got := mustGet(t, s, g.ID).AlertsCount; got != 1 {
+		t.Errorf("AlertsCount = %d after rolling back, want 1", got)
+	}
+
+	if err := s.Grouping(ctx, "i", "key", func(ctx context.Context) error {
+		_, err := s.AddAlert(ctx, g.ID)
+		return err
+	}); err != nil {
+		t.Fatalf("Grouping() error = %v", err)
+	}
+
+	if len(w.published) != 1 || w.published[0] != JobAlertAdded {
+		t.Errorf("published %v, want [%s]", w.published, JobAlertAdded)
+	}
+}
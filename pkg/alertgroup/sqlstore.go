@@ -0,0 +1,245 @@
+package alertgroup
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// searchBatchSize is how many alert groups are read at a time by searches
+// which match labels or search terms.
+const searchBatchSize = 200
+
+// SQLStore keeps alert groups in the SQL database, which the server and
+// workers share.
+type SQLStore struct {
+	db          *sqlstore.DB
+	groups      *sqlstore.Table[AlertGroup]
+	transitions *sqlstore.Table[Transition]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	groups, err := sqlstore.NewTable(db, "alert_groups", func(g *AlertGroup) string { return g.ID },
+		sqlstore.Column[AlertGroup]{Name: "number", Value: func(g *AlertGroup) string { return strconv.Itoa(g.Number) }, Unique: true},
+		sqlstore.Column[AlertGroup]{Name: "integration_id", Value: func(g *AlertGroup) string { return g.IntegrationID }},
+		sqlstore.Column[AlertGroup]{Name: "grouping_key", Value: func(g *AlertGroup) string { return g.GroupingKey }},
+		sqlstore.Column[AlertGroup]{Name: "root_alert_group_id", Value: func(g *AlertGroup) string { return g.RootAlertGroupID }},
+		sqlstore.Column[AlertGroup]{Name: "state", Value: func(g *AlertGroup) string { return string(g.State) }},
+		sqlstore.Column[AlertGroup]{Name: "team_id", Value: func(g *AlertGroup) string { return g.TeamID }},
+		sqlstore.Column[AlertGroup]{Name: "acknowledged_by", Value: func(g *AlertGroup) string { return g.AcknowledgedBy }},
+		sqlstore.Column[AlertGroup]{Name: string(SortCreatedAt), Value: func(g *AlertGroup) string { return sqlstore.Time(g.CreatedAt) }},
+		sqlstore.Column[AlertGroup]{Name: string(SortUpdatedAt), Value: func(g *AlertGroup) string { return sqlstore.Time(g.UpdatedAt) }},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions, err := sqlstore.NewTable(db, "alert_group_transitions", func(t *Transition) string { return t.ID },
+		sqlstore.Column[Transition]{Name: "alert_group_id", Value: func(t *Transition) string { return t.AlertGroupID }},
+		sqlstore.Column[Transition]{Name: "actor", Value: func(t *Transition) string { return t.Actor }},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, groups: groups, transitions: transitions}, nil
+}
+
+func (s *SQLStore) InTransaction(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.db.Lock(ctx, key); err != nil {
+			return err
+		}
+
+		return fn(ctx)
+	})
+}
+
+func (s *SQLStore) Create(ctx context.Context, g *AlertGroup) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		number, err := s.db.NextValue(ctx, "alert_group_number")
+		if err != nil {
+			return err
+		}
+
+		g.Number = number
+
+		return s.groups.Insert(ctx, g)
+	})
+}
+
+func (s *SQLStore) Get(ctx context.Context, id string) (*AlertGroup, error) {
+	g, err := s.groups.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrAlertGroupNotFound
+	}
+
+	return g, err
+}
+
+func (s *SQLStore) GetByNumber(ctx context.Context, number int) (*AlertGroup, error) {
+	g, err := s.groups.FindOne(ctx, sqlstore.Where{"number": strconv.Itoa(number)})
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrAlertGroupNotFound
+	}
+
+	return g, err
+}
+
+func (s *SQLStore) Update(ctx context.Context, g *AlertGroup) error {
+	err := s.groups.Update(ctx, g)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrAlertGroupNotFound
+	}
+
+	return err
+}
+
+func (s *SQLStore) FindUnresolved(ctx context.Context, integrationID, groupingKey string) (*AlertGroup, error) {
+	groups, err := s.groups.Select(ctx, []sqlstore.Cond{
+		sqlstore.Eq("integration_id", integrationID),
+		sqlstore.Eq("grouping_key", groupingKey),
+		sqlstore.NotEq("state", string(StateResolved)),
+	}, sqlstore.Order{Column: string(SortCreatedAt), Descending: true}, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return nil, ErrAlertGroupNotFound
+	}
+
+	return groups[0], nil
+}
+
+// Search selects the alert groups by the columns of the filters of the query
+// and continues after the cursor in the database. Labels and search terms are
+// matched here, so rows are read in batches until the page is full.
+func (s *SQLStore) Search(ctx context.Context, q *Query) (*Page, error) {
+	conds := searchConditions(q)
+	order := sqlstore.Order{Column: string(q.Sort), Descending: q.Descending}
+
+	var after *cursor
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &c
+	}
+
+	batchSize := q.Limit + 1
+	if len(q.Labels) > 0 || q.Search != "" {
+		batchSize = max(batchSize, searchBatchSize)
+	}
+
+	matched := []*AlertGroup{}
+	for len(matched) <= q.Limit {
+		batchConds := conds
+		if after != nil {
+			batchConds = append(slices.Clip(conds), order.After(sqlstore.Time(after.Value), after.ID))
+		}
+
+		batch, err := s.groups.Select(ctx, batchConds, order, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, g := range batch {
+			if q.matchesText(g) {
+				matched = append(matched, g)
+			}
+		}
+
+		if len(batch) < batchSize {
+			break
+		}
+
+		last := q.cursorOf(batch[len(batch)-1])
+		after = &last
+	}
+
+	page := &Page{AlertGroups: matched}
+	if len(matched) > q.Limit {
+		page.AlertGroups = matched[:q.Limit]
+		page.NextCursor = encodeCursor(q.cursorOf(page.AlertGroups[q.Limit-1]))
+	}
+
+	return page, nil
+}
+
+// searchConditions returns the conditions on the columns of alert groups
+// matching the filters of the query.
+func searchConditions(q *Query) []sqlstore.Cond {
+	var conds []sqlstore.Cond
+
+	if len(q.States) > 0 {
+		states := make([]string, len(q.States))
+		for i, state := range q.States {
+			states[i] = string(state)
+		}
+		conds = append(conds, sqlstore.In("state", states))
+	}
+
+	if len(q.IntegrationIDs) > 0 {
+		conds = append(conds, sqlstore.In("integration_id", q.IntegrationIDs))
+	}
+
+	if len(q.TeamIDs) > 0 {
+		conds = append(conds, sqlstore.In("team_id", q.TeamIDs))
+	}
+
+	if q.StartedAfter != nil {
+		conds = append(conds, sqlstore.AtLeast(string(SortCreatedAt), sqlstore.Time(*q.StartedAfter)))
+	}
+
+	if q.StartedBefore != nil {
+		conds = append(conds, sqlstore.Before(string(SortCreatedAt), sqlstore.Time(*q.StartedBefore)))
+	}
+
+	if q.AcknowledgedBy != "" {
+		conds = append(conds, sqlstore.Eq("acknowledged_by", q.AcknowledgedBy))
+	}
+
+	if q.InvolvedUser != "" {
+		conds = append(conds, sqlstore.Expr("id IN (SELECT alert_group_id FROM alert_group_transitions WHERE actor = ?)", q.InvolvedUser))
+	}
+
+	return conds
+}
+
+func (s *SQLStore) ListAttached(ctx context.Context, rootID string) ([]*AlertGroup, error) {
+	attached, err := s.groups.Find(ctx, sqlstore.Where{"root_alert_group_id": rootID})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(attached, func(i, j int) bool {
+		return attached[i].CreatedAt.Before(attached[j].CreatedAt)
+	})
+
+	return attached, nil
+}
+
+func (s *SQLStore) AddTransition(ctx context.Context, t *Transition) error {
+	return s.transitions.Insert(ctx, t)
+}
+
+func (s *SQLStore) ListTransitions(ctx context.Context, alertGroupID string) ([]*Transition, error) {
+	transitions, err := s.transitions.Find(ctx, sqlstore.Where{"alert_group_id": alertGroupID})
+	if err != nil {
+		return nil, err
+	}
+
+	if transitions == nil {
+		transitions = []*Transition{}
+	}
+
+	return transitions, nil
+}
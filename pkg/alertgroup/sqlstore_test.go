@@ -0,0 +1,117 @@
+package alertgroup
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	s, now := newTestService(t)
+	ctx := context.Background()
+	start := *now
+
+	// Pairs of groups are created at the same time, and sorted by their IDs.
+	var created []*AlertGroup
+	for i, integrationID := range []string{"i1", "i1", "i2", "i2", "i1"} {
+		if i%2 == 0 {
+			*now = now.Add(time.Minute)
+		}
+
+		g := &AlertGroup{IntegrationID: integrationID, Title: "group", Labels: map[string]string{"env": "prod"}}
+		if i == 4 {
+			g.Labels["env"] = "dev"
+			g.Title = "disk full"
+		}
+
+		if err := s.Create(ctx, g); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		created = append(created, g)
+	}
+
+	if _, err := s.Acknowledge(ctx, created[1].ID, "bob"); err != nil {
+		t.Fatalf("Acknowledge() error = %v", err)
+	}
+	if _, err := s.Resolve(ctx, created[2].ID, "carol"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	numbers := func(groups []*AlertGroup) []int {
+		result := []int{}
+		for _, g := range groups {
+			result = append(result, g.Number)
+		}
+		return result
+	}
+
+	// all lists the numbers of the alert groups in the order of the query.
+	all := func(q Query) []int {
+		t.Helper()
+
+		var result []int
+		for {
+			page, err := s.Search(ctx, &q)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			result = append(result, numbers(page.AlertGroups)...)
+			if page.NextCursor == "" {
+				return result
+			}
+			q.Cursor = page.NextCursor
+		}
+	}
+
+	byID := slices.Clone(created)
+	slices.SortFunc(byID, func(a, b *AlertGroup) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		if a.ID < b.ID {
+			return -1
+		}
+		return 1
+	})
+	ascending := numbers(byID)
+	descending := slices.Clone(ascending)
+	slices.Reverse(descending)
+
+	after := start.Add(3 * time.Minute)
+
+	// Groups created at the same time are in no particular order, unless
+	// ordered is set.
+	tests := []struct {
+		name    string
+		query   Query
+		want    []int
+		ordered bool
+	}{
+		{name: "pages of one", query: Query{Limit: 1}, want: ascending, ordered: true},
+		{name: "pages of two descending", query: Query{Limit: 2, Descending: true}, want: descending, ordered: true},
+		{name: "state", query: Query{States: []State{StateAcknowledged, StateResolved}}, want: []int{created[1].Number, created[2].Number}},
+		{name: "integration", query: Query{IntegrationIDs: []string{"i2"}}, want: []int{created[2].Number, created[3].Number}},
+		{name: "started after", query: Query{StartedAfter: &after}, want: []int{created[4].Number}},
+		{name: "started before", query: Query{StartedBefore: &after, IntegrationIDs: []string{"i1"}}, want: []int{created[0].Number, created[1].Number}},
+		{name: "acknowledged by", query: Query{AcknowledgedBy: "bob"}, want: []int{created[1].Number}},
+		{name: "involved user", query: Query{InvolvedUser: "carol"}, want: []int{created[2].Number}},
+		{name: "labels", query: Query{Labels: map[string]string{"env": "dev"}, Limit: 1}, want: []int{created[4].Number}},
+		{name: "search", query: Query{Search: "FULL disk"}, want: []int{created[4].Number}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := all(tt.query)
+			if !tt.ordered {
+				slices.Sort(got)
+				slices.Sort(tt.want)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
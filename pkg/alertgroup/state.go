@@ -0,0 +1,80 @@
+package alertgroup
+
+import (
+	"fmt"
+	"time"
+)
+
+// apply moves the alert group to the state resulting from the action. An
+// ErrInvalidTransition is returned when the action is not permitted from the
+// current state.
+func (g *AlertGroup) apply(action Action, actor string, now time.Time, until *time.Time) error {
+	switch action {
+	case ActionAcknowledge:
+		if g.State != StateFiring && g.State != StateSilenced {
+			return invalidTransition(action, g.State)
+		}
+		g.clearSilence()
+		g.State = StateAcknowledged
+		g.AcknowledgedAt = &now
+		g.AcknowledgedBy = actor
+	case ActionUnacknowledge:
+		if g.State != StateAcknowledged {
+			return invalidTransition(action, g.State)
+		}
+		g.clearAcknowledgement()
+		g.State = StateFiring
+	case ActionResolve:
+		if g.State == StateResolved {
+			return invalidTransition(action, g.State)
+		}
+		g.State = StateResolved
+		g.ResolvedAt = &now
+		g.ResolvedBy = actor
+	case ActionUnresolve:
+		if g.State != StateResolved {
+			return invalidTransition(action, g.State)
+		}
+		g.clearAcknowledgement()
+		g.clearSilence()
+		g.ResolvedAt = nil
+		g.ResolvedBy = ""
+		g.State = StateFiring
+	case ActionSilence:
+		if g.State == StateResolved {
+			return invalidTransition(action, g.State)
+		}
+		g.clearAcknowledgement()
+		g.State = StateSilenced
+		g.SilencedAt = &now
+		g.SilencedBy = actor
+		g.SilencedUntil = until
+	case ActionUnsilence:
+		if g.State != StateSilenced {
+			return invalidTransition(action, g.State)
+		}
+		g.clearSilence()
+		g.State = StateFiring
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidTransition, action)
+	}
+
+	g.UpdatedAt = now
+
+	return nil
+}
+
+func (g *AlertGroup) clearAcknowledgement() {
+	g.AcknowledgedAt = nil
+	g.AcknowledgedBy = ""
+}
+
+func (g *AlertGroup) clearSilence() {
+	g.SilencedAt = nil
+	g.SilencedBy = ""
+	g.SilencedUntil = nil
+}
+
+func invalidTransition(action Action, state State) error {
+	return fmt.Errorf("%w: cannot %s an alert group which is %s", ErrInvalidTransition, action, state)
+}
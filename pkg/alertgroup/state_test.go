@@ -0,0 +1,137 @@
+package alertgroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		from    State
+		action  Action
+		want    State
+		wantErr bool
+	}{
+		{from: StateFiring, action: ActionAcknowledge, want: StateAcknowledged},
+		{from: StateSilenced, action: ActionAcknowledge, want: StateAcknowledged},
+		{from: StateAcknowledged, action: ActionAcknowledge, wantErr: true},
+		{from: StateResolved, action: ActionAcknowledge, wantErr: true},
+
+		{from: StateAcknowledged, action: ActionUnacknowledge, want: StateFiring},
+		{from: StateFiring, action: ActionUnacknowledge, wantErr: true},
+		{from: StateSilenced, action: ActionUnacknowledge, wantErr: true},
+
+		{from: StateFiring, action: ActionResolve, want: StateResolved},
+		{from: StateAcknowledged, action: ActionResolve, want: StateResolved},
+		{from: StateSilenced, action: ActionResolve, want: StateResolved},
+		{from: StateResolved, action: ActionResolve, wantErr: true},
+
+		{from: StateResolved, action: ActionUnresolve, want: StateFiring},
+		{from: StateFiring, action: ActionUnresolve, wantErr: true},
+
+		{from: StateFiring, action: ActionSilence, want: StateSilenced},
+		{from: StateAcknowledged, action: ActionSilence, want: StateSilenced},
+		{from: StateSilenced, action: ActionSilence, want: StateSilenced},
+		{from: StateResolved, action: ActionSilence, wantErr: true},
+
+		{from: StateSilenced, action: ActionUnsilence, want: StateFiring},
+		{from: StateFiring, action: ActionUnsilence, wantErr: true},
+
+		{from: StateFiring, action: Action("escalate"), wantErr: true},
+	}
+
+	now := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(string(tt.action)+" "+string(tt.from), func(t *testing.T) {
+			g := &AlertGroup{State: tt.from}
+
+			err := g.apply(tt.action, "alice", now, nil)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Fatalf("apply() error = %v, want %v", err, ErrInvalidTransition)
+				}
+				if g.State != tt.from {
+					t.Errorf("state = %s after a failed transition, want %s", g.State, tt.from)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+			if g.State != tt.want {
+				t.Errorf("state = %s, want %s", g.State, tt.want)
+			}
+			if !g.UpdatedAt.Equal(now) {
+				t.Errorf("UpdatedAt = %s, want %s", g.UpdatedAt, now)
+			}
+		})
+	}
+}
+
+func TestApplyClearsStaleFields(t *testing.T) {
+	earlier := time.Date(2025, time.January, 6, 8, 0, 0, 0, time.UTC)
+	now := earlier.Add(time.Hour)
+	until := now.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		group  AlertGroup
+		action Action
+		check  func(g *AlertGroup) bool
+	}{
+		{
+			name:   "acknowledging clears the silence",
+			group:  AlertGroup{State: StateSilenced, SilencedAt: &earlier, SilencedBy: "bob", SilencedUntil: &until},
+			action: ActionAcknowledge,
+			check: func(g *AlertGroup) bool {
+				return g.SilencedAt == nil && g.SilencedBy == "" && g.SilencedUntil == nil && g.AcknowledgedBy == "alice"
+			},
+		},
+		{
+			name:   "silencing clears the acknowledgement",
+			group:  AlertGroup{State: StateAcknowledged, AcknowledgedAt: &earlier, AcknowledgedBy: "bob"},
+			action: ActionSilence,
+			check: func(g *AlertGroup) bool {
+				return g.AcknowledgedAt == nil && g.AcknowledgedBy == "" && g.SilencedBy == "alice"
+			},
+		},
+		{
+			name:   "resolving keeps the acknowledgement",
+			group:  AlertGroup{State: StateAcknowledged, AcknowledgedAt: &earlier, AcknowledgedBy: "bob"},
+			action: ActionResolve,
+			check: func(g *AlertGroup) bool {
+				return g.AcknowledgedBy == "bob" && g.ResolvedBy == "alice" && g.ResolvedAt.Equal(now)
+			},
+		},
+		{
+			name: "unresolving clears everything",
+			group: AlertGroup{
+				State:          StateResolved,
+				AcknowledgedAt: &earlier,
+				AcknowledgedBy: "bob",
+				ResolvedAt:     &earlier,
+				ResolvedBy:     "bob",
+			},
+			action: ActionUnresolve,
+			check: func(g *AlertGroup) bool {
+				return g.AcknowledgedAt == nil && g.AcknowledgedBy == "" && g.ResolvedAt == nil && g.ResolvedBy == ""
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.group
+			if err := g.apply(tt.action, "alice", now, nil); err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+
+			if !tt.check(&g) {
+				t.Errorf("unexpected alert group after %s: %+v", tt.action, g)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package alertgroup
+
+import "context"
+
+type Store interface {
+	// InTransaction runs fn in a transaction holding the lock of the key,
+	// which transactions of other processes sharing the store wait for.
+	InTransaction(ctx context.Context, key string, fn func(ctx context.Context) error) error
+
+	// Create stores a new alert group, assigning it the next number.
+	Create(ctx context.Context, g *AlertGroup) error
+	Get(ctx context.Context, id string) (*AlertGroup, error)
+	GetByNumber(ctx context.Context, number int) (*AlertGroup, error)
+	Update(ctx context.Context, g *AlertGroup) error
+	// FindUnresolved returns the most recent unresolved alert group of the
+	// integration with the grouping key.
+	FindUnresolved(ctx context.Context, integrationID, groupingKey string) (*AlertGroup, error)
+	// Search returns a page of the alert groups matching the query.
+	Search(ctx context.Context, q *Query) (*Page, error)
+	// ListAttached returns the alert groups attached to the root alert group.
+	ListAttached(ctx context.Context, rootID string) ([]*AlertGroup, error)
+
+	AddTransition(ctx context.Context, t *Transition) error
+	// ListTransitions returns the transitions of an alert group, oldest first.
+	ListTransitions(ctx context.Context, alertGroupID string) ([]*Transition, error)
+}
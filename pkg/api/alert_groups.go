@@ -0,0 +1,372 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+type alertGroupAction func(ctx context.Context, id, actor string) (*alertgroup.AlertGroup, error)
+
+// ListAlertGroups pages through alert groups. Multi-valued filters may be
+// repeated or comma separated, labels are given as label=key:value and sort
+// is a field name prefixed with - for descending order.
+func (s *HTTPServer) ListAlertGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	q, err := parseAlertGroupQuery(r.URL.Query())
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Users are recorded by ID, but may be filtered by their login.
+	if q.AcknowledgedBy != "" {
+		q.AcknowledgedBy = s.userRef(r.Context(), q.AcknowledgedBy)
+	}
+	if q.InvolvedUser != "" {
+		q.InvolvedUser = s.userRef(r.Context(), q.InvolvedUser)
+	}
+
+	page, err := s.alertGroups.Search(r.Context(), q)
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	result := &dto.AlertGroupList{
+		Results:    make([]*dto.AlertGroup, 0, len(page.AlertGroups)),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, g := range page.AlertGroups {
+		result.Results = append(result.Results, toAlertGroupDTO(g))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetAlertGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	g, err := s.alertGroups.Get(r.Context(), ctx.Param("id"))
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAlertGroupDTO(g))
+}
+
+func (s *HTTPServer) GetAlertGroupTransitions(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	transitions, err := s.alertGroups.Transitions(r.Context(), ctx.Param("id"))
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.AlertGroupTransition, 0, len(transitions))
+	for _, t := range transitions {
+		result = append(result, toAlertGroupTransitionDTO(t))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetAlertGroupTimeline merges the transitions of the alert group with the
+// notifications about it, oldest first, to tell who was notified, how and
+// when.
+func (s *HTTPServer) GetAlertGroupTimeline(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	g, err := s.alertGroups.Get(r.Context(), ctx.Param("id"))
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	transitions, err := s.alertGroups.Transitions(r.Context(), g.ID)
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	deliveries, err := s.notifiers.Deliveries(r.Context(), g.ID)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.AlertGroupTimelineEntry, 0, 1+len(transitions)+len(deliveries))
+	result = append(result, &dto.AlertGroupTimelineEntry{Type: dto.TimelineCreated, Time: g.CreatedAt})
+
+	for _, t := range transitions {
+		result = append(result, &dto.AlertGroupTimelineEntry{
+			Type:       dto.TimelineTransition,
+			Time:       t.CreatedAt,
+			Transition: toAlertGroupTransitionDTO(t),
+		})
+	}
+
+	for _, d := range deliveries {
+		result = append(result, &dto.AlertGroupTimelineEntry{
+			Type:         dto.TimelineNotification,
+			Time:         d.CreatedAt,
+			Notification: toDeliveryDTO(d),
+		})
+	}
+
+	// Both lists are oldest first, a stable sort keeps the creation first
+	// and transitions before notifications they caused at the same time.
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) AcknowledgeAlertGroup(w http.ResponseWriter, r *http.Request) {
+	s.alertGroupAction(r, s.alertGroups.Acknowledge)
+}
+
+func (s *HTTPServer) UnacknowledgeAlertGroup(w http.ResponseWriter, r *http.Request) {
+	s.alertGroupAction(r, s.alertGroups.Unacknowledge)
+}
+
+func (s *HTTPServer) ResolveAlertGroup(w http.ResponseWriter, r *http.Request) {
+	s.alertGroupAction(r, s.alertGroups.Resolve)
+}
+
+func (s *HTTPServer) UnresolveAlertGroup(w http.ResponseWriter, r *http.Request) {
+	s.alertGroupAction(r, s.alertGroups.Unresolve)
+}
+
+func (s *HTTPServer) UnsilenceAlertGroup(w http.ResponseWriter, r *http.Request) {
+	s.alertGroupAction(r, s.alertGroups.Unsilence)
+}
+
+func (s *HTTPServer) UnattachAlertGroup(w http.ResponseWriter, r *http.Request) {
+	s.alertGroupAction(r, s.alertGroups.Unattach)
+}
+
+func (s *HTTPServer) SilenceAlertGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.SilenceAlertGroupRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Delay == 0 || req.Delay < -1 {
+		errorJSON(ctx, http.StatusBadRequest, "delay must be a positive number of seconds, or -1 to silence until unsilenced")
+		return
+	}
+
+	var duration time.Duration
+	if req.Delay > 0 {
+		duration = time.Duration(req.Delay) * time.Second
+	}
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	g, err := s.alertGroups.Silence(r.Context(), ctx.Param("id"), actor, duration)
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAlertGroupDTO(g))
+}
+
+func (s *HTTPServer) AttachAlertGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.AttachAlertGroupRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil || req.RootAlertGroupID == "" {
+		errorJSON(ctx, http.StatusBadRequest, "root_alert_group_id is required")
+		return
+	}
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	g, err := s.alertGroups.Attach(r.Context(), ctx.Param("id"), req.RootAlertGroupID, actor)
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAlertGroupDTO(g))
+}
+
+func (s *HTTPServer) alertGroupAction(r *http.Request, action alertGroupAction) {
+	ctx := web.FromContext(r.Context())
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	g, err := action(r.Context(), ctx.Param("id"), actor)
+	if err != nil {
+		alertGroupError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAlertGroupDTO(g))
+}
+
+func parseAlertGroupQuery(values url.Values) (*alertgroup.Query, error) {
+	q := &alertgroup.Query{
+		IntegrationIDs: splitValues(values["integration_id"]),
+		TeamIDs:        splitValues(values["team_id"]),
+		AcknowledgedBy: values.Get("acknowledged_by"),
+		InvolvedUser:   values.Get("involved_user"),
+		Search:         values.Get("search"),
+		Cursor:         values.Get("cursor"),
+	}
+
+	for _, state := range splitValues(values["state"]) {
+		q.States = append(q.States, alertgroup.State(state))
+	}
+
+	for _, label := range values["label"] {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label filter %q, expected key:value", label)
+		}
+
+		if q.Labels == nil {
+			q.Labels = make(map[string]string)
+		}
+		q.Labels[key] = value
+	}
+
+	var err error
+	if q.StartedAfter, err = parseTimeParam(values, "started_after"); err != nil {
+		return nil, err
+	}
+
+	if q.StartedBefore, err = parseTimeParam(values, "started_before"); err != nil {
+		return nil, err
+	}
+
+	sort := values.Get("sort")
+	if sort == "" {
+		// Most recent alert groups first unless asked otherwise.
+		sort = "-" + string(alertgroup.SortCreatedAt)
+	}
+
+	if strings.HasPrefix(sort, "-") {
+		q.Descending = true
+		sort = sort[1:]
+	}
+	q.Sort = alertgroup.SortField(sort)
+
+	if limit := values.Get("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit <= 0 {
+			return nil, fmt.Errorf("limit must be a positive integer")
+		}
+	}
+
+	return q, nil
+}
+
+func parseTimeParam(values url.Values, name string) (*time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+
+	return &t, nil
+}
+
+// splitValues flattens repeated and comma separated query string values.
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+
+	return result
+}
+
+func alertGroupError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, alertgroup.ErrAlertGroupNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, alertgroup.ErrInvalidTransition),
+		errors.Is(err, alertgroup.ErrInvalidAttachment),
+		errors.Is(err, alertgroup.ErrInvalidCursor),
+		errors.Is(err, alertgroup.ErrInvalidQuery):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toAlertGroupTransitionDTO(t *alertgroup.Transition) *dto.AlertGroupTransition {
+	return &dto.AlertGroupTransition{
+		ID:               t.ID,
+		Action:           string(t.Action),
+		Actor:            t.Actor,
+		From:             string(t.From),
+		To:               string(t.To),
+		RootAlertGroupID: t.RootAlertGroupID,
+		SilencedUntil:    t.SilencedUntil,
+		CreatedAt:        t.CreatedAt,
+	}
+}
+
+func toAlertGroupDTO(g *alertgroup.AlertGroup) *dto.AlertGroup {
+	return &dto.AlertGroup{
+		ID:               g.ID,
+		Number:           g.Number,
+		IntegrationID:    g.IntegrationID,
+		TeamID:           g.TeamID,
+		Title:            g.Title,
+		Message:          g.Message,
+		Labels:           g.Labels,
+		State:            string(g.State),
+		RouteID:          g.RouteID,
+		AlertsCount:      g.AlertsCount,
+		AcknowledgedAt:   g.AcknowledgedAt,
+		AcknowledgedBy:   g.AcknowledgedBy,
+		ResolvedAt:       g.ResolvedAt,
+		ResolvedBy:       g.ResolvedBy,
+		SilencedAt:       g.SilencedAt,
+		SilencedBy:       g.SilencedBy,
+		SilencedUntil:    g.SilencedUntil,
+		RootAlertGroupID: g.RootAlertGroupID,
+		CreatedAt:        g.CreatedAt,
+		UpdatedAt:        g.UpdatedAt,
+	}
+}
@@ -2,8 +2,12 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
 
+	"github.com/InariTheFox/oncall/pkg/team"
+	"github.com/InariTheFox/oncall/pkg/user"
 	"github.com/InariTheFox/oncall/pkg/web"
 )
 
@@ -22,3 +26,204 @@ func (s *HTTPServer) Middleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+const (
+	// userHeader is set by the Grafana plugin proxy to the login of the
+	// signed in user.
+	userHeader = "X-Grafana-User"
+
+	// proxySecretHeader is set by the Grafana plugin proxy to the proxy
+	// secret, which proves the request and its userHeader come from it.
+	proxySecretHeader = "X-OnCall-Proxy-Secret"
+)
+
+// Authenticate only lets through requests forwarded by the Grafana plugin
+// proxy, which is trusted to say who is signed in.
+func (s *HTTPServer) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := web.FromContext(r.Context())
+
+		if s.Cfg.AuthProxySecret == "" {
+			errorJSON(ctx, http.StatusUnauthorized, "The API is disabled until auth proxy_secret is configured")
+			return
+		}
+
+		secret := r.Header.Get(proxySecretHeader)
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(s.Cfg.AuthProxySecret)) != 1 {
+			errorJSON(ctx, http.StatusUnauthorized, "Invalid proxy secret")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestActor returns the ID of the signed in user who made the request,
+// for recording against changes. It writes an error response and returns
+// false when nobody is signed in or they have no user.
+func (s *HTTPServer) requestActor(ctx *web.Context) (string, bool) {
+	me := s.signedInUser(ctx)
+	if me == nil {
+		return "", false
+	}
+
+	return me.ID, true
+}
+
+// userRef returns the ID of the user with the login, or the login when they
+// have no user yet.
+func (s *HTTPServer) userRef(ctx context.Context, login string) string {
+	if u, err := s.users.GetByUsername(ctx, login); err == nil {
+		return u.ID
+	}
+
+	return login
+}
+
+// signedInUser returns the user signed in to Grafana, or writes an error
+// response and returns nil when nobody is signed in or they have no user.
+func (s *HTTPServer) signedInUser(ctx *web.Context) *user.User {
+	login := ctx.Request.Header.Get(userHeader)
+	if login == "" {
+		errorJSON(ctx, http.StatusUnauthorized, "Not signed in")
+		return nil
+	}
+
+	u, err := s.users.GetByUsername(ctx.Request.Context(), login)
+	if errors.Is(err, user.ErrUserNotFound) {
+		errorJSON(ctx, http.StatusForbidden, "You have no OnCall user")
+		return nil
+	}
+	if err != nil {
+		internalError(ctx, err)
+		return nil
+	}
+
+	return u
+}
+
+// adminOnly only lets admins through.
+func (s *HTTPServer) adminOnly(h web.Handler) web.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := web.FromContext(r.Context())
+
+		me := s.signedInUser(ctx)
+		if me == nil {
+			return
+		}
+
+		if me.Role != user.RoleAdmin {
+			errorJSON(ctx, http.StatusForbidden, "Only admins can do this")
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+// editorOrAdmin only lets editors and admins through, viewers can only look.
+func (s *HTTPServer) editorOrAdmin(h web.Handler) web.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := web.FromContext(r.Context())
+
+		me := s.signedInUser(ctx)
+		if me == nil {
+			return
+		}
+
+		if me.Role == user.RoleViewer {
+			errorJSON(ctx, http.StatusForbidden, "Only admins and editors can do this")
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+// selfOrAdmin only lets through the user whose ID is the id URL parameter,
+// and admins.
+func (s *HTTPServer) selfOrAdmin(h web.Handler) web.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := web.FromContext(r.Context())
+
+		me := s.signedInUser(ctx)
+		if me == nil {
+			return
+		}
+
+		if me.Role != user.RoleAdmin && me.ID != ctx.Param("id") {
+			errorJSON(ctx, http.StatusForbidden, "You can only change your own user")
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+// teamMemberOrAdmin only lets through the members of the team whose ID is
+// the id URL parameter, and admins. Viewers cannot change their teams.
+func (s *HTTPServer) teamMemberOrAdmin(h web.Handler) web.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := web.FromContext(r.Context())
+
+		me := s.signedInUser(ctx)
+		if me == nil {
+			return
+		}
+
+		if me.Role != user.RoleAdmin {
+			t, err := s.teams.Get(r.Context(), ctx.Param("id"))
+			if err != nil {
+				teamError(ctx, err)
+				return
+			}
+
+			if me.Role == user.RoleViewer || !t.HasMember(me.ID) {
+				errorJSON(ctx, http.StatusForbidden, "Only admins and editors in the team can change it")
+				return
+			}
+		}
+
+		h(w, r)
+	}
+}
+
+// scheduleTeamMemberOrAdmin only lets through the members of the team of the
+// schedule whose ID is the id URL parameter, and admins. Schedules without a
+// team are left to admins.
+func (s *HTTPServer) scheduleTeamMemberOrAdmin(h web.Handler) web.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := web.FromContext(r.Context())
+
+		me := s.signedInUser(ctx)
+		if me == nil {
+			return
+		}
+
+		if me.Role != user.RoleAdmin {
+			sched, err := s.schedules.Get(r.Context(), ctx.Param("id"))
+			if err != nil {
+				scheduleError(ctx, err)
+				return
+			}
+
+			member := false
+			if sched.TeamID != "" {
+				t, err := s.teams.Get(r.Context(), sched.TeamID)
+				if err != nil && !errors.Is(err, team.ErrTeamNotFound) {
+					internalError(ctx, err)
+					return
+				}
+
+				member = err == nil && t.HasMember(me.ID)
+			}
+
+			if !member {
+				errorJSON(ctx, http.StatusForbidden, "Only admins and members of the schedule's team can do this")
+				return
+			}
+		}
+
+		h(w, r)
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import "time"
+
+type AlertGroup struct {
+	ID               string            `json:"id"`
+	Number           int               `json:"number"`
+	IntegrationID    string            `json:"integration_id"`
+	TeamID           string            `json:"team_id,omitempty"`
+	Title            string            `json:"title"`
+	Message          string            `json:"message,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	State            string            `json:"state"`
+	RouteID          string            `json:"route_id,omitempty"`
+	AlertsCount      int               `json:"alerts_count"`
+	AcknowledgedAt   *time.Time        `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy   string            `json:"acknowledged_by,omitempty"`
+	ResolvedAt       *time.Time        `json:"resolved_at,omitempty"`
+	ResolvedBy       string            `json:"resolved_by,omitempty"`
+	SilencedAt       *time.Time        `json:"silenced_at,omitempty"`
+	SilencedBy       string            `json:"silenced_by,omitempty"`
+	SilencedUntil    *time.Time        `json:"silenced_until,omitempty"`
+	RootAlertGroupID string            `json:"root_alert_group_id,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+type AlertGroupTransition struct {
+	ID               string     `json:"id"`
+	Action           string     `json:"action"`
+	Actor            string     `json:"actor"`
+	From             string     `json:"from"`
+	To               string     `json:"to"`
+	RootAlertGroupID string     `json:"root_alert_group_id,omitempty"`
+	SilencedUntil    *time.Time `json:"silenced_until,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Types of alert group timeline entries.
+const (
+	TimelineCreated      = "created"
+	TimelineTransition   = "transition"
+	TimelineNotification = "notification"
+)
+
+// AlertGroupTimelineEntry is the creation of the alert group, one of its
+// transitions, or a notification about it, as set by Type.
+type AlertGroupTimelineEntry struct {
+	Type         string                `json:"type"`
+	Time         time.Time             `json:"time"`
+	Transition   *AlertGroupTransition `json:"transition,omitempty"`
+	Notification *Delivery             `json:"notification,omitempty"`
+}
+
+type AttachAlertGroupRequest struct {
+	RootAlertGroupID string `json:"root_alert_group_id"`
+}
+
+type SilenceAlertGroupRequest struct {
+	// Delay is the silence duration in seconds, a value of -1 silences the
+	// alert group until it is unsilenced.
+	Delay int64 `json:"delay"`
+}
+
+type AlertGroupList struct {
+	Results []*AlertGroup `json:"results"`
+	// NextCursor is passed as the cursor parameter to fetch the next page,
+	// it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
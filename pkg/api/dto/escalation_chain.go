@@ -0,0 +1,23 @@
+package dto
+
+type EscalationChain struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	TeamID string            `json:"team_id,omitempty"`
+	Steps  []*EscalationStep `json:"steps"`
+}
+
+type EscalationStep struct {
+	Type      string `json:"type"`
+	Important bool   `json:"important,omitempty"`
+	// Duration is the wait in seconds.
+	Duration                 int64    `json:"duration,omitempty"`
+	PersonsToNotify          []string `json:"persons_to_notify,omitempty"`
+	NotifyOnCallFromSchedule string   `json:"notify_on_call_from_schedule,omitempty"`
+	GroupToNotify            string   `json:"group_to_notify,omitempty"`
+	ActionToTrigger          string   `json:"action_to_trigger,omitempty"`
+	NumRepeats               int      `json:"num_repeats,omitempty"`
+	NotifyIfTimeFrom         string   `json:"notify_if_time_from,omitempty"`
+	NotifyIfTimeTo           string   `json:"notify_if_time_to,omitempty"`
+	NotifyIfTimeZone         string   `json:"notify_if_time_zone,omitempty"`
+}
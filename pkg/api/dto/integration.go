@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+type Integration struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	TeamID string `json:"team_id,omitempty"`
+	// Link is the URL alert sources post alerts to.
+	Link      string     `json:"link"`
+	Heartbeat *Heartbeat `json:"heartbeat"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type Heartbeat struct {
+	Link string `json:"link"`
+	// Timeout is in seconds, zero when heartbeat monitoring is disabled.
+	Timeout         int64      `json:"timeout"`
+	LastHeartbeatAt *time.Time `json:"last_heartbeat_at,omitempty"`
+	Expired         bool       `json:"expired"`
+}
+
+type CreateIntegrationRequest struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	TeamID string `json:"team_id"`
+	// HeartbeatTimeout is in seconds.
+	HeartbeatTimeout int64 `json:"heartbeat_timeout"`
+}
+
+type UpdateIntegrationRequest struct {
+	Name   string `json:"name"`
+	TeamID string `json:"team_id"`
+	// HeartbeatTimeout is in seconds, zero disables heartbeat monitoring.
+	HeartbeatTimeout int64 `json:"heartbeat_timeout"`
+}
+
+type Route struct {
+	ID                string   `json:"id"`
+	IntegrationID     string   `json:"integration_id"`
+	Position          int      `json:"position"`
+	IsDefault         bool     `json:"is_the_last_route"`
+	RoutingTemplate   string   `json:"routing_template,omitempty"`
+	LabelMatchers     []string `json:"label_matchers,omitempty"`
+	EscalationChainID string   `json:"escalation_chain_id,omitempty"`
+	// ChatChannel is the Slack channel alert groups are posted to.
+	ChatChannel string `json:"chat_channel,omitempty"`
+}
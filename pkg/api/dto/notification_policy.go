@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type NotificationPolicy struct {
+	Importance string                    `json:"importance"`
+	Steps      []*NotificationPolicyStep `json:"steps"`
+}
+
+type NotificationPolicyStep struct {
+	Type   string `json:"type"`
+	Method string `json:"method,omitempty"`
+	// Duration is the wait in seconds.
+	Duration int64 `json:"duration,omitempty"`
+}
+
+type QuietHours struct {
+	Windows []*QuietWindow `json:"windows"`
+	// Action is defer or downgrade.
+	Action            string     `json:"action"`
+	DowngradeMethod   string     `json:"downgrade_method,omitempty"`
+	DoNotDisturbUntil *time.Time `json:"do_not_disturb_until,omitempty"`
+}
+
+type QuietWindow struct {
+	// Days are RFC 5545 days of the week such as MO, every day if empty.
+	Days []string `json:"days,omitempty"`
+	// Start and End are times of day formatted as 15:04.
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type Notifier struct {
+	Type string `json:"type"`
+	// Address is phone, email or contact_method.
+	Address   string `json:"address"`
+	Channel   bool   `json:"channel"`
+	Actions   bool   `json:"actions"`
+	MaxLength int    `json:"max_length,omitempty"`
+}
+
+// Delivery is an attempt to notify a user or a channel.
+type Delivery struct {
+	ID string `json:"id"`
+	// Type is the type of the notifier, such as slack or sms.
+	Type    string `json:"type"`
+	UserID  string `json:"user_id,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Address string `json:"address,omitempty"`
+	Title   string `json:"title,omitempty"`
+	// Status is sent, delivered or failed.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// ProviderMessageID identifies the message in the service behind the
+	// notifier, when it reports one.
+	ProviderMessageID string    `json:"provider_message_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,100 @@
+package dto
+
+import "time"
+
+type Schedule struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	TeamID    string              `json:"team_id,omitempty"`
+	Type      string              `json:"type"`
+	TimeZone  string              `json:"time_zone"`
+	Layers    []*ScheduleLayer    `json:"layers"`
+	Overrides []*ScheduleOverride `json:"overrides"`
+	ICal      *ScheduleICal       `json:"ical,omitempty"`
+	// ChatChannel is told whenever the users on call change.
+	ChatChannel string `json:"chat_channel,omitempty"`
+}
+
+type ScheduleICal struct {
+	URL        string     `json:"url,omitempty"`
+	EventCount int        `json:"event_count"`
+	Error      string     `json:"error,omitempty"`
+	SyncedAt   *time.Time `json:"synced_at,omitempty"`
+}
+
+type ScheduleLayer struct {
+	Priority  int                 `json:"priority"`
+	Rotations []*ScheduleRotation `json:"rotations"`
+}
+
+type ScheduleRotation struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Frequency string    `json:"frequency"`
+	Interval  int       `json:"interval"`
+	Start     time.Time `json:"start"`
+	// Duration is the shift length in seconds, zero for the whole period.
+	Duration     int64      `json:"duration,omitempty"`
+	Until        *time.Time `json:"until,omitempty"`
+	TimeZone     string     `json:"time_zone,omitempty"`
+	Participants []string   `json:"participants"`
+	// ByDay lists RFC 5545 days of the week such as MO.
+	ByDay []string `json:"by_day,omitempty"`
+}
+
+type ScheduleOverride struct {
+	ID      string    `json:"id,omitempty"`
+	UserIDs []string  `json:"users"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+type Shift struct {
+	UserIDs    []string  `json:"users"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	RotationID string    `json:"rotation_id,omitempty"`
+	OverrideID string    `json:"override_id,omitempty"`
+	EventID    string    `json:"event_id,omitempty"`
+	Priority   int       `json:"priority"`
+}
+
+type ScheduleQuality struct {
+	From     time.Time          `json:"from"`
+	To       time.Time          `json:"to"`
+	Score    int                `json:"score"`
+	Coverage float64            `json:"coverage"`
+	Balance  float64            `json:"balance"`
+	Gaps     []*SchedulePeriod  `json:"gaps"`
+	Overlaps []*ScheduleOverlap `json:"overlaps"`
+	Load     []*ScheduleLoad    `json:"load"`
+}
+
+type SchedulePeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type ScheduleOverlap struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	UserIDs []string  `json:"users"`
+}
+
+type ScheduleLoad struct {
+	UserID string `json:"user"`
+	// OnCall is the time on call in seconds.
+	OnCall int64 `json:"on_call"`
+}
+
+type OnCall struct {
+	At      time.Time `json:"at"`
+	UserIDs []string  `json:"users"`
+}
+
+type ExportToken struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExportURL is the address to subscribe to in a calendar app.
+	ExportURL string `json:"export_url"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type ShiftNotificationPreferences struct {
+	// NotifyBefore is how many seconds before a shift starts the user is
+	// told about it, zero turning the notification off.
+	NotifyBefore int64 `json:"notify_before"`
+	NotifyOnEnd  bool  `json:"notify_on_end"`
+}
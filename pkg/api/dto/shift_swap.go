@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type ShiftSwap struct {
+	ID          string     `json:"id"`
+	ScheduleID  string     `json:"schedule_id"`
+	Beneficiary string     `json:"beneficiary"`
+	Benefactor  string     `json:"benefactor,omitempty"`
+	SwapStart   time.Time  `json:"swap_start"`
+	SwapEnd     time.Time  `json:"swap_end"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	OverrideIDs []string   `json:"overrides"`
+	TakenAt     *time.Time `json:"taken_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type ShiftSwapRequest struct {
+	ScheduleID  string    `json:"schedule_id"`
+	SwapStart   time.Time `json:"swap_start"`
+	SwapEnd     time.Time `json:"swap_end"`
+	Description string    `json:"description"`
+}
+
+type ShiftSwapHistoryEntry struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type Team struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email,omitempty"`
+	ChatChannel string    `json:"chat_channel,omitempty"`
+	MemberIDs   []string  `json:"members"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TeamMemberRequest struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type User struct {
+	ID             string           `json:"id"`
+	Username       string           `json:"username"`
+	Name           string           `json:"name,omitempty"`
+	Email          string           `json:"email,omitempty"`
+	Phone          string           `json:"phone_number,omitempty"`
+	PhoneVerified  bool             `json:"phone_verified"`
+	TimeZone       string           `json:"time_zone"`
+	Role           string           `json:"role"`
+	ContactMethods []*ContactMethod `json:"contact_methods"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+type ContactMethod struct {
+	Type       string     `json:"type"`
+	Address    string     `json:"address"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+type PhoneVerification struct {
+	Phone     string    `json:"phone_number"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PhoneVerificationConfirm struct {
+	Code string `json:"code"`
+}
+
+type TelegramLink struct {
+	Code      string    `json:"code"`
+	Command   string    `json:"command"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
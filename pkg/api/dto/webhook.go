@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type Webhook struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	TeamID string `json:"team_id,omitempty"`
+	// Enabled defaults to true.
+	Enabled  *bool             `json:"enabled,omitempty"`
+	URL      string            `json:"url"`
+	Method   string            `json:"method,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	AuthType string            `json:"auth_type,omitempty"`
+	Username string            `json:"username,omitempty"`
+	// Password and Token are never returned. They are kept when omitted
+	// from updates that keep the auth type.
+	Password       string    `json:"password,omitempty"`
+	Token          string    `json:"token,omitempty"`
+	Body           string    `json:"body,omitempty"`
+	Triggers       []string  `json:"triggers"`
+	IntegrationIDs []string  `json:"integration_ids,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type WebhookResponse struct {
+	ID           string    `json:"id"`
+	AlertGroupID string    `json:"alert_group_id"`
+	Trigger      string    `json:"trigger"`
+	Attempt      int       `json:"attempt"`
+	Method       string    `json:"method"`
+	URL          string    `json:"url"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
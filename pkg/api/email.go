@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/notifier/email"
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+// maxInboundEmailSize limits the body of inbound emails, which may include
+// attachments.
+const maxInboundEmailSize = 10 << 20
+
+// ReceiveEmail receives replies to notification emails, posted by the
+// inbound email service of the mail provider as a form. The field names of
+// common providers are accepted.
+func (s *HTTPServer) ReceiveEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.email.Enabled() {
+		errorJSON(ctx, http.StatusNotFound, "Inbound email is not enabled")
+		return
+	}
+
+	if err := s.email.Verify(ctx.Param("token")); err != nil {
+		errorJSON(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxInboundEmailSize)
+	if err := r.ParseMultipartForm(maxInboundEmailSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	g, err := s.email.HandleReply(r.Context(), &email.Reply{
+		Recipient: formValue(r, "recipient", "to", "To"),
+		Sender:    formValue(r, "sender", "from", "From"),
+		Text:      formValue(r, "stripped-text", "body-plain", "text", "TextBody"),
+	})
+	if err != nil {
+		switch {
+		// Mail providers do not retry emails rejected with 406.
+		case errors.Is(err, email.ErrUnknownReply),
+			errors.Is(err, email.ErrUnknownSender),
+			errors.Is(err, email.ErrUnknownCommand):
+			errorJSON(ctx, http.StatusNotAcceptable, err.Error())
+		default:
+			alertGroupError(ctx, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAlertGroupDTO(g))
+}
+
+// formValue returns the first of the form fields that is set.
+func formValue(r *http.Request, names ...string) string {
+	for _, name := range names {
+		if v := r.FormValue(name); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+func errorJSON(ctx *web.Context, status int, message string) {
+	ctx.JSON(status, &dto.ErrorResponse{
+		Message: message,
+	})
+}
+
+func internalError(ctx *web.Context, err error) {
+	fmt.Printf("Request %s %s failed: %s\n", ctx.Request.Method, ctx.Request.URL.Path, err)
+
+	errorJSON(ctx, http.StatusInternalServerError, "Internal server error")
+}
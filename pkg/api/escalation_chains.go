@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+func (s *HTTPServer) ListEscalationChains(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	chains, err := s.escalations.ListChains(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.EscalationChain, 0, len(chains))
+	for _, c := range chains {
+		result = append(result, toEscalationChainDTO(c))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetEscalationChain(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	c, err := s.escalations.GetChain(r.Context(), ctx.Param("id"))
+	if err != nil {
+		escalationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toEscalationChainDTO(c))
+}
+
+func (s *HTTPServer) CreateEscalationChain(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.EscalationChain{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	c := fromEscalationChainDTO(req)
+	if err := s.escalations.CreateChain(r.Context(), c); err != nil {
+		escalationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toEscalationChainDTO(c))
+}
+
+func (s *HTTPServer) UpdateEscalationChain(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.EscalationChain{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	c := fromEscalationChainDTO(req)
+	c.ID = ctx.Param("id")
+
+	if err := s.escalations.UpdateChain(r.Context(), c); err != nil {
+		escalationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toEscalationChainDTO(c))
+}
+
+func (s *HTTPServer) DeleteEscalationChain(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.escalations.DeleteChain(r.Context(), ctx.Param("id")); err != nil {
+		escalationError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func escalationError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, escalation.ErrChainNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, escalation.ErrInvalidChain):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toEscalationChainDTO(c *escalation.Chain) *dto.EscalationChain {
+	result := &dto.EscalationChain{
+		ID:     c.ID,
+		Name:   c.Name,
+		TeamID: c.TeamID,
+		Steps:  make([]*dto.EscalationStep, 0, len(c.Steps)),
+	}
+
+	for _, step := range c.Steps {
+		result.Steps = append(result.Steps, &dto.EscalationStep{
+			Type:                     string(step.Type),
+			Important:                step.Important,
+			Duration:                 int64(step.Duration / time.Second),
+			PersonsToNotify:          step.UserIDs,
+			NotifyOnCallFromSchedule: step.ScheduleID,
+			GroupToNotify:            step.UserGroupID,
+			ActionToTrigger:          step.WebhookID,
+			NumRepeats:               step.Repeats,
+			NotifyIfTimeFrom:         step.FromTime,
+			NotifyIfTimeTo:           step.ToTime,
+			NotifyIfTimeZone:         step.TimeZone,
+		})
+	}
+
+	return result
+}
+
+func fromEscalationChainDTO(c *dto.EscalationChain) *escalation.Chain {
+	result := &escalation.Chain{
+		Name:   c.Name,
+		TeamID: c.TeamID,
+		Steps:  make([]*escalation.Step, 0, len(c.Steps)),
+	}
+
+	for _, step := range c.Steps {
+		if step == nil {
+			continue
+		}
+
+		result.Steps = append(result.Steps, &escalation.Step{
+			Type:        escalation.StepType(step.Type),
+			Important:   step.Important,
+			Duration:    time.Duration(step.Duration) * time.Second,
+			UserIDs:     step.PersonsToNotify,
+			ScheduleID:  step.NotifyOnCallFromSchedule,
+			UserGroupID: step.GroupToNotify,
+			WebhookID:   step.ActionToTrigger,
+			Repeats:     step.NumRepeats,
+			FromTime:    step.NotifyIfTimeFrom,
+			ToTime:      step.NotifyIfTimeTo,
+			TimeZone:    step.NotifyIfTimeZone,
+		})
+	}
+
+	return result
+}
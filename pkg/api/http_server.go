@@ -9,7 +9,24 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/integration"
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/email"
+	"github.com/InariTheFox/oncall/pkg/notifier/mattermost"
+	"github.com/InariTheFox/oncall/pkg/notifier/ntfy"
+	"github.com/InariTheFox/oncall/pkg/notifier/slack"
+	"github.com/InariTheFox/oncall/pkg/notifier/telegram"
+	"github.com/InariTheFox/oncall/pkg/notifier/twilio"
+	"github.com/InariTheFox/oncall/pkg/schedule"
 	"github.com/InariTheFox/oncall/pkg/setting"
+	"github.com/InariTheFox/oncall/pkg/shiftnotify"
+	"github.com/InariTheFox/oncall/pkg/shiftswap"
+	"github.com/InariTheFox/oncall/pkg/team"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -21,9 +38,51 @@ type HTTPServer struct {
 	context    context.Context
 	router     *chi.Mux
 	httpServer *http.Server
+
+	alertGroups  *alertgroup.Service
+	escalations  *escalation.Service
+	integrations *integration.Service
+	schedules    *schedule.Service
+	shiftSwaps   *shiftswap.Service
+
+	shiftNotifications   *shiftnotify.Service
+	notificationPolicies *notificationpolicy.Service
+	users                *user.Service
+	teams                *team.Service
+	notifiers            *notifier.Service
+	slack                *slack.Service
+	telegram             *telegram.Service
+	twilio               *twilio.Service
+	email                *email.Service
+	mattermost           *mattermost.Service
+	ntfy                 *ntfy.Service
+	webhooks             *webhook.Service
 }
 
-func New(cfg *setting.Cfg) (*HTTPServer, error) {
+// Services are the services the HTTP server exposes. Notifier services of
+// notifiers that are not enabled may be nil.
+type Services struct {
+	AlertGroups  *alertgroup.Service
+	Escalations  *escalation.Service
+	Integrations *integration.Service
+	Schedules    *schedule.Service
+	ShiftSwaps   *shiftswap.Service
+
+	ShiftNotifications   *shiftnotify.Service
+	NotificationPolicies *notificationpolicy.Service
+	Users                *user.Service
+	Teams                *team.Service
+	Notifiers            *notifier.Service
+	Slack                *slack.Service
+	Telegram             *telegram.Service
+	Twilio               *twilio.Service
+	Email                *email.Service
+	Mattermost           *mattermost.Service
+	Ntfy                 *ntfy.Service
+	Webhooks             *webhook.Service
+}
+
+func New(cfg *setting.Cfg, svcs *Services) (*HTTPServer, error) {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -32,6 +91,25 @@ func New(cfg *setting.Cfg) (*HTTPServer, error) {
 	s := &HTTPServer{
 		Cfg:    cfg,
 		router: r,
+
+		alertGroups:  svcs.AlertGroups,
+		escalations:  svcs.Escalations,
+		integrations: svcs.Integrations,
+		schedules:    svcs.Schedules,
+		shiftSwaps:   svcs.ShiftSwaps,
+
+		shiftNotifications:   svcs.ShiftNotifications,
+		notificationPolicies: svcs.NotificationPolicies,
+		users:                svcs.Users,
+		teams:                svcs.Teams,
+		notifiers:            svcs.Notifiers,
+		slack:                svcs.Slack,
+		telegram:             svcs.Telegram,
+		twilio:               svcs.Twilio,
+		email:                svcs.Email,
+		mattermost:           svcs.Mattermost,
+		ntfy:                 svcs.Ntfy,
+		webhooks:             svcs.Webhooks,
 	}
 
 	return s, nil
@@ -64,7 +142,7 @@ func (s *HTTPServer) Run(ctx context.Context) error {
 
 		<-ctx.Done()
 		if err := s.httpServer.Shutdown(context.Background()); err != nil {
-			fmt.Errorf("Failed to shutdown server %w\n", err)
+			fmt.Printf("Failed to shutdown server %s\n", err)
 		}
 	}()
 
@@ -97,6 +175,110 @@ func (s *HTTPServer) Run(ctx context.Context) error {
 func (s *HTTPServer) applyRoutes() {
 
 	s.Get("/", s.Index)
+
+	s.Get("/api/v1/alert_groups", s.ListAlertGroups)
+	s.Get("/api/v1/alert_groups/{id}", s.GetAlertGroup)
+	s.Get("/api/v1/alert_groups/{id}/transitions", s.GetAlertGroupTransitions)
+	s.Get("/api/v1/alert_groups/{id}/timeline", s.GetAlertGroupTimeline)
+	s.Post("/api/v1/alert_groups/{id}/acknowledge", s.editorOrAdmin(s.AcknowledgeAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/unacknowledge", s.editorOrAdmin(s.UnacknowledgeAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/resolve", s.editorOrAdmin(s.ResolveAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/unresolve", s.editorOrAdmin(s.UnresolveAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/silence", s.editorOrAdmin(s.SilenceAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/unsilence", s.editorOrAdmin(s.UnsilenceAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/attach", s.editorOrAdmin(s.AttachAlertGroup))
+	s.Post("/api/v1/alert_groups/{id}/unattach", s.editorOrAdmin(s.UnattachAlertGroup))
+
+	s.Get("/api/v1/escalation_chains", s.ListEscalationChains)
+	s.Post("/api/v1/escalation_chains", s.editorOrAdmin(s.CreateEscalationChain))
+	s.Get("/api/v1/escalation_chains/{id}", s.GetEscalationChain)
+	s.Put("/api/v1/escalation_chains/{id}", s.editorOrAdmin(s.UpdateEscalationChain))
+	s.Delete("/api/v1/escalation_chains/{id}", s.editorOrAdmin(s.DeleteEscalationChain))
+
+	s.Get("/api/v1/integrations", s.ListIntegrations)
+	s.Post("/api/v1/integrations", s.editorOrAdmin(s.CreateIntegration))
+	s.Get("/api/v1/integrations/{id}", s.GetIntegration)
+	s.Put("/api/v1/integrations/{id}", s.editorOrAdmin(s.UpdateIntegration))
+	s.Delete("/api/v1/integrations/{id}", s.editorOrAdmin(s.DeleteIntegration))
+
+	s.Get("/api/v1/routes", s.ListRoutes)
+	s.Post("/api/v1/routes", s.editorOrAdmin(s.CreateRoute))
+	s.Get("/api/v1/routes/{id}", s.GetRoute)
+	s.Put("/api/v1/routes/{id}", s.editorOrAdmin(s.UpdateRoute))
+	s.Delete("/api/v1/routes/{id}", s.editorOrAdmin(s.DeleteRoute))
+
+	s.Get("/api/v1/schedules", s.ListSchedules)
+	s.Post("/api/v1/schedules", s.editorOrAdmin(s.CreateSchedule))
+	s.Get("/api/v1/schedules/{id}", s.GetSchedule)
+	s.Put("/api/v1/schedules/{id}", s.editorOrAdmin(s.UpdateSchedule))
+	s.Delete("/api/v1/schedules/{id}", s.editorOrAdmin(s.DeleteSchedule))
+	s.Get("/api/v1/schedules/{id}/final_shifts", s.GetScheduleFinalShifts)
+	s.Get("/api/v1/schedules/{id}/on_call", s.GetScheduleOnCall)
+	s.Get("/api/v1/schedules/{id}/quality", s.GetScheduleQuality)
+	s.Post("/api/v1/schedules/{id}/overrides", s.editorOrAdmin(s.CreateScheduleOverride))
+	s.Delete("/api/v1/schedules/{id}/overrides/{override_id}", s.editorOrAdmin(s.DeleteScheduleOverride))
+	s.Post("/api/v1/schedules/{id}/ical", s.editorOrAdmin(s.ImportScheduleICal))
+	s.Post("/api/v1/schedules/{id}/ical/sync", s.editorOrAdmin(s.SyncScheduleICal))
+	s.Feed("/api/v1/schedules/{id}/export", s.ExportSchedule)
+	s.Post("/api/v1/schedules/{id}/export_token", s.scheduleTeamMemberOrAdmin(s.CreateScheduleExportToken))
+	s.Delete("/api/v1/schedules/{id}/export_token", s.scheduleTeamMemberOrAdmin(s.DeleteScheduleExportToken))
+	s.Get("/api/v1/shift_swaps", s.ListShiftSwaps)
+	s.Post("/api/v1/shift_swaps", s.editorOrAdmin(s.CreateShiftSwap))
+	s.Get("/api/v1/shift_swaps/{id}", s.GetShiftSwap)
+	s.Put("/api/v1/shift_swaps/{id}", s.editorOrAdmin(s.UpdateShiftSwap))
+	s.Delete("/api/v1/shift_swaps/{id}", s.editorOrAdmin(s.DeleteShiftSwap))
+	s.Post("/api/v1/shift_swaps/{id}/take", s.editorOrAdmin(s.TakeShiftSwap))
+	s.Get("/api/v1/shift_swaps/{id}/history", s.GetShiftSwapHistory)
+	s.Get("/api/v1/users", s.ListUsers)
+	s.Post("/api/v1/users", s.CreateUser)
+	s.Get("/api/v1/users/current", s.GetCurrentUser)
+	s.Get("/api/v1/users/{id}", s.GetUser)
+	s.Put("/api/v1/users/{id}", s.selfOrAdmin(s.UpdateUser))
+	s.Delete("/api/v1/users/{id}", s.adminOnly(s.DeleteUser))
+	s.Post("/api/v1/users/{id}/phone_verification", s.selfOrAdmin(s.RequestPhoneVerification))
+	s.Post("/api/v1/users/{id}/phone_verification/confirm", s.selfOrAdmin(s.ConfirmPhoneVerification))
+	s.Get("/api/v1/users/{id}/notification_policies", s.ListNotificationPolicies)
+	s.Get("/api/v1/users/{id}/notification_policies/{importance}", s.GetNotificationPolicy)
+	s.Put("/api/v1/users/{id}/notification_policies/{importance}", s.selfOrAdmin(s.UpdateNotificationPolicy))
+	s.Post("/api/v1/users/{id}/telegram_link", s.selfOrAdmin(s.CreateTelegramLink))
+	s.Get("/api/v1/users/{id}/quiet_hours", s.GetQuietHours)
+	s.Put("/api/v1/users/{id}/quiet_hours", s.selfOrAdmin(s.UpdateQuietHours))
+	s.Get("/api/v1/users/{id}/shift_notifications", s.GetShiftNotificationPreferences)
+	s.Put("/api/v1/users/{id}/shift_notifications", s.selfOrAdmin(s.UpdateShiftNotificationPreferences))
+	s.Feed("/api/v1/users/{id}/ical", s.ExportUserSchedule)
+	s.Post("/api/v1/users/{id}/ical_token", s.selfOrAdmin(s.CreateUserExportToken))
+	s.Delete("/api/v1/users/{id}/ical_token", s.selfOrAdmin(s.DeleteUserExportToken))
+	s.Get("/api/v1/teams", s.ListTeams)
+	s.Post("/api/v1/teams", s.adminOnly(s.CreateTeam))
+	s.Get("/api/v1/teams/{id}", s.GetTeam)
+	s.Put("/api/v1/teams/{id}", s.teamMemberOrAdmin(s.UpdateTeam))
+	s.Delete("/api/v1/teams/{id}", s.adminOnly(s.DeleteTeam))
+	s.Post("/api/v1/teams/{id}/members", s.teamMemberOrAdmin(s.AddTeamMember))
+	s.Delete("/api/v1/teams/{id}/members/{user_id}", s.teamMemberOrAdmin(s.RemoveTeamMember))
+	s.Get("/api/v1/notifiers", s.ListNotifiers)
+	s.Get("/api/v1/webhooks", s.ListWebhooks)
+	s.Post("/api/v1/webhooks", s.adminOnly(s.CreateWebhook))
+	s.Get("/api/v1/webhooks/{id}", s.GetWebhook)
+	s.Put("/api/v1/webhooks/{id}", s.adminOnly(s.UpdateWebhook))
+	s.Delete("/api/v1/webhooks/{id}", s.adminOnly(s.DeleteWebhook))
+	s.Get("/api/v1/webhooks/{id}/responses", s.GetWebhookResponses)
+
+	s.Post("/slack/interactive", s.SlackInteraction)
+	s.Post("/slack/commands", s.SlackCommand)
+	s.Post("/telegram/webhook", s.TelegramWebhook)
+	s.Post("/"+twilio.SMSStatusPath, s.TwilioSMSStatus)
+	s.Post("/"+twilio.CallStatusPath, s.TwilioCallStatus)
+	s.Post("/"+twilio.CallGatherPath, s.TwilioCallGather)
+	s.Post("/email/inbound/{token}", s.ReceiveEmail)
+	s.Post("/"+mattermost.ActionsPath, s.MattermostAction)
+	s.Post("/"+ntfy.ActionsPath+"/{token}/{action}", s.NtfyAction)
+
+	s.Post("/integrations/v1/{type}/{token}", s.ReceiveAlert)
+	s.Post("/integrations/v1/{type}/{token}/", s.ReceiveAlert)
+	s.Get("/integrations/v1/{type}/{token}/heartbeat", s.ReceiveHeartbeat)
+	s.Get("/integrations/v1/{type}/{token}/heartbeat/", s.ReceiveHeartbeat)
+	s.Post("/integrations/v1/{type}/{token}/heartbeat", s.ReceiveHeartbeat)
+	s.Post("/integrations/v1/{type}/{token}/heartbeat/", s.ReceiveHeartbeat)
 }
 
 func (s *HTTPServer) getListener() (net.Listener, error) {
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/integration"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// maxAlertPayloadSize limits the size of alert payloads posted to
+// integrations.
+const maxAlertPayloadSize = 1 << 20
+
+func (s *HTTPServer) ListIntegrations(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	integrations, err := s.integrations.ListIntegrations(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.Integration, 0, len(integrations))
+	for _, i := range integrations {
+		result = append(result, s.toIntegrationDTO(i))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	i, err := s.integrations.GetIntegration(r.Context(), ctx.Param("id"))
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.toIntegrationDTO(i))
+}
+
+func (s *HTTPServer) CreateIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.CreateIntegrationRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	i := &integration.Integration{
+		Name:             req.Name,
+		Type:             integration.Type(req.Type),
+		TeamID:           req.TeamID,
+		HeartbeatTimeout: time.Duration(req.HeartbeatTimeout) * time.Second,
+	}
+
+	if err := s.integrations.CreateIntegration(r.Context(), i); err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.toIntegrationDTO(i))
+}
+
+func (s *HTTPServer) UpdateIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.UpdateIntegrationRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	i := &integration.Integration{
+		ID:               ctx.Param("id"),
+		Name:             req.Name,
+		TeamID:           req.TeamID,
+		HeartbeatTimeout: time.Duration(req.HeartbeatTimeout) * time.Second,
+	}
+
+	if err := s.integrations.UpdateIntegration(r.Context(), i); err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.toIntegrationDTO(i))
+}
+
+func (s *HTTPServer) DeleteIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.integrations.DeleteIntegration(r.Context(), ctx.Param("id")); err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReceiveAlert accepts alerts posted by alert sources to the integration URL.
+func (s *HTTPServer) ReceiveAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAlertPayloadSize))
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid alert payload")
+		return
+	}
+
+	err = s.integrations.Receive(r.Context(), integration.Type(ctx.Param("type")), ctx.Param("token"), payload)
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ReceiveHeartbeat records a heartbeat sent to the integration heartbeat URL.
+func (s *HTTPServer) ReceiveHeartbeat(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	err := s.integrations.Heartbeat(r.Context(), integration.Type(ctx.Param("type")), ctx.Param("token"))
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *HTTPServer) ListRoutes(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	integrationID := ctx.Query("integration_id")
+	if integrationID == "" {
+		errorJSON(ctx, http.StatusBadRequest, "integration_id is required")
+		return
+	}
+
+	routes, err := s.integrations.ListRoutes(r.Context(), integrationID)
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.Route, 0, len(routes))
+	for _, route := range routes {
+		result = append(result, toRouteDTO(route))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetRoute(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	route, err := s.integrations.GetRoute(r.Context(), ctx.Param("id"))
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toRouteDTO(route))
+}
+
+func (s *HTTPServer) CreateRoute(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	route, err := decodeRoute(r)
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	if err := s.integrations.CreateRoute(r.Context(), route); err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toRouteDTO(route))
+}
+
+func (s *HTTPServer) UpdateRoute(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	route, err := decodeRoute(r)
+	if err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	route.ID = ctx.Param("id")
+
+	if err := s.integrations.UpdateRoute(r.Context(), route); err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toRouteDTO(route))
+}
+
+func (s *HTTPServer) DeleteRoute(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.integrations.DeleteRoute(r.Context(), ctx.Param("id")); err != nil {
+		integrationError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeRoute(r *http.Request) (*integration.Route, error) {
+	req := &dto.Route{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		return nil, integration.ErrInvalidRoute
+	}
+
+	route := &integration.Route{
+		IntegrationID:      req.IntegrationID,
+		Position:           req.Position,
+		TemplateExpression: req.RoutingTemplate,
+		EscalationChainID:  req.EscalationChainID,
+		ChatChannel:        req.ChatChannel,
+	}
+
+	for _, m := range req.LabelMatchers {
+		matcher, err := integration.ParseMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		route.LabelMatchers = append(route.LabelMatchers, matcher)
+	}
+
+	return route, nil
+}
+
+func integrationError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, integration.ErrIntegrationNotFound), errors.Is(err, integration.ErrRouteNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, integration.ErrInvalidIntegration), errors.Is(err, integration.ErrInvalidRoute):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func (s *HTTPServer) toIntegrationDTO(i *integration.Integration) *dto.Integration {
+	link := s.Cfg.AppURL + "integrations/v1/" + string(i.Type) + "/" + i.Token + "/"
+
+	return &dto.Integration{
+		ID:     i.ID,
+		Name:   i.Name,
+		Type:   string(i.Type),
+		TeamID: i.TeamID,
+		Link:   link,
+		Heartbeat: &dto.Heartbeat{
+			Link:            link + "heartbeat/",
+			Timeout:         int64(i.HeartbeatTimeout / time.Second),
+			LastHeartbeatAt: i.LastHeartbeatAt,
+			Expired:         i.HeartbeatExpired,
+		},
+		CreatedAt: i.CreatedAt,
+	}
+}
+
+func toRouteDTO(r *integration.Route) *dto.Route {
+	result := &dto.Route{
+		ID:                r.ID,
+		IntegrationID:     r.IntegrationID,
+		Position:          r.Position,
+		IsDefault:         r.Default,
+		RoutingTemplate:   r.TemplateExpression,
+		EscalationChainID: r.EscalationChainID,
+		ChatChannel:       r.ChatChannel,
+	}
+
+	for _, m := range r.LabelMatchers {
+		result.LabelMatchers = append(result.LabelMatchers, m.String())
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/notifier/mattermost"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// maxMattermostRequestSize limits the body of action requests sent by
+// Mattermost.
+const maxMattermostRequestSize = 1 << 20
+
+// MattermostAction receives the actions taken with the buttons of alert
+// group messages posted to Mattermost.
+func (s *HTTPServer) MattermostAction(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.mattermost.Enabled() {
+		errorJSON(ctx, http.StatusNotFound, "Mattermost is not enabled")
+		return
+	}
+
+	req := &mattermost.ActionRequest{}
+	if err := render.DecodeJSON(io.LimitReader(r.Body, maxMattermostRequestSize), req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	res, err := s.mattermost.Action(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, mattermost.ErrInvalidToken) {
+			errorJSON(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// ListNotificationPolicies returns the default and important policies of a
+// user.
+func (s *HTTPServer) ListNotificationPolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	result := make([]*dto.NotificationPolicy, 0, 2)
+	for _, importance := range []notificationpolicy.Importance{notificationpolicy.ImportanceDefault, notificationpolicy.ImportanceImportant} {
+		p, err := s.notificationPolicies.Policy(r.Context(), ctx.Param("id"), importance)
+		if err != nil {
+			internalError(ctx, err)
+			return
+		}
+
+		result = append(result, toNotificationPolicyDTO(p))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetNotificationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	importance, err := notificationpolicy.ParseImportance(ctx.Param("importance"))
+	if err != nil {
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	p, err := s.notificationPolicies.Policy(r.Context(), ctx.Param("id"), importance)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toNotificationPolicyDTO(p))
+}
+
+// UpdateNotificationPolicy replaces the steps of the policy of a user at the
+// importance.
+func (s *HTTPServer) UpdateNotificationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	importance, err := notificationpolicy.ParseImportance(ctx.Param("importance"))
+	if err != nil {
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	req := &dto.NotificationPolicy{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	p := &notificationpolicy.Policy{
+		UserID:     ctx.Param("id"),
+		Importance: importance,
+		Steps:      make([]*notificationpolicy.Step, 0, len(req.Steps)),
+	}
+
+	for _, step := range req.Steps {
+		if step == nil {
+			continue
+		}
+
+		p.Steps = append(p.Steps, &notificationpolicy.Step{
+			Type:     notificationpolicy.StepType(step.Type),
+			Method:   step.Method,
+			Duration: time.Duration(step.Duration) * time.Second,
+		})
+	}
+
+	if err := s.notificationPolicies.SavePolicy(r.Context(), p); err != nil {
+		if errors.Is(err, notificationpolicy.ErrInvalidPolicy) {
+			errorJSON(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toNotificationPolicyDTO(p))
+}
+
+func toNotificationPolicyDTO(p *notificationpolicy.Policy) *dto.NotificationPolicy {
+	result := &dto.NotificationPolicy{
+		Importance: string(p.Importance),
+		Steps:      make([]*dto.NotificationPolicyStep, 0, len(p.Steps)),
+	}
+
+	for _, step := range p.Steps {
+		result.Steps = append(result.Steps, &dto.NotificationPolicyStep{
+			Type:     string(step.Type),
+			Method:   step.Method,
+			Duration: int64(step.Duration / time.Second),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+// ListNotifiers returns the enabled notifiers, whose types are the methods
+// that can be used in notification policies.
+func (s *HTTPServer) ListNotifiers(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	notifiers := s.notifiers.Notifiers()
+
+	result := make([]*dto.Notifier, 0, len(notifiers))
+	for t, caps := range notifiers {
+		result = append(result, &dto.Notifier{
+			Type:      t,
+			Address:   string(caps.Address),
+			Channel:   caps.Channel,
+			Actions:   caps.Actions,
+			MaxLength: caps.MaxLength,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func toDeliveryDTO(d *notifier.Delivery) *dto.Delivery {
+	return &dto.Delivery{
+		ID:                d.ID,
+		Type:              d.Type,
+		UserID:            d.UserID,
+		Channel:           d.Channel,
+		Address:           d.Address,
+		Title:             d.Title,
+		Status:            string(d.Status),
+		Error:             d.Error,
+		ProviderMessageID: d.ExternalID,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/notifier/ntfy"
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+// NtfyAction receives the actions of the buttons on ntfy notifications,
+// which are authenticated by the action token in the path.
+func (s *HTTPServer) NtfyAction(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.ntfy.Enabled() {
+		errorJSON(ctx, http.StatusNotFound, "ntfy is not enabled")
+		return
+	}
+
+	g, err := s.ntfy.Action(r.Context(), ctx.Param("token"), ctx.Param("action"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ntfy.ErrInvalidToken):
+			errorJSON(ctx, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, ntfy.ErrUnknownAction):
+			errorJSON(ctx, http.StatusNotFound, err.Error())
+		default:
+			alertGroupError(ctx, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toAlertGroupDTO(g))
+}
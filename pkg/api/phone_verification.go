@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// RequestPhoneVerification sends a verification code to the phone number of
+// the user.
+func (s *HTTPServer) RequestPhoneVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	v, err := s.users.RequestPhoneVerification(r.Context(), ctx.Param("id"))
+	if err != nil {
+		phoneVerificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, &dto.PhoneVerification{
+		Phone:     v.Phone,
+		ExpiresAt: v.ExpiresAt,
+	})
+}
+
+// ConfirmPhoneVerification marks the phone number of the user as verified
+// with the code sent to it.
+func (s *HTTPServer) ConfirmPhoneVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	var req dto.PhoneVerificationConfirm
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	u, err := s.users.ConfirmPhoneVerification(r.Context(), ctx.Param("id"), strings.TrimSpace(req.Code))
+	if err != nil {
+		phoneVerificationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserDTO(u))
+}
+
+func phoneVerificationError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, user.ErrVerificationRateLimited):
+		errorJSON(ctx, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, user.ErrNoPhone),
+		errors.Is(err, user.ErrInvalidVerificationCode),
+		errors.Is(err, user.ErrVerificationCodeExpired):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, user.ErrNoSMSProvider):
+		errorJSON(ctx, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, user.ErrPhoneAlreadyVerified):
+		errorJSON(ctx, http.StatusConflict, err.Error())
+	default:
+		userError(ctx, err)
+	}
+}
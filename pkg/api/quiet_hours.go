@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/ical"
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+const timeOfDayLayout = "15:04"
+
+func (s *HTTPServer) GetQuietHours(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	q, err := s.notificationPolicies.QuietHours(r.Context(), ctx.Param("id"))
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toQuietHoursDTO(q))
+}
+
+// UpdateQuietHours replaces the quiet hours of a user.
+func (s *HTTPServer) UpdateQuietHours(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	req := &dto.QuietHours{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	q, err := decodeQuietHours(ctx.Param("id"), req)
+	if err == nil {
+		err = s.notificationPolicies.SaveQuietHours(r.Context(), q)
+	}
+
+	if err != nil {
+		if errors.Is(err, notificationpolicy.ErrInvalidQuietHours) {
+			errorJSON(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toQuietHoursDTO(q))
+}
+
+func decodeQuietHours(userID string, req *dto.QuietHours) (*notificationpolicy.QuietHours, error) {
+	q := &notificationpolicy.QuietHours{
+		UserID:            userID,
+		Action:            notificationpolicy.QuietAction(req.Action),
+		DowngradeMethod:   req.DowngradeMethod,
+		DoNotDisturbUntil: req.DoNotDisturbUntil,
+		Windows:           make([]*notificationpolicy.QuietWindow, 0, len(req.Windows)),
+	}
+
+	if q.Action == "" {
+		q.Action = notificationpolicy.QuietDefer
+	}
+
+	if q.Action == notificationpolicy.QuietDowngrade && q.DowngradeMethod == "" {
+		q.DowngradeMethod = notificationpolicy.DefaultMethod
+	}
+
+	for _, win := range req.Windows {
+		if win == nil {
+			continue
+		}
+
+		start, err := parseTimeOfDay(win.Start)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := parseTimeOfDay(win.End)
+		if err != nil {
+			return nil, err
+		}
+
+		window := &notificationpolicy.QuietWindow{Start: start, End: end}
+		for _, code := range win.Days {
+			day, err := ical.ParseWeekday(code)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unknown day of the week %q", notificationpolicy.ErrInvalidQuietHours, code)
+			}
+			window.Days = append(window.Days, day)
+		}
+
+		q.Windows = append(q.Windows, window)
+	}
+
+	return q, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse(timeOfDayLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time of day %q, expected HH:MM", notificationpolicy.ErrInvalidQuietHours, s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func toQuietHoursDTO(q *notificationpolicy.QuietHours) *dto.QuietHours {
+	result := &dto.QuietHours{
+		Windows:           make([]*dto.QuietWindow, 0, len(q.Windows)),
+		Action:            string(q.Action),
+		DowngradeMethod:   q.DowngradeMethod,
+		DoNotDisturbUntil: q.DoNotDisturbUntil,
+	}
+
+	var midnight time.Time
+	for _, win := range q.Windows {
+		window := &dto.QuietWindow{
+			Start: midnight.Add(win.Start).Format(timeOfDayLayout),
+			End:   midnight.Add(win.End).Format(timeOfDayLayout),
+		}
+		for _, day := range win.Days {
+			window.Days = append(window.Days, ical.WeekdayCode(day))
+		}
+		result.Windows = append(result.Windows, window)
+	}
+
+	return result
+}
@@ -3,28 +3,55 @@ package api
 import (
 	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/InariTheFox/oncall/pkg/web"
 	"github.com/go-chi/chi/v5"
 )
 
+func (s *HTTPServer) Delete(pattern string, h web.Handler) {
+	s.route(pattern, http.MethodDelete, h)
+}
+
 func (s *HTTPServer) Get(pattern string, h web.Handler) {
 	s.route(pattern, http.MethodGet, h)
 }
 
+func (s *HTTPServer) Patch(pattern string, h web.Handler) {
+	s.route(pattern, http.MethodPatch, h)
+}
+
 func (s *HTTPServer) Post(pattern string, h web.Handler) {
 	s.route(pattern, http.MethodPost, h)
 }
 
+func (s *HTTPServer) Put(pattern string, h web.Handler) {
+	s.route(pattern, http.MethodPut, h)
+}
+
+// Feed registers a GET route of the API which authenticates requests by a
+// token in the URL instead of the Grafana plugin proxy, for calendar apps and
+// other clients that cannot go through the proxy.
+func (s *HTTPServer) Feed(pattern string, h web.Handler) {
+	s.handle(pattern, http.MethodGet, h, false)
+}
+
+// route registers a route, which only serves requests from the Grafana
+// plugin proxy when it is part of the API.
 func (s *HTTPServer) route(pattern string, method string, h web.Handler) {
-	s.router.Route(pattern, func(r chi.Router) {
+	s.handle(pattern, method, h, strings.HasPrefix(pattern, "/api/"))
+}
+
+func (s *HTTPServer) handle(pattern string, method string, h web.Handler, authenticate bool) {
+	s.router.Group(func(r chi.Router) {
 
 		r.Use(s.Middleware)
 		r.Use(web.Renderer(filepath.Join(s.Cfg.StaticRootPath, "views"), "[[", "]]"))
 
-		switch method {
-		case http.MethodGet:
-			r.Get(pattern, h)
+		if authenticate {
+			r.Use(s.Authenticate)
 		}
+
+		r.Method(method, pattern, h)
 	})
 }
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/ical"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+const (
+	// exportPast and exportAhead bound the shifts included in calendar
+	// feeds.
+	exportPast  = 30 * 24 * time.Hour
+	exportAhead = 180 * 24 * time.Hour
+
+	contentTypeCalendar = "text/calendar; charset=UTF-8"
+)
+
+// ExportSchedule renders the final shifts of a schedule as a calendar. Calendar
+// apps cannot send credentials, so the feed is authorized by the token query
+// parameter.
+func (s *HTTPServer) ExportSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+	id := ctx.Param("id")
+
+	if err := s.schedules.AuthenticateExport(r.Context(), ctx.Query("token"), id, ""); err != nil {
+		exportError(ctx, err)
+		return
+	}
+
+	sched, err := s.schedules.Get(r.Context(), id)
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	now := time.Now()
+	shifts, err := s.schedules.FinalShifts(r.Context(), id, now.Add(-exportPast), now.Add(exportAhead))
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	names, err := s.userNames(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	cal := &ical.Calendar{Name: sched.Name}
+	for _, shift := range shifts {
+		onCall := names.join(shift.UserIDs)
+		cal.Events = append(cal.Events, shiftEvent(shift, onCall, sched.Name, onCall))
+	}
+
+	writeCalendar(ctx, cal)
+}
+
+// ExportUserSchedule renders the shifts of a user across all schedules as a
+// calendar, authorized like ExportSchedule.
+func (s *HTTPServer) ExportUserSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+	userID := ctx.Param("id")
+
+	if err := s.schedules.AuthenticateExport(r.Context(), ctx.Query("token"), "", userID); err != nil {
+		exportError(ctx, err)
+		return
+	}
+
+	schedules, err := s.schedules.List(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	scheduleNames := make(map[string]string, len(schedules))
+	for _, sched := range schedules {
+		scheduleNames[sched.ID] = sched.Name
+	}
+
+	names, err := s.userNames(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	now := time.Now()
+	shifts, err := s.schedules.UserShifts(r.Context(), userID, now.Add(-exportPast), now.Add(exportAhead))
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	cal := &ical.Calendar{Name: "On-call shifts of " + names.name(userID)}
+	for _, shift := range shifts {
+		name := scheduleNames[shift.ScheduleID]
+		cal.Events = append(cal.Events, shiftEvent(shift, "On call: "+name, name, names.join(shift.UserIDs)))
+	}
+
+	writeCalendar(ctx, cal)
+}
+
+func (s *HTTPServer) CreateScheduleExportToken(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+	id := ctx.Param("id")
+
+	t, err := s.schedules.CreateExportToken(r.Context(), id, "")
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.toExportTokenDTO(t, "api/v1/schedules/"+url.PathEscape(id)+"/export"))
+}
+
+func (s *HTTPServer) DeleteScheduleExportToken(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.schedules.RevokeExportToken(r.Context(), ctx.Param("id"), ""); err != nil {
+		exportTokenError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) CreateUserExportToken(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+	id := ctx.Param("id")
+
+	t, err := s.schedules.CreateExportToken(r.Context(), "", id)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, s.toExportTokenDTO(t, "api/v1/users/"+url.PathEscape(id)+"/ical"))
+}
+
+func (s *HTTPServer) DeleteUserExportToken(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.schedules.RevokeExportToken(r.Context(), "", ctx.Param("id")); err != nil {
+		exportTokenError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userNames maps the IDs of users to the names shown in calendar feeds.
+type userNames map[string]string
+
+// userNames returns the display names of the users, or their usernames when
+// they have none.
+func (s *HTTPServer) userNames(ctx context.Context) (userNames, error) {
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(userNames, len(users))
+	for _, u := range users {
+		names[u.ID] = cmp.Or(u.Name, u.Username)
+	}
+
+	return names, nil
+}
+
+// name returns the name of the user, or the ID of users which no longer
+// exist.
+func (n userNames) name(id string) string {
+	return cmp.Or(n[id], id)
+}
+
+func (n userNames) join(ids []string) string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = n.name(id)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// shiftEvent turns a shift into a calendar event. The UID only depends on
+// the shift so calendar apps can follow changes between refreshes.
+func shiftEvent(shift *schedule.Shift, summary, scheduleName, onCall string) *ical.Event {
+	return &ical.Event{
+		UID:         fmt.Sprintf("%s-%d-%d@oncall", shift.ScheduleID, shift.Start.Unix(), shift.End.Unix()),
+		Summary:     summary,
+		Description: fmt.Sprintf("Schedule: %s\nOn call: %s", scheduleName, onCall),
+		Start:       shift.Start,
+		End:         shift.End,
+	}
+}
+
+func writeCalendar(ctx *web.Context, cal *ical.Calendar) {
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.Bytes(http.StatusOK, contentTypeCalendar, buf.Bytes())
+}
+
+func exportError(ctx *web.Context, err error) {
+	if errors.Is(err, schedule.ErrExportTokenNotFound) {
+		errorJSON(ctx, http.StatusUnauthorized, "Invalid export token")
+		return
+	}
+
+	internalError(ctx, err)
+}
+
+func exportTokenError(ctx *web.Context, err error) {
+	if errors.Is(err, schedule.ErrExportTokenNotFound) {
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	internalError(ctx, err)
+}
+
+func (s *HTTPServer) toExportTokenDTO(t *schedule.ExportToken, path string) *dto.ExportToken {
+	return &dto.ExportToken{
+		Token:     t.Token,
+		CreatedAt: t.CreatedAt,
+		ExportURL: s.Cfg.AppURL + path + "?token=" + t.Token,
+	}
+}
@@ -0,0 +1,449 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/ical"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+const (
+	// defaultShiftsRange is how far ahead shifts are listed when no end is
+	// given, and maxShiftsRange how far apart start and end may be.
+	defaultShiftsRange = 7 * 24 * time.Hour
+	maxShiftsRange     = 90 * 24 * time.Hour
+)
+
+const (
+	// defaultQualityWeeks and maxQualityWeeks bound how far ahead schedule
+	// quality is checked.
+	defaultQualityWeeks = 2
+	maxQualityWeeks     = 52
+)
+
+func (s *HTTPServer) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	schedules, err := s.schedules.List(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.Schedule, 0, len(schedules))
+	for _, sched := range schedules {
+		result = append(result, toScheduleDTO(sched))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	sched, err := s.schedules.Get(r.Context(), ctx.Param("id"))
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toScheduleDTO(sched))
+}
+
+func (s *HTTPServer) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	sched, err := decodeSchedule(r)
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	if err := s.schedules.Create(r.Context(), sched); err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toScheduleDTO(sched))
+}
+
+func (s *HTTPServer) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	sched, err := decodeSchedule(r)
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	sched.ID = ctx.Param("id")
+
+	if err := s.schedules.Update(r.Context(), sched); err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toScheduleDTO(sched))
+}
+
+func (s *HTTPServer) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.schedules.Delete(r.Context(), ctx.Param("id")); err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) CreateScheduleOverride(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.ScheduleOverride{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	o := &schedule.Override{
+		UserIDs: req.UserIDs,
+		Start:   req.Start,
+		End:     req.End,
+	}
+
+	if err := s.schedules.AddOverride(r.Context(), ctx.Param("id"), o); err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toOverrideDTO(o))
+}
+
+func (s *HTTPServer) DeleteScheduleOverride(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.schedules.DeleteOverride(r.Context(), ctx.Param("id"), ctx.Param("override_id")); err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportScheduleICal replaces the events of an iCal schedule with the
+// calendar in the request body.
+func (s *HTTPServer) ImportScheduleICal(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	sched, err := s.schedules.ImportICal(r.Context(), ctx.Param("id"), r.Body)
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toScheduleDTO(sched))
+}
+
+// SyncScheduleICal fetches the calendar of an iCal schedule right away.
+func (s *HTTPServer) SyncScheduleICal(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	sched, err := s.schedules.SyncICal(r.Context(), ctx.Param("id"))
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toScheduleDTO(sched))
+}
+
+// GetScheduleFinalShifts lists who is on call between start and end, which
+// default to now and a week from now.
+func (s *HTTPServer) GetScheduleFinalShifts(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	from, to, err := parseTimeRange(r, defaultShiftsRange, maxShiftsRange)
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	shifts, err := s.schedules.FinalShifts(r.Context(), ctx.Param("id"), from, to)
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toShiftDTOs(shifts))
+}
+
+func (s *HTTPServer) GetScheduleOnCall(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	at := time.Now()
+	if t, err := parseTimeParam(r.URL.Query(), "at"); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+		return
+	} else if t != nil {
+		at = *t
+	}
+
+	users, err := s.schedules.OnCallUserIDs(r.Context(), ctx.Param("id"), at)
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &dto.OnCall{At: at, UserIDs: users})
+}
+
+// GetScheduleQuality reports gaps, overlaps and the load of each participant
+// over the number of weeks from now given by the weeks query parameter.
+func (s *HTTPServer) GetScheduleQuality(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	weeks := defaultQualityWeeks
+	if v := ctx.Query("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxQualityWeeks {
+			errorJSON(ctx, http.StatusBadRequest, fmt.Sprintf("weeks must be between 1 and %d", maxQualityWeeks))
+			return
+		}
+		weeks = n
+	}
+
+	from := time.Now()
+	q, err := s.schedules.Quality(r.Context(), ctx.Param("id"), from, from.AddDate(0, 0, 7*weeks))
+	if err != nil {
+		scheduleError(ctx, err)
+		return
+	}
+
+	result := &dto.ScheduleQuality{
+		From:     q.From,
+		To:       q.To,
+		Score:    q.Score,
+		Coverage: q.Coverage,
+		Balance:  q.Balance,
+		Gaps:     make([]*dto.SchedulePeriod, 0, len(q.Gaps)),
+		Overlaps: make([]*dto.ScheduleOverlap, 0, len(q.Overlaps)),
+		Load:     make([]*dto.ScheduleLoad, 0, len(q.Load)),
+	}
+
+	for _, gap := range q.Gaps {
+		result.Gaps = append(result.Gaps, &dto.SchedulePeriod{Start: gap.Start, End: gap.End})
+	}
+
+	for _, o := range q.Overlaps {
+		result.Overlaps = append(result.Overlaps, &dto.ScheduleOverlap{Start: o.Start, End: o.End, UserIDs: o.UserIDs})
+	}
+
+	for _, l := range q.Load {
+		result.Load = append(result.Load, &dto.ScheduleLoad{UserID: l.UserID, OnCall: int64(l.OnCall / time.Second)})
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// parseTimeRange reads the start and end query parameters, defaulting to now
+// and the default range after start. Ranges longer than maxRange are refused,
+// as resolving shifts takes longer the longer the range.
+func parseTimeRange(r *http.Request, defaultRange, maxRange time.Duration) (time.Time, time.Time, error) {
+	values := r.URL.Query()
+
+	from := time.Now()
+	if t, err := parseTimeParam(values, "start"); err != nil {
+		return time.Time{}, time.Time{}, err
+	} else if t != nil {
+		from = *t
+	}
+
+	to := from.Add(defaultRange)
+	if t, err := parseTimeParam(values, "end"); err != nil {
+		return time.Time{}, time.Time{}, err
+	} else if t != nil {
+		to = *t
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, errors.New("end must be after start")
+	}
+
+	if to.Sub(from) > maxRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be at most %d days after start", maxRange/(24*time.Hour))
+	}
+
+	return from, to, nil
+}
+
+func decodeSchedule(r *http.Request) (*schedule.Schedule, error) {
+	req := &dto.Schedule{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		return nil, schedule.ErrInvalidSchedule
+	}
+
+	sched := &schedule.Schedule{
+		Name:        req.Name,
+		TeamID:      req.TeamID,
+		Type:        schedule.Type(req.Type),
+		TimeZone:    req.TimeZone,
+		ChatChannel: req.ChatChannel,
+	}
+
+	if sched.Type == "" {
+		sched.Type = schedule.TypeWeb
+	}
+
+	if sched.TimeZone == "" {
+		sched.TimeZone = "UTC"
+	}
+
+	if req.ICal != nil {
+		sched.ICalURL = req.ICal.URL
+	}
+
+	for _, l := range req.Layers {
+		if l == nil {
+			continue
+		}
+
+		layer := &schedule.Layer{Priority: l.Priority}
+		for _, rot := range l.Rotations {
+			if rot == nil {
+				continue
+			}
+
+			rotation := &schedule.Rotation{
+				ID:           rot.ID,
+				Name:         rot.Name,
+				Frequency:    schedule.Frequency(rot.Frequency),
+				Interval:     rot.Interval,
+				Start:        rot.Start,
+				Duration:     time.Duration(rot.Duration) * time.Second,
+				Until:        rot.Until,
+				TimeZone:     rot.TimeZone,
+				Participants: rot.Participants,
+			}
+
+			if rotation.Interval == 0 {
+				rotation.Interval = 1
+			}
+
+			for _, code := range rot.ByDay {
+				day, err := ical.ParseWeekday(code)
+				if err != nil {
+					return nil, fmt.Errorf("%w: unknown day of the week %q", schedule.ErrInvalidSchedule, code)
+				}
+				rotation.ByDay = append(rotation.ByDay, day)
+			}
+
+			layer.Rotations = append(layer.Rotations, rotation)
+		}
+
+		sched.Layers = append(sched.Layers, layer)
+	}
+
+	return sched, nil
+}
+
+func scheduleError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, schedule.ErrScheduleNotFound), errors.Is(err, schedule.ErrOverrideNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, schedule.ErrInvalidSchedule):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toScheduleDTO(sched *schedule.Schedule) *dto.Schedule {
+	result := &dto.Schedule{
+		ID:          sched.ID,
+		Name:        sched.Name,
+		TeamID:      sched.TeamID,
+		Type:        string(sched.Type),
+		TimeZone:    sched.TimeZone,
+		ChatChannel: sched.ChatChannel,
+		Layers:      make([]*dto.ScheduleLayer, 0, len(sched.Layers)),
+		Overrides:   make([]*dto.ScheduleOverride, 0, len(sched.Overrides)),
+	}
+
+	if sched.Type == schedule.TypeICal {
+		result.ICal = &dto.ScheduleICal{
+			URL:        sched.ICalURL,
+			EventCount: len(sched.ICalEvents),
+			Error:      sched.ICalError,
+			SyncedAt:   sched.ICalSyncedAt,
+		}
+	}
+
+	for _, layer := range sched.Layers {
+		l := &dto.ScheduleLayer{
+			Priority:  layer.Priority,
+			Rotations: make([]*dto.ScheduleRotation, 0, len(layer.Rotations)),
+		}
+
+		for _, r := range layer.Rotations {
+			rot := &dto.ScheduleRotation{
+				ID:           r.ID,
+				Name:         r.Name,
+				Frequency:    string(r.Frequency),
+				Interval:     r.Interval,
+				Start:        r.Start,
+				Duration:     int64(r.Duration / time.Second),
+				Until:        r.Until,
+				TimeZone:     r.TimeZone,
+				Participants: r.Participants,
+			}
+
+			for _, day := range r.ByDay {
+				rot.ByDay = append(rot.ByDay, ical.WeekdayCode(day))
+			}
+
+			l.Rotations = append(l.Rotations, rot)
+		}
+
+		result.Layers = append(result.Layers, l)
+	}
+
+	for _, o := range sched.Overrides {
+		result.Overrides = append(result.Overrides, toOverrideDTO(o))
+	}
+
+	return result
+}
+
+func toOverrideDTO(o *schedule.Override) *dto.ScheduleOverride {
+	return &dto.ScheduleOverride{
+		ID:      o.ID,
+		UserIDs: o.UserIDs,
+		Start:   o.Start,
+		End:     o.End,
+	}
+}
+
+func toShiftDTOs(shifts []*schedule.Shift) []*dto.Shift {
+	result := make([]*dto.Shift, 0, len(shifts))
+	for _, shift := range shifts {
+		result = append(result, &dto.Shift{
+			UserIDs:    shift.UserIDs,
+			Start:      shift.Start,
+			End:        shift.End,
+			RotationID: shift.RotationID,
+			OverrideID: shift.OverrideID,
+			EventID:    shift.EventID,
+			Priority:   shift.Priority,
+		})
+	}
+
+	return result
+}
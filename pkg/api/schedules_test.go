@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	start := time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default range", query: "start=2025-01-06T00:00:00Z", want: defaultShiftsRange},
+		{name: "end", query: "start=2025-01-06T00:00:00Z&end=2025-01-20T00:00:00Z", want: 14 * 24 * time.Hour},
+		{name: "longest range", query: "start=2025-01-06T00:00:00Z&end=2025-04-06T00:00:00Z", want: maxShiftsRange},
+		{name: "too long", query: "start=2025-01-06T00:00:00Z&end=2025-04-06T00:00:01Z", wantErr: true},
+		{name: "end before start", query: "start=2025-01-06T00:00:00Z&end=2025-01-05T00:00:00Z", wantErr: true},
+		{name: "invalid start", query: "start=tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/schedules/s/final_shifts?"+tt.query, nil)
+
+			from, to, err := parseTimeRange(r, defaultShiftsRange, maxShiftsRange)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseTimeRange() = %s, %s, want an error", from, to)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseTimeRange() error = %v", err)
+			}
+
+			if !from.Equal(start) || to.Sub(from) != tt.want {
+				t.Errorf("parseTimeRange() = %s, %s, want %s and %s after", from, to, start, tt.want)
+			}
+		})
+	}
+}
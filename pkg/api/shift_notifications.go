@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/shiftnotify"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+func (s *HTTPServer) GetShiftNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	p, err := s.shiftNotifications.Preferences(r.Context(), ctx.Param("id"))
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toShiftNotificationPreferencesDTO(p))
+}
+
+func (s *HTTPServer) UpdateShiftNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.userExists(ctx) {
+		return
+	}
+
+	req := &dto.ShiftNotificationPreferences{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	p := &shiftnotify.Preferences{
+		UserID:       ctx.Param("id"),
+		NotifyBefore: time.Duration(req.NotifyBefore) * time.Second,
+		NotifyOnEnd:  req.NotifyOnEnd,
+	}
+
+	if err := s.shiftNotifications.SavePreferences(r.Context(), p); err != nil {
+		if errors.Is(err, shiftnotify.ErrInvalidPreferences) {
+			errorJSON(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toShiftNotificationPreferencesDTO(p))
+}
+
+func toShiftNotificationPreferencesDTO(p *shiftnotify.Preferences) *dto.ShiftNotificationPreferences {
+	return &dto.ShiftNotificationPreferences{
+		NotifyBefore: int64(p.NotifyBefore / time.Second),
+		NotifyOnEnd:  p.NotifyOnEnd,
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/shiftswap"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// ListShiftSwaps lists shift swaps, optionally filtered by the schedule and
+// status query parameters.
+func (s *HTTPServer) ListShiftSwaps(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	status := shiftswap.Status(ctx.Query("status"))
+	if status != "" && !slices.Contains([]shiftswap.Status{shiftswap.StatusOpen, shiftswap.StatusTaken, shiftswap.StatusDeleted, shiftswap.StatusPastDue}, status) {
+		errorJSON(ctx, http.StatusBadRequest, "Unknown shift swap status")
+		return
+	}
+
+	swaps, err := s.shiftSwaps.List(r.Context(), ctx.Query("schedule_id"), status)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.ShiftSwap, 0, len(swaps))
+	for _, swap := range swaps {
+		result = append(result, toShiftSwapDTO(swap))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetShiftSwap(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	swap, err := s.shiftSwaps.Get(r.Context(), ctx.Param("id"))
+	if err != nil {
+		shiftSwapError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toShiftSwapDTO(swap))
+}
+
+// CreateShiftSwap requests a swap of the shifts of the requesting user.
+func (s *HTTPServer) CreateShiftSwap(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.ShiftSwapRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	swap := &shiftswap.ShiftSwap{
+		ScheduleID:  req.ScheduleID,
+		Beneficiary: actor,
+		SwapStart:   req.SwapStart,
+		SwapEnd:     req.SwapEnd,
+		Description: req.Description,
+	}
+
+	if err := s.shiftSwaps.Create(r.Context(), swap); err != nil {
+		shiftSwapError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toShiftSwapDTO(swap))
+}
+
+func (s *HTTPServer) UpdateShiftSwap(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.ShiftSwapRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	swap, err := s.shiftSwaps.Update(r.Context(), ctx.Param("id"), actor, req.SwapStart, req.SwapEnd, req.Description)
+	if err != nil {
+		shiftSwapError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toShiftSwapDTO(swap))
+}
+
+func (s *HTTPServer) DeleteShiftSwap(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.shiftSwaps.Delete(r.Context(), ctx.Param("id"), actor); err != nil {
+		shiftSwapError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TakeShiftSwap accepts a swap request on behalf of the requesting user.
+func (s *HTTPServer) TakeShiftSwap(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	actor, ok := s.requestActor(ctx)
+	if !ok {
+		return
+	}
+
+	swap, err := s.shiftSwaps.Take(r.Context(), ctx.Param("id"), actor)
+	if err != nil {
+		shiftSwapError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toShiftSwapDTO(swap))
+}
+
+func (s *HTTPServer) GetShiftSwapHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	history, err := s.shiftSwaps.History(r.Context(), ctx.Param("id"))
+	if err != nil {
+		shiftSwapError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.ShiftSwapHistoryEntry, 0, len(history))
+	for _, e := range history {
+		result = append(result, &dto.ShiftSwapHistoryEntry{
+			ID:        e.ID,
+			Action:    string(e.Action),
+			Actor:     e.Actor,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func shiftSwapError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, shiftswap.ErrShiftSwapNotFound), errors.Is(err, schedule.ErrScheduleNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, shiftswap.ErrNotBeneficiary), errors.Is(err, shiftswap.ErrNotParticipant):
+		errorJSON(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, shiftswap.ErrShiftSwapNotOpen):
+		errorJSON(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, shiftswap.ErrInvalidShiftSwap), errors.Is(err, shiftswap.ErrBeneficiaryCannotTake):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toShiftSwapDTO(swap *shiftswap.ShiftSwap) *dto.ShiftSwap {
+	result := &dto.ShiftSwap{
+		ID:          swap.ID,
+		ScheduleID:  swap.ScheduleID,
+		Beneficiary: swap.Beneficiary,
+		Benefactor:  swap.Benefactor,
+		SwapStart:   swap.SwapStart,
+		SwapEnd:     swap.SwapEnd,
+		Description: swap.Description,
+		Status:      string(swap.Status),
+		OverrideIDs: swap.OverrideIDs,
+		TakenAt:     swap.TakenAt,
+		CreatedAt:   swap.CreatedAt,
+		UpdatedAt:   swap.UpdatedAt,
+	}
+
+	if result.OverrideIDs == nil {
+		result.OverrideIDs = []string{}
+	}
+
+	return result
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+// maxSlackRequestSize limits the body of requests sent by Slack.
+const maxSlackRequestSize = 1 << 20
+
+// SlackInteraction receives the buttons and menus used on messages posted by
+// the Slack app.
+func (s *HTTPServer) SlackInteraction(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	form, ok := s.slackRequest(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.slack.Interact(r.Context(), []byte(form.Get("payload"))); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SlackCommand runs the /oncall slash command.
+func (s *HTTPServer) SlackCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	form, ok := s.slackRequest(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.slack.Command(r.Context(), form.Get("user_id"), form.Get("text")))
+}
+
+// slackRequest verifies the signature of a request sent by Slack and parses
+// its form body.
+func (s *HTTPServer) slackRequest(ctx *web.Context) (url.Values, bool) {
+	if !s.slack.Enabled() {
+		errorJSON(ctx, http.StatusNotFound, "Slack is not enabled")
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxSlackRequestSize))
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+
+	if err := s.slack.Verify(ctx.Request.Header, body); err != nil {
+		errorJSON(ctx, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+
+	return form, true
+}
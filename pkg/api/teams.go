@@ -0,0 +1,174 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/team"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// ListTeams lists teams, optionally only those of the user_id query
+// parameter.
+func (s *HTTPServer) ListTeams(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	var (
+		teams []*team.Team
+		err   error
+	)
+
+	if userID := ctx.Query("user_id"); userID != "" {
+		teams, err = s.teams.TeamsOf(r.Context(), userID)
+	} else {
+		teams, err = s.teams.List(r.Context())
+	}
+
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.Team, 0, len(teams))
+	for _, t := range teams {
+		result = append(result, toTeamDTO(t))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	t, err := s.teams.Get(r.Context(), ctx.Param("id"))
+	if err != nil {
+		teamError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toTeamDTO(t))
+}
+
+func (s *HTTPServer) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.Team{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	t := &team.Team{
+		Name:        req.Name,
+		Email:       req.Email,
+		ChatChannel: req.ChatChannel,
+		MemberIDs:   req.MemberIDs,
+	}
+
+	if err := s.teams.Create(r.Context(), t); err != nil {
+		teamError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toTeamDTO(t))
+}
+
+// UpdateTeam replaces the details of a team. Members are changed through the
+// members endpoints.
+func (s *HTTPServer) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.Team{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	t := &team.Team{
+		ID:          ctx.Param("id"),
+		Name:        req.Name,
+		Email:       req.Email,
+		ChatChannel: req.ChatChannel,
+	}
+
+	if err := s.teams.Update(r.Context(), t); err != nil {
+		teamError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toTeamDTO(t))
+}
+
+func (s *HTTPServer) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.teams.Delete(r.Context(), ctx.Param("id")); err != nil {
+		teamError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) AddTeamMember(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.TeamMemberRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	t, err := s.teams.AddMember(r.Context(), ctx.Param("id"), req.UserID)
+	if err != nil {
+		teamError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toTeamDTO(t))
+}
+
+func (s *HTTPServer) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	t, err := s.teams.RemoveMember(r.Context(), ctx.Param("id"), ctx.Param("user_id"))
+	if err != nil {
+		teamError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toTeamDTO(t))
+}
+
+func teamError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, team.ErrTeamNotFound), errors.Is(err, user.ErrUserNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, team.ErrInvalidTeam):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, team.ErrTeamNameTaken):
+		errorJSON(ctx, http.StatusConflict, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toTeamDTO(t *team.Team) *dto.Team {
+	result := &dto.Team{
+		ID:          t.ID,
+		Name:        t.Name,
+		Email:       t.Email,
+		ChatChannel: t.ChatChannel,
+		MemberIDs:   t.MemberIDs,
+		CreatedAt:   t.CreatedAt,
+	}
+
+	if result.MemberIDs == nil {
+		result.MemberIDs = []string{}
+	}
+
+	return result
+}
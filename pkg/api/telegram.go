@@ -0,0 +1,63 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+// maxTelegramRequestSize limits the body of updates sent by Telegram.
+const maxTelegramRequestSize = 1 << 20
+
+// TelegramWebhook receives the updates of the Telegram bot.
+func (s *HTTPServer) TelegramWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.telegram.Enabled() {
+		errorJSON(ctx, http.StatusNotFound, "Telegram is not enabled")
+		return
+	}
+
+	if err := s.telegram.Verify(r.Header); err != nil {
+		errorJSON(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTelegramRequestSize))
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := s.telegram.HandleUpdate(r.Context(), body); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// CreateTelegramLink creates a one-time code the user sends to the Telegram
+// bot to link their account.
+func (s *HTTPServer) CreateTelegramLink(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if !s.telegram.Enabled() {
+		errorJSON(ctx, http.StatusNotFound, "Telegram is not enabled")
+		return
+	}
+
+	c, err := s.telegram.CreateLinkCode(r.Context(), ctx.Param("id"))
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &dto.TelegramLink{
+		Code:      c.Code,
+		Command:   "/start " + c.Code,
+		ExpiresAt: c.ExpiresAt,
+	})
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/InariTheFox/oncall/pkg/web"
+)
+
+// maxTwilioRequestSize limits the body of callbacks sent by Twilio.
+const maxTwilioRequestSize = 1 << 20
+
+// TwilioSMSStatus receives the status of messages sent by the SMS notifier.
+func (s *HTTPServer) TwilioSMSStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	form, ok := s.twilioRequest(ctx, s.twilio.SMSEnabled(), s.twilio.VerifySMS)
+	if !ok {
+		return
+	}
+
+	if err := s.twilio.SMSStatus(r.Context(), form); err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TwilioCallStatus receives the status of calls placed by the phone call
+// notifier.
+func (s *HTTPServer) TwilioCallStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	form, ok := s.twilioRequest(ctx, s.twilio.CallEnabled(), s.twilio.VerifyCall)
+	if !ok {
+		return
+	}
+
+	if err := s.twilio.CallStatus(r.Context(), form); err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TwilioCallGather receives the digit pressed during a call about an alert
+// group, and responds with TwiML.
+func (s *HTTPServer) TwilioCallGather(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	form, ok := s.twilioRequest(ctx, s.twilio.CallEnabled(), s.twilio.VerifyCall)
+	if !ok {
+		return
+	}
+
+	twiml := s.twilio.Gather(r.Context(), ctx.Query("alert_group_id"), ctx.Query("user_id"), form.Get("Digits"))
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(twiml))
+}
+
+// twilioRequest verifies the signature of a callback sent by Twilio and
+// parses its form body.
+func (s *HTTPServer) twilioRequest(ctx *web.Context, enabled bool, verify func(path string, form url.Values, signature string) error) (url.Values, bool) {
+	if !enabled {
+		errorJSON(ctx, http.StatusNotFound, "Notifier is not enabled")
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxTwilioRequestSize))
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+
+	if err := verify(ctx.Request.URL.RequestURI(), form, ctx.Request.Header.Get("X-Twilio-Signature")); err != nil {
+		errorJSON(ctx, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+
+	return form, true
+}
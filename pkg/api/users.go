@@ -0,0 +1,293 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/go-chi/render"
+)
+
+// ListUsers lists users, optionally only the one with the username query
+// parameter.
+func (s *HTTPServer) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	var users []*user.User
+	if username := ctx.Query("username"); username != "" {
+		u, err := s.users.GetByUsername(r.Context(), username)
+		if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+			internalError(ctx, err)
+			return
+		}
+		if u != nil {
+			users = append(users, u)
+		}
+	} else {
+		var err error
+		if users, err = s.users.List(r.Context()); err != nil {
+			internalError(ctx, err)
+			return
+		}
+	}
+
+	result := make([]*dto.User, 0, len(users))
+	for _, u := range users {
+		result = append(result, toUserDTO(u))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	u, err := s.users.Get(r.Context(), ctx.Param("id"))
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserDTO(u))
+}
+
+// GetCurrentUser returns the user signed in to Grafana.
+func (s *HTTPServer) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	u := s.signedInUser(ctx)
+	if u == nil {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserDTO(u))
+}
+
+// CreateUser creates a user. Admins can create any user, while someone
+// signed in to Grafana without a user can only create their own, which is
+// only an admin when it is the first user.
+func (s *HTTPServer) CreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	u, err := decodeUser(r)
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	if u.Role == "" {
+		u.Role = user.RoleViewer
+	}
+
+	if !s.canCreateUser(ctx, u) {
+		return
+	}
+
+	if err := s.users.Create(r.Context(), u); err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toUserDTO(u))
+}
+
+func (s *HTTPServer) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	u, err := decodeUser(r)
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	u.ID = ctx.Param("id")
+
+	existing, err := s.users.Get(r.Context(), u.ID)
+	if err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	if u.Role == "" {
+		u.Role = existing.Role
+	}
+
+	if u.Username == "" {
+		u.Username = existing.Username
+	}
+
+	// selfOrAdmin only lets users who are not admins change themselves, and
+	// they cannot change their role. Neither can they change their username,
+	// which matches them to their Grafana login.
+	if u.Role != existing.Role || u.Username != existing.Username {
+		me := s.signedInUser(ctx)
+		if me == nil {
+			return
+		}
+
+		if me.Role != user.RoleAdmin {
+			errorJSON(ctx, http.StatusForbidden, "Only admins can change roles and usernames")
+			return
+		}
+	}
+
+	if err := s.users.Update(r.Context(), u); err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUserDTO(u))
+}
+
+// DeleteUser deletes a user and takes them out of their teams.
+func (s *HTTPServer) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+	id := ctx.Param("id")
+
+	if err := s.users.Delete(r.Context(), id); err != nil {
+		userError(ctx, err)
+		return
+	}
+
+	if err := s.teams.RemoveUser(r.Context(), id); err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	// The calendar feed of a deleted user has to stop working.
+	if err := s.schedules.RevokeExportToken(r.Context(), "", id); err != nil && !errors.Is(err, schedule.ErrExportTokenNotFound) {
+		internalError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// canCreateUser reports whether the signed in user may create the user,
+// writing an error response when they may not.
+func (s *HTTPServer) canCreateUser(ctx *web.Context, u *user.User) bool {
+	login := ctx.Request.Header.Get(userHeader)
+	if login == "" {
+		errorJSON(ctx, http.StatusUnauthorized, "Not signed in")
+		return false
+	}
+
+	me, err := s.users.GetByUsername(ctx.Request.Context(), login)
+	switch {
+	case err == nil && me.Role == user.RoleAdmin:
+		return true
+	case err == nil:
+		errorJSON(ctx, http.StatusForbidden, "Only admins can create users")
+		return false
+	case !errors.Is(err, user.ErrUserNotFound):
+		internalError(ctx, err)
+		return false
+	}
+
+	if !strings.EqualFold(u.Username, login) {
+		errorJSON(ctx, http.StatusForbidden, "You can only create your own user")
+		return false
+	}
+
+	if u.Role == user.RoleAdmin {
+		users, err := s.users.List(ctx.Request.Context())
+		if err != nil {
+			internalError(ctx, err)
+			return false
+		}
+
+		if len(users) > 0 {
+			errorJSON(ctx, http.StatusForbidden, "Only the first user can make themselves an admin")
+			return false
+		}
+	}
+
+	return true
+}
+
+// userExists writes a not found response and returns false when there is no
+// user with the ID of the request.
+func (s *HTTPServer) userExists(ctx *web.Context) bool {
+	if _, err := s.users.Get(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		userError(ctx, err)
+		return false
+	}
+	return true
+}
+
+func decodeUser(r *http.Request) (*user.User, error) {
+	req := &dto.User{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		return nil, user.ErrInvalidUser
+	}
+
+	u := &user.User{
+		Username: req.Username,
+		Name:     req.Name,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		TimeZone: req.TimeZone,
+		Role:     user.Role(req.Role),
+	}
+
+	if u.TimeZone == "" {
+		u.TimeZone = "UTC"
+	}
+
+	for _, cm := range req.ContactMethods {
+		if cm == nil {
+			continue
+		}
+
+		u.ContactMethods = append(u.ContactMethods, &user.ContactMethod{
+			Type:    cm.Type,
+			Address: cm.Address,
+		})
+	}
+
+	return u, nil
+}
+
+func userError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, user.ErrInvalidUser):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, user.ErrUsernameTaken):
+		errorJSON(ctx, http.StatusConflict, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toUserDTO(u *user.User) *dto.User {
+	result := &dto.User{
+		ID:             u.ID,
+		Username:       u.Username,
+		Name:           u.Name,
+		Email:          u.Email,
+		Phone:          u.Phone,
+		PhoneVerified:  u.PhoneVerified,
+		TimeZone:       u.TimeZone,
+		Role:           string(u.Role),
+		ContactMethods: make([]*dto.ContactMethod, 0, len(u.ContactMethods)),
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+	}
+
+	for _, cm := range u.ContactMethods {
+		result.ContactMethods = append(result.ContactMethods, &dto.ContactMethod{
+			Type:       cm.Type,
+			Address:    cm.Address,
+			Verified:   cm.Verified,
+			VerifiedAt: cm.VerifiedAt,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/InariTheFox/oncall/pkg/api/dto"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/InariTheFox/oncall/pkg/webhook"
+	"github.com/go-chi/render"
+)
+
+func (s *HTTPServer) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	webhooks, err := s.webhooks.ListWebhooks(r.Context())
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.Webhook, 0, len(webhooks))
+	for _, wh := range webhooks {
+		result = append(result, toWebhookDTO(wh))
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (s *HTTPServer) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	wh, err := s.webhooks.GetWebhook(r.Context(), ctx.Param("id"))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toWebhookDTO(wh))
+}
+
+func (s *HTTPServer) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.Webhook{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	wh := fromWebhookDTO(req)
+	if err := s.webhooks.CreateWebhook(r.Context(), wh); err != nil {
+		webhookError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, toWebhookDTO(wh))
+}
+
+func (s *HTTPServer) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	req := &dto.Webhook{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		errorJSON(ctx, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	wh := fromWebhookDTO(req)
+	wh.ID = ctx.Param("id")
+
+	if err := s.webhooks.UpdateWebhook(r.Context(), wh); err != nil {
+		webhookError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toWebhookDTO(wh))
+}
+
+func (s *HTTPServer) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	if err := s.webhooks.DeleteWebhook(r.Context(), ctx.Param("id")); err != nil {
+		webhookError(ctx, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *HTTPServer) GetWebhookResponses(w http.ResponseWriter, r *http.Request) {
+	ctx := web.FromContext(r.Context())
+
+	responses, err := s.webhooks.Responses(r.Context(), ctx.Param("id"))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+
+	result := make([]*dto.WebhookResponse, 0, len(responses))
+	for _, res := range responses {
+		result = append(result, &dto.WebhookResponse{
+			ID:           res.ID,
+			AlertGroupID: res.AlertGroupID,
+			Trigger:      string(res.Trigger),
+			Attempt:      res.Attempt,
+			Method:       res.Method,
+			URL:          res.URL,
+			RequestBody:  res.RequestBody,
+			StatusCode:   res.StatusCode,
+			ResponseBody: res.ResponseBody,
+			Error:        res.Error,
+			CreatedAt:    res.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func webhookError(ctx *web.Context, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrWebhookNotFound):
+		errorJSON(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, webhook.ErrInvalidWebhook):
+		errorJSON(ctx, http.StatusBadRequest, err.Error())
+	default:
+		internalError(ctx, err)
+	}
+}
+
+func toWebhookDTO(wh *webhook.Webhook) *dto.Webhook {
+	result := &dto.Webhook{
+		ID:             wh.ID,
+		Name:           wh.Name,
+		TeamID:         wh.TeamID,
+		Enabled:        &wh.Enabled,
+		URL:            wh.URL,
+		Method:         wh.Method,
+		Headers:        wh.Headers,
+		AuthType:       string(wh.AuthType),
+		Username:       wh.Username,
+		Body:           wh.Body,
+		Triggers:       make([]string, 0, len(wh.Triggers)),
+		IntegrationIDs: wh.IntegrationIDs,
+		CreatedAt:      wh.CreatedAt,
+		UpdatedAt:      wh.UpdatedAt,
+	}
+
+	for _, t := range wh.Triggers {
+		result.Triggers = append(result.Triggers, string(t))
+	}
+
+	return result
+}
+
+func fromWebhookDTO(wh *dto.Webhook) *webhook.Webhook {
+	result := &webhook.Webhook{
+		Name:           wh.Name,
+		TeamID:         wh.TeamID,
+		Enabled:        wh.Enabled == nil || *wh.Enabled,
+		URL:            wh.URL,
+		Method:         wh.Method,
+		Headers:        wh.Headers,
+		AuthType:       webhook.AuthType(wh.AuthType),
+		Username:       wh.Username,
+		Password:       wh.Password,
+		Token:          wh.Token,
+		Body:           wh.Body,
+		Triggers:       make([]webhook.Trigger, 0, len(wh.Triggers)),
+		IntegrationIDs: wh.IntegrationIDs,
+	}
+
+	for _, t := range wh.Triggers {
+		result.Triggers = append(result.Triggers, webhook.Trigger(t))
+	}
+
+	return result
+}
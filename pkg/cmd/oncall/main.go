@@ -51,7 +51,6 @@ func cmdNotFound(c *cli.Context, command string) {
 		"%s: '%s' is not a command. See '%s --help'.\n",
 		c.App.Name,
 		command,
-		c.App.Name,
 		os.Args[0])
 
 	os.Exit(1)
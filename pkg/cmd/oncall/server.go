@@ -5,10 +5,14 @@ import (
 	"time"
 
 	"github.com/InariTheFox/oncall/pkg/api"
+	"github.com/InariTheFox/oncall/pkg/integration"
+	"github.com/InariTheFox/oncall/pkg/notifier/signal"
+	"github.com/InariTheFox/oncall/pkg/schedule"
 	"github.com/InariTheFox/oncall/pkg/server"
 	"github.com/InariTheFox/oncall/pkg/setting"
+	"github.com/InariTheFox/oncall/pkg/shiftnotify"
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
 	"github.com/InariTheFox/oncall/pkg/worker"
-	"github.com/InariTheFox/oncall/pkg/worker/handlers"
 	"github.com/urfave/cli/v2"
 )
 
@@ -23,19 +27,36 @@ func Server(ctx *cli.Context) error {
 		return err
 	}
 
-	api, err := api.New(cfg)
+	db, err := sqlstore.Open(cfg)
 	if err != nil {
 		return err
 	}
+	defer db.Close()
 
 	worker, err := worker.NewRabbitWorker(cfg.RabbitMqHost, cfg.RabbitMqUsername, cfg.RabbitMqPassword, cfg.RabbitMqVhost, cfg.RabbitMqPort, cfg.RabbitMqQueueName, cfg.RabbitMqExchangeName, 5*time.Second)
 	if err != nil {
 		return err
 	}
 
-	worker.RegisterHandler("test", handlers.Handle, nil)
+	svcs, err := newServices(cfg, db, worker)
+	if err != nil {
+		return err
+	}
+
+	registerHandlers(worker, svcs)
+
+	api, err := api.New(cfg, &svcs.Services)
+	if err != nil {
+		return err
+	}
 
-	s, err := server.New(cfg, api, worker)
+	s, err := server.New(cfg, api, worker,
+		integration.NewHeartbeatChecker(svcs.Integrations, cfg.HeartbeatCheckInterval),
+		schedule.NewICalSyncer(svcs.Schedules, cfg.ICalSyncInterval),
+		shiftnotify.NewNotifier(svcs.ShiftNotifications, cfg.ShiftNotificationInterval),
+		schedule.NewGapChecker(svcs.Schedules, worker, cfg.ScheduleGapCheckInterval, time.Duration(cfg.ScheduleGapCheckWeeks)*7*24*time.Hour),
+		signal.NewPoller(svcs.Signal),
+	)
 	if err != nil {
 		return err
 	}
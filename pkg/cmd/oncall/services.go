@@ -0,0 +1,200 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/api"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/integration"
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/email"
+	"github.com/InariTheFox/oncall/pkg/notifier/gotify"
+	"github.com/InariTheFox/oncall/pkg/notifier/mattermost"
+	"github.com/InariTheFox/oncall/pkg/notifier/msteams"
+	"github.com/InariTheFox/oncall/pkg/notifier/ntfy"
+	"github.com/InariTheFox/oncall/pkg/notifier/signal"
+	"github.com/InariTheFox/oncall/pkg/notifier/slack"
+	"github.com/InariTheFox/oncall/pkg/notifier/telegram"
+	"github.com/InariTheFox/oncall/pkg/notifier/twilio"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/setting"
+	"github.com/InariTheFox/oncall/pkg/shiftnotify"
+	"github.com/InariTheFox/oncall/pkg/shiftswap"
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/team"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/webhook"
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/InariTheFox/oncall/pkg/worker/handlers"
+)
+
+// oncallServices holds the services the server and worker commands each
+// build, which see the same data through the shared database.
+type oncallServices struct {
+	api.Services
+
+	Signal  *signal.Service
+	MSTeams *msteams.Service
+}
+
+func newServices(cfg *setting.Cfg, db *sqlstore.DB, w worker.Worker) (*oncallServices, error) {
+	stores, err := newStores(db)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := notifier.NewRegistry()
+	registry.Register(email.Type, email.New(stores.email, filepath.Join(cfg.StaticRootPath, "emails")))
+	registry.Register(slack.Type, slack.New)
+	registry.Register(telegram.Type, telegram.New)
+	registry.Register(signal.Type, signal.New)
+	registry.Register(twilio.SMSType, twilio.NewSMS(cfg.AppURL))
+	registry.Register(twilio.CallType, twilio.NewCall(cfg.AppURL))
+	registry.Register(msteams.Type, msteams.New)
+	registry.Register(mattermost.Type, mattermost.New(cfg.AppURL))
+	registry.Register(ntfy.Type, ntfy.New(stores.ntfy, cfg.AppURL))
+	registry.Register(gotify.Type, gotify.New)
+
+	if err := registry.Configure(cfg.Notifiers); err != nil {
+		return nil, err
+	}
+
+	alertGroups := alertgroup.NewService(stores.alertGroups, w)
+
+	// Phone numbers are verified by SMS when the SMS notifier is enabled.
+	var sms user.SMSSender
+	if n, err := registry.Get(twilio.SMSType); err == nil {
+		sms = n.(*twilio.SMS)
+	}
+
+	users := user.NewService(stores.users, w, sms)
+	teams := team.NewService(stores.teams, users)
+	notifiers := notifier.NewService(stores.notifiers, registry, users, w)
+
+	schedules := schedule.NewService(stores.schedules, users, cfg.OutgoingAllowedNetworks)
+	escalations := escalation.NewService(stores.escalations, alertGroups, w, schedules, teams)
+	integrations := integration.NewService(stores.integrations, alertGroups, escalations, w)
+	notificationPolicies := notificationpolicy.NewService(stores.notificationPolicies, alertGroups, users, schedules, notifiers, w)
+
+	return &oncallServices{
+		Services: api.Services{
+			AlertGroups:  alertGroups,
+			Escalations:  escalations,
+			Integrations: integrations,
+			Schedules:    schedules,
+			ShiftSwaps:   shiftswap.NewService(stores.shiftSwaps, schedules, users, notificationPolicies, notifiers, w),
+
+			ShiftNotifications:   shiftnotify.NewService(stores.shiftNotifications, schedules, users, teams, notificationPolicies, notifiers, cfg.ScheduleChatNotifier, w),
+			NotificationPolicies: notificationPolicies,
+			Users:                users,
+			Teams:                teams,
+			Notifiers:            notifiers,
+			Slack:                slack.NewService(stores.slack, registry, notifiers, alertGroups, escalations, integrations, schedules, users),
+			Telegram:             telegram.NewService(stores.telegram, registry, notifiers, alertGroups, escalations, users),
+			Twilio:               twilio.NewService(registry, notifiers, alertGroups),
+			Email:                email.NewService(stores.email, registry, alertGroups, users),
+			Mattermost:           mattermost.NewService(registry, notifiers, alertGroups, escalations, users),
+			Ntfy:                 ntfy.NewService(stores.ntfy, registry, alertGroups),
+			Webhooks:             webhook.NewService(stores.webhooks, alertGroups, w, cfg.OutgoingAllowedNetworks),
+		},
+		Signal:  signal.NewService(registry, notifiers, alertGroups, users),
+		MSTeams: msteams.NewService(registry, notifiers, alertGroups, users),
+	}, nil
+}
+
+// oncallStores are the stores of the services, which keep their data in
+// the SQL database so the server and workers see the same data.
+type oncallStores struct {
+	alertGroups          *alertgroup.SQLStore
+	users                *user.SQLStore
+	teams                *team.SQLStore
+	notifiers            *notifier.SQLStore
+	schedules            *schedule.SQLStore
+	escalations          *escalation.SQLStore
+	integrations         *integration.SQLStore
+	notificationPolicies *notificationpolicy.SQLStore
+	shiftSwaps           *shiftswap.SQLStore
+	shiftNotifications   *shiftnotify.SQLStore
+	webhooks             *webhook.SQLStore
+	email                *email.SQLStore
+	ntfy                 *ntfy.SQLStore
+	slack                *slack.SQLStore
+	telegram             *telegram.SQLStore
+}
+
+func newStores(db *sqlstore.DB) (*oncallStores, error) {
+	var (
+		s   oncallStores
+		err error
+	)
+
+	if s.alertGroups, err = alertgroup.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.users, err = user.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.teams, err = team.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.notifiers, err = notifier.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.schedules, err = schedule.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.escalations, err = escalation.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.integrations, err = integration.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.notificationPolicies, err = notificationpolicy.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.shiftSwaps, err = shiftswap.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.shiftNotifications, err = shiftnotify.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.webhooks, err = webhook.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.email, err = email.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.ntfy, err = ntfy.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.slack, err = slack.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+	if s.telegram, err = telegram.NewSQLStore(db); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func registerHandlers(w worker.Worker, svcs *oncallServices) {
+	w.RegisterHandler("test", handlers.Handle, nil)
+	w.RegisterHandler(alertgroup.JobSilenceExpired, svcs.AlertGroups.HandleSilenceExpired, nil)
+	w.RegisterHandler(alertgroup.JobCreated, worker.Chain(svcs.Slack.HandleAlertGroupCreated, svcs.Telegram.HandleAlertGroupCreated, svcs.Signal.HandleAlertGroupCreated, svcs.MSTeams.HandleAlertGroupCreated, svcs.Mattermost.HandleAlertGroupCreated, svcs.Webhooks.HandleAlertGroupCreated), nil)
+	w.RegisterHandler(alertgroup.JobAlertAdded, worker.Chain(svcs.Slack.HandleAlertAdded, svcs.Telegram.HandleAlertAdded), nil)
+	w.RegisterHandler(alertgroup.JobStateChanged, worker.Chain(svcs.Escalations.HandleStateChanged, svcs.Slack.HandleStateChanged, svcs.Telegram.HandleStateChanged, svcs.Signal.HandleStateChanged, svcs.MSTeams.HandleStateChanged, svcs.Mattermost.HandleStateChanged, svcs.Webhooks.HandleStateChanged), nil)
+	w.RegisterHandler(escalation.JobStep, svcs.Escalations.HandleStep, nil)
+	w.RegisterHandler(escalation.JobNotifyUser, svcs.NotificationPolicies.HandleNotifyUser, nil)
+	w.RegisterHandler(escalation.JobTriggerWebhook, svcs.Webhooks.HandleTriggerWebhook, nil)
+	w.RegisterHandler(webhook.JobSend, svcs.Webhooks.HandleSend, nil)
+	w.RegisterHandler(notificationpolicy.JobStep, svcs.NotificationPolicies.HandleStep, nil)
+	w.RegisterHandler(integration.JobIngest, svcs.Integrations.HandleIngest, nil)
+	w.RegisterHandler(notifier.JobNotify, svcs.Notifiers.HandleNotify, nil)
+	w.RegisterHandler(shiftnotify.JobNotifyUser, svcs.ShiftNotifications.HandleNotifyUser, nil)
+	w.RegisterHandler(shiftnotify.JobOnCallChanged, svcs.ShiftNotifications.HandleOnCallChanged, nil)
+	w.RegisterHandler(shiftswap.JobNotify, svcs.ShiftSwaps.HandleNotify, nil)
+	w.RegisterHandler(schedule.JobGapsDetected, svcs.ShiftNotifications.HandleGapsDetected, nil)
+	w.RegisterHandler(user.JobSendVerificationCode, svcs.Users.HandleSendVerificationCode, nil)
+}
@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/InariTheFox/oncall/pkg/setting"
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
 	"github.com/InariTheFox/oncall/pkg/worker"
-	"github.com/InariTheFox/oncall/pkg/worker/handlers"
 	"github.com/urfave/cli/v2"
 )
 
+// Worker only handles jobs, alongside the server which handles them as well.
+// It shares the database of the server.
 func Worker(ctx *cli.Context) error {
 	configOptions := strings.Split(ConfigOverrides, " ")
 	cfg, err := setting.NewCfgFromArgs(setting.CommandLineArgs{
@@ -22,14 +24,23 @@ func Worker(ctx *cli.Context) error {
 		return err
 	}
 
+	db, err := sqlstore.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
 	worker, err := worker.NewRabbitWorker(cfg.RabbitMqHost, cfg.RabbitMqUsername, cfg.RabbitMqPassword, cfg.RabbitMqVhost, cfg.RabbitMqPort, cfg.RabbitMqQueueName, cfg.RabbitMqExchangeName, 5*time.Second)
 	if err != nil {
 		return err
 	}
 
-	worker.RegisterHandler("test", handlers.Handle, nil)
+	svcs, err := newServices(cfg, db, worker)
+	if err != nil {
+		return err
+	}
 
-	worker.Run(context.Background())
+	registerHandlers(worker, svcs)
 
-	return nil
+	return worker.Run(context.Background())
 }
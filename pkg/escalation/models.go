@@ -0,0 +1,164 @@
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type StepType string
+
+const (
+	// StepWait delays the next step by Duration.
+	StepWait StepType = "wait"
+	// StepNotifyUsers notifies each of UserIDs.
+	StepNotifyUsers StepType = "notify_persons"
+	// StepNotifyOnCallFromSchedule notifies whoever is on call in ScheduleID.
+	StepNotifyOnCallFromSchedule StepType = "notify_on_call_from_schedule"
+	// StepNotifyUserGroup notifies every member of UserGroupID.
+	StepNotifyUserGroup StepType = "notify_user_group"
+	// StepTriggerWebhook triggers the outgoing webhook WebhookID.
+	StepTriggerWebhook StepType = "trigger_webhook"
+	// StepRepeat restarts the chain from its first step, at most Repeats
+	// times.
+	StepRepeat StepType = "repeat_escalation"
+	// StepTimeWindow only lets the escalation continue when the current time
+	// of day in TimeZone is between FromTime and ToTime.
+	StepTimeWindow StepType = "notify_if_time_from_to"
+	// StepResolve resolves the alert group.
+	StepResolve StepType = "resolve"
+)
+
+// MaxRepeats limits how many times a chain can be restarted by a repeat step.
+const MaxRepeats = 5
+
+var (
+	ErrChainNotFound      = errors.New("escalation chain not found")
+	ErrEscalationNotFound = errors.New("escalation not found")
+	ErrInvalidChain       = errors.New("invalid escalation chain")
+	ErrNotFiring          = errors.New("alert group is not firing")
+)
+
+type Chain struct {
+	ID     string
+	Name   string
+	TeamID string
+	Steps  []*Step
+}
+
+type Step struct {
+	Type StepType
+	// Important selects the important notification policy of the users
+	// notified by the step, rather than the default one.
+	Important   bool
+	Duration    time.Duration
+	UserIDs     []string
+	ScheduleID  string
+	UserGroupID string
+	WebhookID   string
+	Repeats     int
+	// FromTime and ToTime are times of day in TimeZone formatted as 15:04.
+	// A window where ToTime is before FromTime spans midnight.
+	FromTime string
+	ToTime   string
+	// TimeZone is an IANA time zone, UTC when empty.
+	TimeZone string
+}
+
+// Escalation tracks the progress of an alert group through its chain.
+type Escalation struct {
+	AlertGroupID string
+	ChainID      string
+	// StepIndex is the next step to be executed.
+	StepIndex int
+	// Repeats counts how many times the chain has been restarted by a
+	// repeat step.
+	Repeats int
+	Halted  bool
+	// Generation is incremented whenever the escalation is halted or
+	// restarted so that step jobs published before then are ignored.
+	Generation int
+	UpdatedAt  time.Time
+}
+
+func (c *Chain) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("%w: escalation chain name is required", ErrInvalidChain)
+	}
+
+	for i, step := range c.Steps {
+		if err := step.Validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) Validate() error {
+	switch s.Type {
+	case StepWait:
+		if s.Duration <= 0 {
+			return fmt.Errorf("%w: wait duration must be positive", ErrInvalidChain)
+		}
+	case StepNotifyUsers:
+		if len(s.UserIDs) == 0 {
+			return fmt.Errorf("%w: at least one user to notify is required", ErrInvalidChain)
+		}
+	case StepNotifyOnCallFromSchedule:
+		if s.ScheduleID == "" {
+			return fmt.Errorf("%w: schedule is required", ErrInvalidChain)
+		}
+	case StepNotifyUserGroup:
+		if s.UserGroupID == "" {
+			return fmt.Errorf("%w: user group is required", ErrInvalidChain)
+		}
+	case StepTriggerWebhook:
+		if s.WebhookID == "" {
+			return fmt.Errorf("%w: webhook is required", ErrInvalidChain)
+		}
+	case StepRepeat:
+		if s.Repeats < 1 || s.Repeats > MaxRepeats {
+			return fmt.Errorf("%w: repeats must be between 1 and %d", ErrInvalidChain, MaxRepeats)
+		}
+	case StepTimeWindow:
+		if _, err := time.Parse("15:04", s.FromTime); err != nil {
+			return fmt.Errorf("%w: from time must be formatted as HH:MM", ErrInvalidChain)
+		}
+		if _, err := time.Parse("15:04", s.ToTime); err != nil {
+			return fmt.Errorf("%w: to time must be formatted as HH:MM", ErrInvalidChain)
+		}
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return fmt.Errorf("%w: unknown time zone %q", ErrInvalidChain, s.TimeZone)
+		}
+	case StepResolve:
+	default:
+		return fmt.Errorf("%w: unknown step type %q", ErrInvalidChain, s.Type)
+	}
+
+	return nil
+}
+
+// inWindow reports whether the time of day of t in the time zone of the step
+// is within the window of a StepTimeWindow step.
+func (s *Step) inWindow(t time.Time) bool {
+	from, _ := time.Parse("15:04", s.FromTime)
+	to, _ := time.Parse("15:04", s.ToTime)
+
+	// Validated steps have a known time zone, an empty one is UTC.
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+
+	if start <= end {
+		return minute >= start && minute < end
+	}
+
+	return minute >= start || minute < end
+}
@@ -0,0 +1,362 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+const (
+	// JobStep executes a single step of an escalation, with the alert group
+	// ID, the escalation generation and the step index as arguments.
+	JobStep worker.JobType = "escalation_step"
+
+	// JobNotifyUser is published for every user an escalation step notifies,
+	// with the alert group ID, the user ID and the importance as arguments.
+	JobNotifyUser worker.JobType = "escalation_notify_user"
+
+	// JobTriggerWebhook is published by trigger webhook steps, with the
+	// webhook ID and the alert group ID as arguments.
+	JobTriggerWebhook worker.JobType = "escalation_trigger_webhook"
+)
+
+// OnCallResolver finds the users on call in a schedule.
+type OnCallResolver interface {
+	OnCallUserIDs(ctx context.Context, scheduleID string, at time.Time) ([]string, error)
+}
+
+// UserGroupResolver finds the members of a user group.
+type UserGroupResolver interface {
+	UserGroupMemberIDs(ctx context.Context, groupID string) ([]string, error)
+}
+
+type Service struct {
+	mtx         sync.Mutex
+	store       Store
+	alertGroups *alertgroup.Service
+	worker      worker.Worker
+	onCall      OnCallResolver
+	userGroups  UserGroupResolver
+	now         func() time.Time
+}
+
+// NewService creates the escalation service. The on-call and user group
+// resolvers may be nil, in which case steps that need them are skipped.
+func NewService(store Store, alertGroups *alertgroup.Service, w worker.Worker, onCall OnCallResolver, userGroups UserGroupResolver) *Service {
+	return &Service{
+		store:       store,
+		alertGroups: alertGroups,
+		worker:      w,
+		onCall:      onCall,
+		userGroups:  userGroups,
+		now:         time.Now,
+	}
+}
+
+func (s *Service) CreateChain(ctx context.Context, c *Chain) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	c.ID = uuid.NewString()
+
+	return s.store.CreateChain(ctx, c)
+}
+
+func (s *Service) GetChain(ctx context.Context, id string) (*Chain, error) {
+	return s.store.GetChain(ctx, id)
+}
+
+func (s *Service) ListChains(ctx context.Context) ([]*Chain, error) {
+	return s.store.ListChains(ctx)
+}
+
+func (s *Service) UpdateChain(ctx context.Context, c *Chain) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.UpdateChain(ctx, c)
+}
+
+func (s *Service) DeleteChain(ctx context.Context, id string) error {
+	return s.store.DeleteChain(ctx, id)
+}
+
+// Start begins escalating the alert group through the chain.
+func (s *Service) Start(ctx context.Context, alertGroupID, chainID string) error {
+	if _, err := s.store.GetChain(ctx, chainID); err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e := &Escalation{
+		AlertGroupID: alertGroupID,
+		ChainID:      chainID,
+		UpdatedAt:    s.now(),
+	}
+
+	if err := s.store.SaveEscalation(ctx, e); err != nil {
+		return err
+	}
+
+	return s.enqueueStep(ctx, e, 0)
+}
+
+// Escalate runs the next step of the escalation of the alert group right
+// away instead of after the current wait, or restarts the chain from its
+// first step when it has finished.
+func (s *Service) Escalate(ctx context.Context, alertGroupID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, err := s.store.GetEscalation(ctx, alertGroupID)
+	if err != nil {
+		return err
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		return err
+	}
+
+	if !g.IsActive() {
+		return ErrNotFiring
+	}
+
+	if e.Halted {
+		e.StepIndex = 0
+		e.Repeats = 0
+		e.Halted = false
+	}
+
+	e.Generation++
+	e.UpdatedAt = s.now()
+
+	if err := s.store.SaveEscalation(ctx, e); err != nil {
+		return err
+	}
+
+	return s.enqueueStep(ctx, e, 0)
+}
+
+// HandleStep executes the next step of an escalation and schedules the one
+// after it.
+func (s *Service) HandleStep(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 3 {
+		fmt.Printf("Invalid %s job %s, expected 3 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	alertGroupID := job.Args[0]
+	generation, err := strconv.Atoi(job.Args[1])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s, bad generation %q\n", job.Type, job.ID, job.Args[1])
+		return
+	}
+
+	index, err := strconv.Atoi(job.Args[2])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s, bad step index %q\n", job.Type, job.ID, job.Args[2])
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, err := s.store.GetEscalation(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load escalation of alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	// Jobs published before the escalation was halted or restarted are
+	// stale.
+	if e.Halted || e.Generation != generation || e.StepIndex != index {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	if !g.IsActive() {
+		s.halt(ctx, e)
+		return
+	}
+
+	chain, err := s.store.GetChain(ctx, e.ChainID)
+	if errors.Is(err, ErrChainNotFound) || (err == nil && index >= len(chain.Steps)) {
+		s.halt(ctx, e)
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Failed to load escalation chain %s: %s\n", e.ChainID, err)
+		return
+	}
+
+	next, delay, ok := s.execute(ctx, e, g, chain.Steps[index])
+	if !ok {
+		s.halt(ctx, e)
+		return
+	}
+
+	e.StepIndex = next
+	e.UpdatedAt = s.now()
+	if err := s.store.SaveEscalation(ctx, e); err != nil {
+		fmt.Printf("Failed to save escalation of alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	if err := s.enqueueStep(ctx, e, delay); err != nil {
+		fmt.Printf("Failed to schedule escalation step of alert group %s: %s\n", alertGroupID, err)
+	}
+}
+
+// HandleStateChanged halts the escalation of an alert group which is no
+// longer firing, and restarts it from the first step when the alert group
+// fires again.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, err := s.store.GetEscalation(ctx, job.Args[0])
+	if errors.Is(err, ErrEscalationNotFound) {
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Failed to load escalation of alert group %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, e.AlertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", e.AlertGroupID, err)
+		return
+	}
+
+	switch {
+	case !g.IsActive() && !e.Halted:
+		s.halt(ctx, e)
+	case g.IsActive() && e.Halted:
+		e.Generation++
+		e.StepIndex = 0
+		e.Repeats = 0
+		e.Halted = false
+		e.UpdatedAt = s.now()
+
+		if err := s.store.SaveEscalation(ctx, e); err != nil {
+			fmt.Printf("Failed to save escalation of alert group %s: %s\n", e.AlertGroupID, err)
+			return
+		}
+
+		if err := s.enqueueStep(ctx, e, 0); err != nil {
+			fmt.Printf("Failed to restart escalation of alert group %s: %s\n", e.AlertGroupID, err)
+		}
+	}
+}
+
+// execute runs the step, returning the index of the next step and how long
+// to wait before it, or false when the escalation should stop.
+func (s *Service) execute(ctx context.Context, e *Escalation, g *alertgroup.AlertGroup, step *Step) (int, time.Duration, bool) {
+	next := e.StepIndex + 1
+
+	importance := notificationpolicy.ImportanceDefault
+	if step.Important {
+		importance = notificationpolicy.ImportanceImportant
+	}
+
+	switch step.Type {
+	case StepWait:
+		return next, step.Duration, true
+	case StepNotifyUsers:
+		s.notifyUsers(ctx, g.ID, step.UserIDs, importance)
+	case StepNotifyOnCallFromSchedule:
+		if s.onCall == nil {
+			fmt.Printf("Skipping step of alert group %s, schedules are not available\n", g.ID)
+			break
+		}
+
+		users, err := s.onCall.OnCallUserIDs(ctx, step.ScheduleID, s.now())
+		if err != nil {
+			fmt.Printf("Failed to resolve on-call users of schedule %s: %s\n", step.ScheduleID, err)
+			break
+		}
+
+		s.notifyUsers(ctx, g.ID, users, importance)
+	case StepNotifyUserGroup:
+		if s.userGroups == nil {
+			fmt.Printf("Skipping step of alert group %s, user groups are not available\n", g.ID)
+			break
+		}
+
+		users, err := s.userGroups.UserGroupMemberIDs(ctx, step.UserGroupID)
+		if err != nil {
+			fmt.Printf("Failed to resolve members of user group %s: %s\n", step.UserGroupID, err)
+			break
+		}
+
+		s.notifyUsers(ctx, g.ID, users, importance)
+	case StepTriggerWebhook:
+		if err := s.worker.Enqueue(ctx, JobTriggerWebhook, step.WebhookID, g.ID); err != nil {
+			fmt.Printf("Failed to trigger webhook %s for alert group %s: %s\n", step.WebhookID, g.ID, err)
+		}
+	case StepRepeat:
+		if e.Repeats < step.Repeats {
+			e.Repeats++
+			return 0, 0, true
+		}
+	case StepTimeWindow:
+		if !step.inWindow(s.now()) {
+			return 0, 0, false
+		}
+	case StepResolve:
+		if _, err := s.alertGroups.Resolve(ctx, g.ID, alertgroup.SystemActor); err != nil {
+			fmt.Printf("Failed to resolve alert group %s: %s\n", g.ID, err)
+		}
+		return 0, 0, false
+	}
+
+	return next, 0, true
+}
+
+func (s *Service) notifyUsers(ctx context.Context, alertGroupID string, userIDs []string, importance notificationpolicy.Importance) {
+	for _, userID := range userIDs {
+		if err := s.worker.Enqueue(ctx, JobNotifyUser, alertGroupID, userID, string(importance)); err != nil {
+			fmt.Printf("Failed to notify user %s of alert group %s: %s\n", userID, alertGroupID, err)
+		}
+	}
+}
+
+func (s *Service) halt(ctx context.Context, e *Escalation) {
+	e.Halted = true
+	e.Generation++
+	e.UpdatedAt = s.now()
+
+	if err := s.store.SaveEscalation(ctx, e); err != nil {
+		fmt.Printf("Failed to halt escalation of alert group %s: %s\n", e.AlertGroupID, err)
+	}
+}
+
+func (s *Service) enqueueStep(ctx context.Context, e *Escalation, delay time.Duration) error {
+	return s.worker.EnqueueIn(ctx, delay, JobStep, e.AlertGroupID, strconv.Itoa(e.Generation), strconv.Itoa(e.StepIndex))
+}
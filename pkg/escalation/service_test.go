@@ -0,0 +1,317 @@
+package escalation
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// queuedJob is a job published to the queueWorker, with its delay.
+type queuedJob struct {
+	delay time.Duration
+	job   *worker.Job
+}
+
+// queueWorker keeps the jobs published, for tests to handle them in turn.
+type queueWorker struct {
+	jobs []queuedJob
+}
+
+func (w *queueWorker) Enqueue(ctx context.Context, t worker.JobType, args ...string) error {
+	return w.EnqueueIn(ctx, 0, t, args...)
+}
+
+func (w *queueWorker) EnqueueIn(ctx context.Context, delay time.Duration, t worker.JobType, args ...string) error {
+	w.jobs = append(w.jobs, queuedJob{delay: delay, job: &worker.Job{Type: t, Args: args}})
+	return nil
+}
+
+func (w *queueWorker) RegisterHandler(worker.JobType, worker.JobHandler, any) {}
+
+func (w *queueWorker) Stop(ctx context.Context) {}
+
+// take removes the jobs of the type from the queue and returns them.
+func (w *queueWorker) take(t worker.JobType) []queuedJob {
+	var taken, kept []queuedJob
+	for _, j := range w.jobs {
+		if j.job.Type == t {
+			taken = append(taken, j)
+		} else {
+			kept = append(kept, j)
+		}
+	}
+	w.jobs = kept
+
+	return taken
+}
+
+type testEnv struct {
+	s           *Service
+	alertGroups *alertgroup.Service
+	worker      *queueWorker
+	now         *time.Time
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	db := sqlstore.InitTestDB(t)
+	w := &queueWorker{}
+
+	groups, err := alertgroup.NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("alertgroup.NewSQLStore() error = %v", err)
+	}
+	alertGroups := alertgroup.NewService(groups, w)
+
+	store, err := NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+
+	now := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+	s := NewService(store, alertGroups, w, nil, nil)
+	s.now = func() time.Time { return now }
+
+	return &testEnv{s: s, alertGroups: alertGroups, worker: w, now: &now}
+}
+
+// start creates a firing alert group and starts escalating it through a chain
+// with the steps.
+func (env *testEnv) start(t *testing.T, steps ...*Step) *alertgroup.AlertGroup {
+	t.Helper()
+
+	ctx := context.Background()
+
+	c := &Chain{Name: "chain", Steps: steps}
+	if err := env.s.CreateChain(ctx, c); err != nil {
+		t.Fatalf("CreateChain() error = %v", err)
+	}
+
+	g := &alertgroup.AlertGroup{IntegrationID: "i", Title: "disk full"}
+	if err := env.alertGroups.Create(ctx, g); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := env.s.Start(ctx, g.ID, c.ID); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	return g
+}
+
+// run handles the step jobs until there are none left, and returns the users
+// notified in order.
+func (env *testEnv) run(t *testing.T) []string {
+	t.Helper()
+
+	var notified []string
+	for range 100 {
+		jobs := env.worker.take(JobStep)
+		if len(jobs) == 0 {
+			return notified
+		}
+
+		for _, j := range jobs {
+			env.s.HandleStep(context.Background(), j.job)
+		}
+
+		for _, j := range env.worker.take(JobNotifyUser) {
+			notified = append(notified, j.job.Args[1])
+		}
+	}
+
+	t.Fatal("escalation did not finish")
+
+	return nil
+}
+
+func (env *testEnv) escalation(t *testing.T, alertGroupID string) *Escalation {
+	t.Helper()
+
+	e, err := env.s.store.GetEscalation(context.Background(), alertGroupID)
+	if err != nil {
+		t.Fatalf("GetEscalation() error = %v", err)
+	}
+
+	return e
+}
+
+func stepJob(alertGroupID string, generation, index int) *worker.Job {
+	return &worker.Job{Type: JobStep, Args: []string{alertGroupID, strconv.Itoa(generation), strconv.Itoa(index)}}
+}
+
+func TestHandleStepIgnoresStaleJobs(t *testing.T) {
+	tests := []struct {
+		name       string
+		halt       bool
+		generation int
+		index      int
+	}{
+		{name: "earlier generation", generation: -1},
+		{name: "later generation", generation: 1},
+		{name: "other step", index: 1},
+		{name: "halted", halt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			ctx := context.Background()
+
+			g := env.start(t, &Step{Type: StepNotifyUsers, UserIDs: []string{"alice"}})
+			env.worker.jobs = nil
+
+			e := env.escalation(t, g.ID)
+			if tt.halt {
+				env.s.halt(ctx, e)
+			}
+
+			before := *env.escalation(t, g.ID)
+			env.s.HandleStep(ctx, stepJob(g.ID, before.Generation+tt.generation, before.StepIndex+tt.index))
+
+			if len(env.worker.jobs) > 0 {
+				t.Errorf("stale job published %d jobs", len(env.worker.jobs))
+			}
+
+			if after := *env.escalation(t, g.ID); after != before {
+				t.Errorf("stale job changed the escalation from %+v to %+v", before, after)
+			}
+		})
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	tests := []struct {
+		name    string
+		repeats int
+		want    []string
+	}{
+		{name: "once", repeats: 1, want: []string{"alice", "alice"}},
+		{name: "up to the limit", repeats: MaxRepeats, want: slices.Repeat([]string{"alice"}, MaxRepeats+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			g := env.start(t,
+				&Step{Type: StepNotifyUsers, UserIDs: []string{"alice"}},
+				&Step{Type: StepRepeat, Repeats: tt.repeats},
+			)
+
+			if got := env.run(t); !slices.Equal(got, tt.want) {
+				t.Errorf("notified %q, want %q", got, tt.want)
+			}
+
+			e := env.escalation(t, g.ID)
+			if !e.Halted || e.Repeats != tt.repeats {
+				t.Errorf("escalation = %+v, want halted after %d repeats", e, tt.repeats)
+			}
+		})
+	}
+
+	if err := (&Step{Type: StepRepeat, Repeats: MaxRepeats + 1}).Validate(); err == nil {
+		t.Errorf("Validate() of more than %d repeats succeeded", MaxRepeats)
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		from, to string
+		timeZone string
+		want     []string
+	}{
+		{name: "inside", now: utc(10, 0), from: "09:00", to: "17:00", want: []string{"alice"}},
+		{name: "before", now: utc(8, 59), from: "09:00", to: "17:00"},
+		{name: "at the end", now: utc(17, 0), from: "09:00", to: "17:00"},
+		{name: "spanning midnight", now: utc(23, 0), from: "22:00", to: "06:00", want: []string{"alice"}},
+		{name: "outside spanning midnight", now: utc(12, 0), from: "22:00", to: "06:00"},
+		// 08:30 UTC is 09:30 in Paris in January.
+		{name: "inside in time zone", now: utc(8, 30), from: "09:00", to: "17:00", timeZone: "Europe/Paris", want: []string{"alice"}},
+		{name: "outside in time zone", now: utc(16, 30), from: "09:00", to: "17:00", timeZone: "Europe/Paris"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			*env.now = tt.now
+
+			g := env.start(t,
+				&Step{Type: StepTimeWindow, FromTime: tt.from, ToTime: tt.to, TimeZone: tt.timeZone},
+				&Step{Type: StepNotifyUsers, UserIDs: []string{"alice"}},
+			)
+
+			if got := env.run(t); !slices.Equal(got, tt.want) {
+				t.Errorf("notified %q, want %q", got, tt.want)
+			}
+
+			if e := env.escalation(t, g.ID); !e.Halted {
+				t.Errorf("escalation = %+v, want halted", e)
+			}
+		})
+	}
+
+	if err := (&Step{Type: StepTimeWindow, FromTime: "09:00", ToTime: "17:00", TimeZone: "Nowhere/Special"}).Validate(); err == nil {
+		t.Error("Validate() of an unknown time zone succeeded")
+	}
+}
+
+func TestHaltOnAcknowledge(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	g := env.start(t,
+		&Step{Type: StepWait, Duration: time.Minute},
+		&Step{Type: StepNotifyUsers, UserIDs: []string{"alice"}},
+	)
+
+	// The wait step schedules the notify step.
+	for _, j := range env.worker.take(JobStep) {
+		env.s.HandleStep(ctx, j.job)
+	}
+	pending := env.worker.take(JobStep)
+	if len(pending) != 1 || pending[0].delay != time.Minute {
+		t.Fatalf("wait step published %+v, want one step a minute later", pending)
+	}
+
+	if _, err := env.alertGroups.Acknowledge(ctx, g.ID, "bob"); err != nil {
+		t.Fatalf("Acknowledge() error = %v", err)
+	}
+	for _, j := range env.worker.take(alertgroup.JobStateChanged) {
+		env.s.HandleStateChanged(ctx, j.job)
+	}
+
+	if e := env.escalation(t, g.ID); !e.Halted {
+		t.Fatalf("escalation = %+v after acknowledging, want halted", e)
+	}
+
+	// The notify step was published before the escalation halted.
+	env.s.HandleStep(ctx, pending[0].job)
+	if notified := env.worker.take(JobNotifyUser); len(notified) > 0 {
+		t.Errorf("notified %d users after acknowledging", len(notified))
+	}
+
+	if _, err := env.alertGroups.Unacknowledge(ctx, g.ID, "bob"); err != nil {
+		t.Fatalf("Unacknowledge() error = %v", err)
+	}
+	for _, j := range env.worker.take(alertgroup.JobStateChanged) {
+		env.s.HandleStateChanged(ctx, j.job)
+	}
+
+	if got := env.run(t); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("notified %q after unacknowledging, want [alice]", got)
+	}
+}
+
+func utc(h, m int) time.Time {
+	return time.Date(2025, time.January, 6, h, m, 0, 0, time.UTC)
+}
@@ -0,0 +1,89 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps escalation chains and progress in the SQL database, which
+// the server and workers share.
+type SQLStore struct {
+	chains      *sqlstore.Table[Chain]
+	escalations *sqlstore.Table[Escalation]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	chains, err := sqlstore.NewTable(db, "escalation_chains", func(c *Chain) string { return c.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	escalations, err := sqlstore.NewTable(db, "escalations", func(e *Escalation) string { return e.AlertGroupID })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{chains: chains, escalations: escalations}, nil
+}
+
+func (s *SQLStore) CreateChain(ctx context.Context, c *Chain) error {
+	return s.chains.Insert(ctx, c)
+}
+
+func (s *SQLStore) GetChain(ctx context.Context, id string) (*Chain, error) {
+	c, err := s.chains.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrChainNotFound
+	}
+
+	return c, err
+}
+
+func (s *SQLStore) ListChains(ctx context.Context) ([]*Chain, error) {
+	chains, err := s.chains.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].Name < chains[j].Name
+	})
+
+	return chains, nil
+}
+
+func (s *SQLStore) UpdateChain(ctx context.Context, c *Chain) error {
+	err := s.chains.Update(ctx, c)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrChainNotFound
+	}
+
+	return err
+}
+
+func (s *SQLStore) DeleteChain(ctx context.Context, id string) error {
+	err := s.chains.Delete(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrChainNotFound
+	}
+
+	return err
+}
+
+func (s *SQLStore) GetEscalation(ctx context.Context, alertGroupID string) (*Escalation, error) {
+	e, err := s.escalations.Get(ctx, alertGroupID)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrEscalationNotFound
+	}
+
+	return e, err
+}
+
+func (s *SQLStore) SaveEscalation(ctx context.Context, e *Escalation) error {
+	return s.escalations.Save(ctx, e)
+}
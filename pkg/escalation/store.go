@@ -0,0 +1,14 @@
+package escalation
+
+import "context"
+
+type Store interface {
+	CreateChain(ctx context.Context, c *Chain) error
+	GetChain(ctx context.Context, id string) (*Chain, error)
+	ListChains(ctx context.Context) ([]*Chain, error)
+	UpdateChain(ctx context.Context, c *Chain) error
+	DeleteChain(ctx context.Context, id string) error
+
+	GetEscalation(ctx context.Context, alertGroupID string) (*Escalation, error)
+	SaveEscalation(ctx context.Context, e *Escalation) error
+}
@@ -0,0 +1,89 @@
+// Package httpclient builds the HTTP clients of requests sent to URLs set by
+// users, such as webhooks and iCal calendars, which must not reach the
+// internal network of the server.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrAddressNotAllowed = errors.New("address not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, which is as internal as
+// the private ranges.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// New returns a client which refuses to connect to loopback, private,
+// link-local and other internal addresses, unless they are in allowed. The
+// addresses are checked once resolved, when connecting, so host names
+// resolving to internal addresses and redirects to them are refused as well.
+func New(timeout time.Duration, allowed []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			return checkAddress(address, allowed)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxies would connect to the addresses on our behalf, past the check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+
+			return nil
+		},
+	}
+}
+
+func checkAddress(address string, allowed []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+	}
+
+	addr := addrPort.Addr().Unmap()
+	for _, p := range allowed {
+		if p.Contains(addr) {
+			return nil
+		}
+	}
+
+	if Internal(addr) {
+		return fmt.Errorf("%w: %s is an internal address", ErrAddressNotAllowed, addr)
+	}
+
+	return nil
+}
+
+// Internal reports whether the address is one of the server itself or of its
+// internal network.
+func Internal(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestCheckAddress(t *testing.T) {
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.1.2.0/24")}
+
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{address: "93.184.216.34:443"},
+		{address: "[2606:2800:220:1::1]:443"},
+		{address: "127.0.0.1:80", wantErr: true},
+		{address: "[::1]:80", wantErr: true},
+		{address: "10.0.0.1:80", wantErr: true},
+		{address: "172.16.5.4:80", wantErr: true},
+		{address: "192.168.1.1:80", wantErr: true},
+		{address: "169.254.169.254:80", wantErr: true},
+		{address: "[fe80::1]:80", wantErr: true},
+		{address: "[fd00::1]:80", wantErr: true},
+		{address: "0.0.0.0:80", wantErr: true},
+		{address: "100.64.0.1:80", wantErr: true},
+		{address: "[::ffff:127.0.0.1]:80", wantErr: true},
+		{address: "10.1.2.3:80"},
+		{address: "localhost:80", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkAddress(tt.address, allowed)
+			if tt.wantErr {
+				if !errors.Is(err, ErrAddressNotAllowed) {
+					t.Errorf("checkAddress() error = %v, want %v", err, ErrAddressNotAllowed)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("checkAddress() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	// The check happens before connecting, so nothing needs to listen on
+	// the address redirected to.
+	internalRedirect := httptest.NewServer(http.RedirectHandler("http://127.0.0.2:1/", http.StatusFound))
+	defer internalRedirect.Close()
+
+	loopback := []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+
+	tests := []struct {
+		name    string
+		allowed []netip.Prefix
+		url     string
+		wantErr bool
+	}{
+		{name: "loopback", url: target.URL, wantErr: true},
+		{name: "allowed loopback", allowed: loopback, url: target.URL},
+		{name: "redirect to an allowed address", allowed: loopback, url: redirect.URL},
+		{name: "redirect to an internal address", allowed: loopback, url: internalRedirect.URL, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := New(5*time.Second, tt.allowed).Do(req)
+			if tt.wantErr {
+				if !errors.Is(err, ErrAddressNotAllowed) {
+					t.Errorf("Do() error = %v, want %v", err, ErrAddressNotAllowed)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			res.Body.Close()
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// prodID identifies the generator of exported calendars.
+const prodID = "-//OnCall//OnCall//EN"
+
+// maxLineLength is the limit in octets of content lines, longer lines are
+// folded.
+const maxLineLength = 75
+
+// Calendar is a calendar to be exported.
+type Calendar struct {
+	Name   string
+	Events []*Event
+}
+
+// Encode writes the calendar as RFC 5545 data. Times are written in UTC.
+func Encode(w io.Writer, cal *Calendar) error {
+	bw := bufio.NewWriter(w)
+	stamp := formatTime(time.Now())
+
+	write := func(line string) {
+		writeFolded(bw, line)
+	}
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:" + prodID)
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	if cal.Name != "" {
+		write("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+
+	for _, e := range cal.Events {
+		write("BEGIN:VEVENT")
+		write("UID:" + e.UID)
+		write("DTSTAMP:" + stamp)
+		if e.AllDay {
+			write("DTSTART;VALUE=DATE:" + e.Start.Format("20060102"))
+			write("DTEND;VALUE=DATE:" + e.End.Format("20060102"))
+		} else {
+			write("DTSTART:" + formatTime(e.Start))
+			write("DTEND:" + formatTime(e.End))
+		}
+		if e.Rule != nil {
+			write("RRULE:" + e.Rule.String())
+		}
+		for _, ex := range e.ExDates {
+			write("EXDATE:" + formatTime(ex))
+		}
+		write("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			write("DESCRIPTION:" + escapeText(e.Description))
+		}
+		write("END:VEVENT")
+	}
+
+	write("END:VCALENDAR")
+
+	return bw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeFolded writes a content line, folding it onto continuation lines
+// without splitting multi-byte characters.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with a space which counts to the limit.
+		limit = maxLineLength - 1
+	}
+
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,259 @@
+package ical
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event is a VEVENT, optionally repeating according to a recurrence rule.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Rule         *Rule
+	ExDates      []time.Time
+	RecurrenceID *time.Time
+	Cancelled    bool
+}
+
+// Occurrence is a single instance of an event.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+func newEvent(props []*property, fallback *time.Location) (*Event, error) {
+	e := &Event{}
+
+	var (
+		duration    time.Duration
+		hasDuration bool
+		hasEnd      bool
+		hasStart    bool
+	)
+
+	for _, p := range props {
+		switch p.Name {
+		case "UID":
+			e.UID = p.Value
+		case "SUMMARY":
+			e.Summary = unescapeText(p.Value)
+		case "DESCRIPTION":
+			e.Description = unescapeText(p.Value)
+		case "STATUS":
+			e.Cancelled = strings.EqualFold(p.Value, "CANCELLED")
+		case "DTSTART":
+			t, allDay, err := parseTime(p.Value, p.Params, fallback)
+			if err != nil {
+				return nil, eventError(e, "invalid DTSTART %q", p.Value)
+			}
+			e.Start, e.AllDay, hasStart = t, allDay, true
+		case "DTEND":
+			t, _, err := parseTime(p.Value, p.Params, fallback)
+			if err != nil {
+				return nil, eventError(e, "invalid DTEND %q", p.Value)
+			}
+			e.End, hasEnd = t, true
+		case "DURATION":
+			d, err := parseDuration(p.Value)
+			if err != nil {
+				return nil, eventError(e, "%s", err)
+			}
+			duration, hasDuration = d, true
+		case "RRULE":
+			rule, err := parseRule(p.Value, fallback)
+			if err != nil {
+				return nil, eventError(e, "%s", err)
+			}
+			e.Rule = rule
+		case "EXDATE":
+			for _, v := range strings.Split(p.Value, ",") {
+				t, _, err := parseTime(v, p.Params, fallback)
+				if err != nil {
+					return nil, eventError(e, "invalid EXDATE %q", v)
+				}
+				e.ExDates = append(e.ExDates, t)
+			}
+		case "RECURRENCE-ID":
+			t, _, err := parseTime(p.Value, p.Params, fallback)
+			if err != nil {
+				return nil, eventError(e, "invalid RECURRENCE-ID %q", p.Value)
+			}
+			e.RecurrenceID = &t
+		}
+	}
+
+	if !hasStart {
+		return nil, eventError(e, "missing DTSTART")
+	}
+
+	switch {
+	case hasEnd:
+	case hasDuration:
+		e.End = e.Start.Add(duration)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+
+	if !e.End.After(e.Start) && !e.Cancelled {
+		return nil, eventError(e, "event must end after it starts")
+	}
+
+	return e, nil
+}
+
+func eventError(e *Event, format string, args ...any) error {
+	name := e.UID
+	if e.Summary != "" {
+		name = e.Summary
+	}
+	if name == "" {
+		name = "unnamed event"
+	}
+	return fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...))
+}
+
+// applyRecurrenceIDs handles modified instances of repeating events. The
+// instance is removed from its series and kept as a standalone event unless
+// it was cancelled.
+func applyRecurrenceIDs(events []*Event) []*Event {
+	series := make(map[string]*Event)
+	for _, e := range events {
+		if e.RecurrenceID == nil && e.Rule != nil && e.UID != "" {
+			series[e.UID] = e
+		}
+	}
+
+	result := make([]*Event, 0, len(events))
+	for _, e := range events {
+		if e.RecurrenceID != nil {
+			if master, ok := series[e.UID]; ok {
+				master.ExDates = append(master.ExDates, *e.RecurrenceID)
+			}
+		}
+
+		if e.Cancelled {
+			continue
+		}
+
+		result = append(result, e)
+	}
+
+	return result
+}
+
+// Occurrences returns the instances of the event which overlap the given
+// range, in chronological order.
+func (e *Event) Occurrences(from, to time.Time) []Occurrence {
+	length := e.End.Sub(e.Start)
+
+	var starts []time.Time
+	if e.Rule == nil {
+		starts = []time.Time{e.Start}
+	} else {
+		starts = e.Rule.expand(e.Start, from.Add(-length), to)
+	}
+
+	var occurrences []Occurrence
+	for _, start := range starts {
+		if e.excluded(start) {
+			continue
+		}
+
+		end := start.Add(length)
+		if !end.After(from) || !start.Before(to) {
+			continue
+		}
+
+		occurrences = append(occurrences, Occurrence{Start: start, End: end})
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+
+	return occurrences
+}
+
+func (e *Event) excluded(start time.Time) bool {
+	for _, ex := range e.ExDates {
+		if ex.Equal(start) {
+			return true
+		}
+
+		// Excluded dates of all-day events match the whole day.
+		if e.AllDay && sameDay(ex, start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// eventJSON is an event as encoded in JSON, along with the time zone of its
+// start which JSON times only keep the offset of.
+type eventJSON struct {
+	*jsonEvent
+	TimeZone string `json:",omitempty"`
+}
+
+type jsonEvent Event
+
+// MarshalJSON keeps the time zone of the event, as recurrences are expanded
+// in it.
+func (e *Event) MarshalJSON() ([]byte, error) {
+	v := eventJSON{jsonEvent: (*jsonEvent)(e)}
+	if name := e.Start.Location().String(); name != "" && name != "Local" {
+		v.TimeZone = name
+	}
+
+	return json.Marshal(v)
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	v := eventJSON{jsonEvent: (*jsonEvent)(e)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.TimeZone == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(v.TimeZone)
+	if err != nil {
+		// The offsets of the times are still right, only recurrences across
+		// DST changes may be off.
+		return nil
+	}
+
+	e.Start = e.Start.In(loc)
+	e.End = e.End.In(loc)
+	for i, t := range e.ExDates {
+		e.ExDates[i] = t.In(loc)
+	}
+	if e.RecurrenceID != nil {
+		t := e.RecurrenceID.In(loc)
+		e.RecurrenceID = &t
+	}
+	if e.Rule != nil && e.Rule.Until != nil {
+		t := e.Rule.Until.In(loc)
+		e.Rule.Until = &t
+	}
+
+	return nil
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,262 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid iCalendar data")
+
+// property is a single content line, such as DTSTART;TZID=UTC:20250101T090000.
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Parse reads the events of an RFC 5545 calendar. Times without a time zone
+// are read in the fallback location. Events which cannot be understood are
+// skipped and reported in the returned error alongside the events that
+// could be read.
+func Parse(r io.Reader, fallback *time.Location) ([]*Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []*Event
+		problems []string
+		current  []*property
+		depth    int
+		inEvent  bool
+		found    bool
+	)
+
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidCalendar, n+1, err)
+		}
+
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VCALENDAR"):
+			found = true
+		case p.Name == "BEGIN":
+			depth++
+			if depth == 1 && strings.EqualFold(p.Value, "VEVENT") {
+				inEvent = true
+				current = nil
+			}
+		case p.Name == "END" && strings.EqualFold(p.Value, "VCALENDAR"):
+		case p.Name == "END":
+			depth--
+			if depth == 0 && inEvent {
+				inEvent = false
+				e, err := newEvent(current, fallback)
+				if err != nil {
+					problems = append(problems, err.Error())
+					continue
+				}
+				if e != nil {
+					events = append(events, e)
+				}
+			}
+		case inEvent && depth == 1:
+			current = append(current, p)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR", ErrInvalidCalendar)
+	}
+
+	events = applyRecurrenceIDs(events)
+
+	if len(problems) > 0 {
+		return events, fmt.Errorf("%w: %s", ErrInvalidCalendar, strings.Join(problems, "; "))
+	}
+
+	return events, nil
+}
+
+// unfold joins folded content lines, which continue on the next line after a
+// leading space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCalendar, err)
+	}
+
+	return lines, nil
+}
+
+func parseProperty(line string) (*property, error) {
+	// The value starts at the first colon outside of a quoted parameter.
+	inQuotes := false
+	split := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			split = i
+			break
+		}
+	}
+
+	if split < 0 {
+		return nil, fmt.Errorf("content line %q has no value", line)
+	}
+
+	parts := splitQuoted(line[:split], ';')
+	p := &property{
+		Name:   strings.ToUpper(parts[0]),
+		Params: make(map[string]string),
+		Value:  line[split+1:],
+	}
+
+	for _, param := range parts[1:] {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return p, nil
+}
+
+func splitQuoted(s string, sep rune) []string {
+	var (
+		parts    []string
+		buf      bytes.Buffer
+		inQuotes bool
+	)
+
+	for _, c := range s {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			buf.WriteRune(c)
+		case c == sep && !inQuotes:
+			parts = append(parts, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteRune(c)
+		}
+	}
+
+	return append(parts, buf.String())
+}
+
+// unescapeText reverses the escaping of TEXT values.
+func unescapeText(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
+
+// parseTime reads a DATE or DATE-TIME value. Dates are returned as midnight
+// in the location.
+func parseTime(value string, params map[string]string, fallback *time.Location) (time.Time, bool, error) {
+	loc := fallback
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration reads a DURATION value such as P1D or PT8H30M.
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	}
+	s = strings.TrimPrefix(s, "+")
+
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var (
+		d      time.Duration
+		inTime bool
+		num    int
+		digits bool
+	)
+
+	for _, c := range s {
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			num = num*10 + int(c-'0')
+			digits = true
+		default:
+			if !digits {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+
+			switch {
+			case c == 'W' && !inTime:
+				d += time.Duration(num) * 7 * 24 * time.Hour
+			case c == 'D' && !inTime:
+				d += time.Duration(num) * 24 * time.Hour
+			case c == 'H' && inTime:
+				d += time.Duration(num) * time.Hour
+			case c == 'M' && inTime:
+				d += time.Duration(num) * time.Minute
+			case c == 'S' && inTime:
+				d += time.Duration(num) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+
+			num = 0
+			digits = false
+		}
+	}
+
+	if neg {
+		d = -d
+	}
+
+	return d, nil
+}
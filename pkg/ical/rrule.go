@@ -0,0 +1,354 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	FrequencyHourly  Frequency = "HOURLY"
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the expansion of a single rule.
+const maxPeriods = 100000
+
+// Rule is the supported subset of an RRULE: FREQ, INTERVAL, COUNT, UNTIL,
+// BYDAY and BYMONTHDAY.
+type Rule struct {
+	Frequency  Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// WeekdayNum is a BYDAY entry such as MO or, for monthly rules, -1FR.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// weekdayCodes are the two letter codes of the days of the week, indexed by
+// time.Weekday.
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayCode returns the two letter code of the weekday.
+func WeekdayCode(d time.Weekday) string {
+	return weekdayCodes[d]
+}
+
+// ParseWeekday parses the two letter code of a weekday such as MO, ignoring
+// case.
+func ParseWeekday(s string) (time.Weekday, error) {
+	for i, code := range weekdayCodes {
+		if strings.EqualFold(s, code) {
+			return time.Weekday(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+func parseRule(value string, fallback *time.Location) (*Rule, error) {
+	r := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.Frequency = Frequency(strings.ToUpper(v))
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid RRULE interval %q", v)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid RRULE count %q", v)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, _, err := parseTime(v, nil, fallback)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE until %q", v)
+			}
+			r.Until = &t
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(v, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid RRULE month day %q", d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			// Weeks always start on Monday.
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", k)
+		}
+	}
+
+	switch r.Frequency {
+	case FrequencyHourly, FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	case "":
+		return nil, fmt.Errorf("RRULE is missing FREQ")
+	default:
+		return nil, fmt.Errorf("unsupported RRULE frequency %s", r.Frequency)
+	}
+
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Frequency != FrequencyMonthly {
+			return nil, fmt.Errorf("numbered BYDAY is only supported for monthly rules")
+		}
+	}
+
+	return r, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid RRULE weekday %q", s)
+	}
+
+	wd, err := ParseWeekday(s[len(s)-2:])
+	if err != nil {
+		return WeekdayNum{}, fmt.Errorf("invalid RRULE weekday %q", s)
+	}
+
+	n := 0
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid RRULE weekday %q", s)
+		}
+	}
+
+	return WeekdayNum{Weekday: wd, N: n}, nil
+}
+
+// String renders the rule as an RRULE value.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = WeekdayCode(d.Weekday)
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// expand returns the start times generated by the rule for an event starting
+// at dtstart, from the first instance starting at or after from until to.
+func (r *Rule) expand(dtstart, from, to time.Time) []time.Time {
+	first := 0
+	if r.Count == 0 && from.After(dtstart) {
+		// Without a count earlier periods can be skipped outright.
+		first = max(r.periodsBetween(dtstart, from)-1, 0)
+	}
+
+	var (
+		starts []time.Time
+		count  int
+	)
+
+	for k := first; k < first+maxPeriods; k++ {
+		candidates := r.period(dtstart, k)
+		for _, c := range candidates {
+			if c.Before(dtstart) {
+				continue
+			}
+
+			if !c.Before(to) || (r.Until != nil && c.After(*r.Until)) {
+				return starts
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return starts
+			}
+
+			if !c.Before(from) {
+				starts = append(starts, c)
+			}
+		}
+	}
+
+	return starts
+}
+
+func (r *Rule) periodsBetween(a, b time.Time) int {
+	d := b.Sub(a)
+
+	switch r.Frequency {
+	case FrequencyHourly:
+		return int(d / time.Hour / time.Duration(r.Interval))
+	case FrequencyDaily:
+		return int(d / (24 * time.Hour) / time.Duration(r.Interval))
+	case FrequencyWeekly:
+		return int(d / (7 * 24 * time.Hour) / time.Duration(r.Interval))
+	case FrequencyMonthly:
+		months := (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+		return months / r.Interval
+	default:
+		return (b.Year() - a.Year()) / r.Interval
+	}
+}
+
+// period returns the candidate start times of the k-th period of the rule in
+// chronological order.
+func (r *Rule) period(dtstart time.Time, k int) []time.Time {
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	var candidates []time.Time
+
+	switch r.Frequency {
+	case FrequencyHourly:
+		return []time.Time{dtstart.Add(time.Duration(k*r.Interval) * time.Hour)}
+	case FrequencyDaily:
+		c := at(y, m, d+k*r.Interval)
+		if len(r.ByDay) == 0 || r.hasWeekday(c.Weekday()) {
+			candidates = append(candidates, c)
+		}
+	case FrequencyWeekly:
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := at(y, m, d-offset+7*k*r.Interval)
+		if len(r.ByDay) == 0 {
+			candidates = append(candidates, monday.AddDate(0, 0, offset))
+			break
+		}
+		for _, wd := range r.ByDay {
+			candidates = append(candidates, monday.AddDate(0, 0, (int(wd.Weekday)+6)%7))
+		}
+	case FrequencyMonthly:
+		first := at(y, m+time.Month(k*r.Interval), 1)
+		candidates = r.monthDays(first, d)
+	case FrequencyYearly:
+		c := at(y+k*r.Interval, m, d)
+		if c.Month() == m {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+
+	return candidates
+}
+
+// monthDays returns the instances within the month starting at first.
+func (r *Rule) monthDays(first time.Time, day int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = last + 1 + d
+			}
+			if d >= 1 && d <= last && r.matchesByDay(first, d, last) {
+				days = append(days, d)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for d := 1; d <= last; d++ {
+			if r.matchesByDay(first, d, last) {
+				days = append(days, d)
+			}
+		}
+	case day <= last:
+		days = append(days, day)
+	}
+
+	candidates := make([]time.Time, 0, len(days))
+	for _, d := range days {
+		candidates = append(candidates, first.AddDate(0, 0, d-1))
+	}
+
+	return candidates
+}
+
+func (r *Rule) matchesByDay(first time.Time, d, last int) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+
+	wd := first.AddDate(0, 0, d-1).Weekday()
+	for _, b := range r.ByDay {
+		if b.Weekday != wd {
+			continue
+		}
+
+		switch {
+		case b.N == 0:
+			return true
+		case b.N > 0 && (d-1)/7+1 == b.N:
+			return true
+		case b.N < 0 && (last-d)/7+1 == -b.N:
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) hasWeekday(d time.Weekday) bool {
+	for _, b := range r.ByDay {
+		if b.Weekday == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,361 @@
+package ical
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// calendar wraps the content lines of events in a calendar.
+func calendar(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+}
+
+func mustParse(t *testing.T, data string) []*Event {
+	t.Helper()
+
+	events, err := Parse(strings.NewReader(data), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	return events
+}
+
+// starts formats the starts of the occurrences of the events in the range,
+// in the zone of each event.
+func starts(events []*Event, from, to time.Time) []string {
+	var result []string
+	for _, e := range events {
+		for _, o := range e.Occurrences(from, to) {
+			result = append(result, o.Start.Format("2006-01-02 15:04 MST"))
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRuleExpansion(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		rule     string
+		extra    []string
+		from, to time.Time
+		want     []string
+	}{
+		{
+			name:  "daily count",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=DAILY;COUNT=3",
+			from:  date(2025, 1, 1),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-06 09:00 UTC", "2025-01-07 09:00 UTC", "2025-01-08 09:00 UTC"},
+		},
+		{
+			name:  "count includes instances before the range",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=DAILY;COUNT=3",
+			from:  date(2025, 1, 8),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-08 09:00 UTC"},
+		},
+		{
+			name:  "interval",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			from:  date(2025, 1, 6),
+			to:    date(2025, 1, 16),
+			want:  []string{"2025-01-06 09:00 UTC", "2025-01-09 09:00 UTC", "2025-01-12 09:00 UTC", "2025-01-15 09:00 UTC"},
+		},
+		{
+			name:  "interval skipping periods before the range",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			from:  date(2025, 3, 1),
+			to:    date(2025, 3, 31),
+			want:  []string{"2025-03-03 09:00 UTC", "2025-03-17 09:00 UTC"},
+		},
+		{
+			name:  "until on an instance includes it",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=DAILY;UNTIL=20250108T090000Z",
+			from:  date(2025, 1, 1),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-06 09:00 UTC", "2025-01-07 09:00 UTC", "2025-01-08 09:00 UTC"},
+		},
+		{
+			name:  "until just before an instance excludes it",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=DAILY;UNTIL=20250108T085959Z",
+			from:  date(2025, 1, 1),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-06 09:00 UTC", "2025-01-07 09:00 UTC"},
+		},
+		{
+			name:  "until as a date",
+			start: "DTSTART;VALUE=DATE:20250106",
+			rule:  "FREQ=DAILY;UNTIL=20250108",
+			from:  date(2025, 1, 1),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-06 00:00 UTC", "2025-01-07 00:00 UTC", "2025-01-08 00:00 UTC"},
+		},
+		{
+			name:  "weekly by day",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			from:  date(2025, 1, 6),
+			to:    date(2025, 1, 13),
+			want:  []string{"2025-01-06 09:00 UTC", "2025-01-08 09:00 UTC", "2025-01-10 09:00 UTC"},
+		},
+		{
+			name:  "weekly by day before the start in the first week",
+			start: "DTSTART:20250108T090000Z",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE",
+			from:  date(2025, 1, 1),
+			to:    date(2025, 1, 16),
+			want:  []string{"2025-01-08 09:00 UTC", "2025-01-13 09:00 UTC", "2025-01-15 09:00 UTC"},
+		},
+		{
+			name:  "daily by day skips other days",
+			start: "DTSTART:20250110T090000Z",
+			rule:  "FREQ=DAILY;BYDAY=SA,SU",
+			from:  date(2025, 1, 10),
+			to:    date(2025, 1, 20),
+			want:  []string{"2025-01-11 09:00 UTC", "2025-01-12 09:00 UTC", "2025-01-18 09:00 UTC", "2025-01-19 09:00 UTC"},
+		},
+		{
+			name:  "monthly last friday",
+			start: "DTSTART:20250131T090000Z",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			from:  date(2025, 1, 1),
+			to:    date(2025, 4, 1),
+			want:  []string{"2025-01-31 09:00 UTC", "2025-02-28 09:00 UTC", "2025-03-28 09:00 UTC"},
+		},
+		{
+			name:  "monthly second tuesday",
+			start: "DTSTART:20250114T090000Z",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			from:  date(2025, 1, 1),
+			to:    date(2026, 1, 1),
+			want:  []string{"2025-01-14 09:00 UTC", "2025-02-11 09:00 UTC", "2025-03-11 09:00 UTC"},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			start: "DTSTART:20250131T090000Z",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			from:  date(2025, 1, 1),
+			to:    date(2026, 1, 1),
+			want:  []string{"2025-01-31 09:00 UTC", "2025-03-31 09:00 UTC", "2025-05-31 09:00 UTC"},
+		},
+		{
+			name:  "monthly on the last day",
+			start: "DTSTART:20250131T090000Z",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			from:  date(2025, 1, 1),
+			to:    date(2026, 1, 1),
+			want:  []string{"2025-01-31 09:00 UTC", "2025-02-28 09:00 UTC", "2025-03-31 09:00 UTC"},
+		},
+		{
+			name:  "yearly on leap day",
+			start: "DTSTART:20240229T090000Z",
+			rule:  "FREQ=YEARLY;COUNT=2",
+			from:  date(2024, 1, 1),
+			to:    date(2030, 1, 1),
+			want:  []string{"2024-02-29 09:00 UTC", "2028-02-29 09:00 UTC"},
+		},
+		{
+			name:  "daily keeps the local time across DST",
+			start: "DTSTART;TZID=Europe/Berlin:20250328T090000",
+			rule:  "FREQ=DAILY",
+			from:  date(2025, 3, 28),
+			to:    date(2025, 3, 31),
+			want:  []string{"2025-03-28 09:00 CET", "2025-03-29 09:00 CET", "2025-03-30 09:00 CEST"},
+		},
+		{
+			name:  "weekly keeps the local time across DST",
+			start: "DTSTART;TZID=America/New_York:20251027T090000",
+			rule:  "FREQ=WEEKLY",
+			from:  date(2025, 10, 27),
+			to:    date(2025, 11, 11),
+			want:  []string{"2025-10-27 09:00 EDT", "2025-11-03 09:00 EST", "2025-11-10 09:00 EST"},
+		},
+		{
+			name:  "hourly counts elapsed hours across DST",
+			start: "DTSTART;TZID=Europe/Berlin:20250330T010000",
+			rule:  "FREQ=HOURLY;COUNT=3",
+			from:  date(2025, 3, 29),
+			to:    date(2025, 3, 31),
+			want:  []string{"2025-03-30 01:00 CET", "2025-03-30 03:00 CEST", "2025-03-30 04:00 CEST"},
+		},
+		{
+			name:  "excluded dates",
+			start: "DTSTART:20250106T090000Z",
+			rule:  "FREQ=DAILY;COUNT=3",
+			extra: []string{"EXDATE:20250107T090000Z"},
+			from:  date(2025, 1, 1),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-06 09:00 UTC", "2025-01-08 09:00 UTC"},
+		},
+		{
+			name:  "occurrence overlapping the start of the range",
+			start: "DTSTART:20250106T220000Z",
+			rule:  "FREQ=DAILY;COUNT=2",
+			from:  time.Date(2025, 1, 7, 6, 0, 0, 0, time.UTC),
+			to:    date(2025, 2, 1),
+			want:  []string{"2025-01-06 22:00 UTC", "2025-01-07 22:00 UTC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := []string{"BEGIN:VEVENT", "UID:1", tt.start, "DURATION:PT9H", "RRULE:" + tt.rule}
+			lines = append(lines, tt.extra...)
+			lines = append(lines, "END:VEVENT")
+
+			got := starts(mustParse(t, calendar(lines...)), tt.from, tt.to)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{name: "missing frequency", rule: "COUNT=3"},
+		{name: "unsupported frequency", rule: "FREQ=SECONDLY"},
+		{name: "zero interval", rule: "FREQ=DAILY;INTERVAL=0"},
+		{name: "negative count", rule: "FREQ=DAILY;COUNT=-1"},
+		{name: "unknown weekday", rule: "FREQ=WEEKLY;BYDAY=XX"},
+		{name: "numbered weekday in a weekly rule", rule: "FREQ=WEEKLY;BYDAY=1MO"},
+		{name: "month day out of range", rule: "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYHOUR=9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseRule(tt.rule, time.UTC); err == nil {
+				t.Errorf("parseRule(%q) succeeded, want an error", tt.rule)
+			}
+		})
+	}
+}
+
+func TestRecurrenceID(t *testing.T) {
+	series := []string{
+		"BEGIN:VEVENT",
+		"UID:series",
+		"SUMMARY:alice",
+		"DTSTART:20250106T090000Z",
+		"DTEND:20250106T170000Z",
+		"RRULE:FREQ=DAILY;COUNT=3",
+		"END:VEVENT",
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name: "moved instance",
+			lines: []string{
+				"BEGIN:VEVENT",
+				"UID:series",
+				"SUMMARY:bob",
+				"RECURRENCE-ID:20250107T090000Z",
+				"DTSTART:20250107T120000Z",
+				"DTEND:20250107T180000Z",
+				"END:VEVENT",
+			},
+			want: []string{"2025-01-06 09:00 UTC", "2025-01-07 12:00 UTC", "2025-01-08 09:00 UTC"},
+		},
+		{
+			name: "cancelled instance",
+			lines: []string{
+				"BEGIN:VEVENT",
+				"UID:series",
+				"RECURRENCE-ID:20250107T090000Z",
+				"DTSTART:20250107T090000Z",
+				"STATUS:CANCELLED",
+				"END:VEVENT",
+			},
+			want: []string{"2025-01-06 09:00 UTC", "2025-01-08 09:00 UTC"},
+		},
+		{
+			name: "instance of another series",
+			lines: []string{
+				"BEGIN:VEVENT",
+				"UID:other",
+				"RECURRENCE-ID:20250107T090000Z",
+				"DTSTART:20250107T120000Z",
+				"DTEND:20250107T130000Z",
+				"END:VEVENT",
+			},
+			want: []string{"2025-01-06 09:00 UTC", "2025-01-07 09:00 UTC", "2025-01-07 12:00 UTC", "2025-01-08 09:00 UTC"},
+		},
+		{
+			name: "instance in another time zone",
+			lines: []string{
+				"BEGIN:VEVENT",
+				"UID:series",
+				"RECURRENCE-ID;TZID=Europe/Berlin:20250108T100000",
+				"DTSTART:20250108T150000Z",
+				"DTEND:20250108T160000Z",
+				"END:VEVENT",
+			},
+			want: []string{"2025-01-06 09:00 UTC", "2025-01-07 09:00 UTC", "2025-01-08 15:00 UTC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The modified instance comes first, as calendars do not have
+			// to list the series before its instances.
+			events := mustParse(t, calendar(append(tt.lines, series...)...))
+
+			got := starts(events, date(2025, 1, 1), date(2025, 2, 1))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventJSONKeepsTimeZone(t *testing.T) {
+	events := mustParse(t, calendar(
+		"BEGIN:VEVENT",
+		"UID:1",
+		"DTSTART;TZID=Europe/Berlin:20250328T090000",
+		"DURATION:PT8H",
+		"RRULE:FREQ=DAILY",
+		"END:VEVENT",
+	))
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded []*Event
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	from, to := date(2025, 3, 28), date(2025, 3, 31)
+	if got, want := starts(decoded, from, to), starts(events, from, to); !slices.Equal(got, want) {
+		t.Errorf("occurrences after decoding = %q, want %q", got, want)
+	}
+}
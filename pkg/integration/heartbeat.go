@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MinHeartbeatTimeout is the shortest heartbeat timeout an integration can
+// be configured with.
+const MinHeartbeatTimeout = time.Minute
+
+// heartbeatGroupingKey groups the heartbeat alerts of an integration into a
+// single alert group.
+const heartbeatGroupingKey = "heartbeat"
+
+// Heartbeat records a heartbeat for the integration, resolving the heartbeat
+// alert group if the heartbeat had expired.
+func (s *Service) Heartbeat(ctx context.Context, t Type, token string) error {
+	i, err := s.Authenticate(ctx, t, token)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Reload under the lock so a concurrent check does not overwrite the
+	// heartbeat.
+	i, err = s.store.GetIntegration(ctx, i.ID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	expired := i.HeartbeatExpired
+
+	i.LastHeartbeatAt = &now
+	i.HeartbeatExpired = false
+
+	if err := s.store.UpdateIntegration(ctx, i); err != nil {
+		return err
+	}
+
+	if !expired {
+		return nil
+	}
+
+	return s.ingest(ctx, i, &Alert{
+		Title:       fmt.Sprintf("Heartbeat of %s restored", i.Name),
+		Labels:      map[string]string{"alertname": "Heartbeat"},
+		GroupingKey: heartbeatGroupingKey,
+		Resolved:    true,
+	})
+}
+
+// CheckHeartbeats raises an alert for every integration whose heartbeat has
+// not been received within its timeout. Integrations only start being
+// checked once they have received their first heartbeat.
+func (s *Service) CheckHeartbeats(ctx context.Context) error {
+	integrations, err := s.store.ListIntegrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range integrations {
+		if i.HeartbeatTimeout == 0 || i.LastHeartbeatAt == nil || i.HeartbeatExpired {
+			continue
+		}
+
+		if s.now().Sub(*i.LastHeartbeatAt) < i.HeartbeatTimeout {
+			continue
+		}
+
+		if err := s.expireHeartbeat(ctx, i.ID); err != nil {
+			fmt.Printf("Failed to raise heartbeat alert for integration %s: %s\n", i.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) expireHeartbeat(ctx context.Context, id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	i, err := s.store.GetIntegration(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// A heartbeat may have arrived since the integrations were listed.
+	if i.HeartbeatExpired || i.LastHeartbeatAt == nil || s.now().Sub(*i.LastHeartbeatAt) < i.HeartbeatTimeout {
+		return nil
+	}
+
+	i.HeartbeatExpired = true
+
+	if err := s.store.UpdateIntegration(ctx, i); err != nil {
+		return err
+	}
+
+	return s.ingest(ctx, i, &Alert{
+		Title: fmt.Sprintf("Heartbeat of %s expired", i.Name),
+		Message: fmt.Sprintf("No heartbeat has been received since %s, the timeout is %s.",
+			i.LastHeartbeatAt.UTC().Format(time.RFC3339), i.HeartbeatTimeout),
+		Labels:      map[string]string{"alertname": "Heartbeat"},
+		GroupingKey: heartbeatGroupingKey,
+	})
+}
+
+// HeartbeatChecker periodically checks the heartbeats of all integrations.
+type HeartbeatChecker struct {
+	integrations *Service
+	interval     time.Duration
+}
+
+func NewHeartbeatChecker(integrations *Service, interval time.Duration) *HeartbeatChecker {
+	return &HeartbeatChecker{
+		integrations: integrations,
+		interval:     interval,
+	}
+}
+
+func (c *HeartbeatChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.integrations.CheckHeartbeats(ctx); err != nil {
+				fmt.Printf("Failed to check heartbeats: %s\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type MatchType string
+
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher is an Alertmanager style label matcher.
+type Matcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+
+	re *regexp.Regexp
+}
+
+// ParseMatcher parses a matcher such as severity=~"critical|high". Quotes
+// around the value are optional.
+func ParseMatcher(s string) (*Matcher, error) {
+	s = strings.TrimSpace(s)
+
+	idx := strings.IndexAny(s, "=!")
+	if idx <= 0 {
+		return nil, fmt.Errorf("%w: %q is not a label matcher", ErrInvalidRoute, s)
+	}
+
+	m := &Matcher{Name: strings.TrimSpace(s[:idx])}
+	rest := s[idx:]
+
+	for _, t := range []MatchType{MatchRegexp, MatchNotRegexp, MatchNotEqual, MatchEqual} {
+		if strings.HasPrefix(rest, string(t)) {
+			m.Type = t
+			m.Value = strings.TrimSpace(rest[len(t):])
+			break
+		}
+	}
+
+	if m.Type == "" {
+		return nil, fmt.Errorf("%w: %q has no valid operator", ErrInvalidRoute, s)
+	}
+
+	if len(m.Value) >= 2 && m.Value[0] == '"' && m.Value[len(m.Value)-1] == '"' {
+		m.Value = m.Value[1 : len(m.Value)-1]
+	}
+
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// Matches reports whether the labels satisfy the matcher. A missing label
+// has the empty value, as in Alertmanager.
+func (m *Matcher) Matches(labels map[string]string) bool {
+	v := labels[m.Name]
+
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(v)
+	case MatchNotRegexp:
+		return !m.re.MatchString(v)
+	}
+
+	return false
+}
+
+func (m *Matcher) compile() error {
+	if m.Type != MatchRegexp && m.Type != MatchNotRegexp {
+		return nil
+	}
+
+	// Anchored like Alertmanager, so the whole value must match.
+	re, err := regexp.Compile("^(?:" + m.Value + ")$")
+	if err != nil {
+		return fmt.Errorf("%w: invalid regular expression in %s: %s", ErrInvalidRoute, m.Name, err)
+	}
+
+	m.re = re
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Matcher
+		wantErr bool
+	}{
+		{in: `severity="critical"`, want: Matcher{Name: "severity", Type: MatchEqual, Value: "critical"}},
+		{in: `severity=critical`, want: Matcher{Name: "severity", Type: MatchEqual, Value: "critical"}},
+		{in: ` severity != "info" `, want: Matcher{Name: "severity", Type: MatchNotEqual, Value: "info"}},
+		{in: `severity=~"critical|high"`, want: Matcher{Name: "severity", Type: MatchRegexp, Value: "critical|high"}},
+		{in: `env!~"dev.*"`, want: Matcher{Name: "env", Type: MatchNotRegexp, Value: "dev.*"}},
+		{in: `team=""`, want: Matcher{Name: "team", Type: MatchEqual, Value: ""}},
+		{in: `summary="a=b"`, want: Matcher{Name: "summary", Type: MatchEqual, Value: "a=b"}},
+		{in: `severity`, wantErr: true},
+		{in: `="critical"`, wantErr: true},
+		{in: `severity!"critical"`, wantErr: true},
+		{in: `severity=~"("`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m, err := ParseMatcher(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRoute) {
+					t.Errorf("ParseMatcher() error = %v, want %v", err, ErrInvalidRoute)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseMatcher() error = %v", err)
+			}
+
+			if m.Name != tt.want.Name || m.Type != tt.want.Type || m.Value != tt.want.Value {
+				t.Errorf("ParseMatcher() = %s, want %s", m, &tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherMatches(t *testing.T) {
+	labels := map[string]string{"severity": "critical", "env": "production"}
+
+	tests := []struct {
+		matcher string
+		want    bool
+	}{
+		{matcher: `severity="critical"`, want: true},
+		{matcher: `severity="high"`, want: false},
+		{matcher: `severity!="high"`, want: true},
+		{matcher: `severity!="critical"`, want: false},
+		{matcher: `severity=~"critical|high"`, want: true},
+		{matcher: `severity=~"crit"`, want: false},
+		{matcher: `env!~"dev.*"`, want: true},
+		{matcher: `env!~"prod.*"`, want: false},
+		{matcher: `team=""`, want: true},
+		{matcher: `team!=""`, want: false},
+		{matcher: `team=~".*"`, want: true},
+		{matcher: `team=~".+"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			m, err := ParseMatcher(tt.matcher)
+			if err != nil {
+				t.Fatalf("ParseMatcher() error = %v", err)
+			}
+
+			if got := m.Matches(labels); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteMatches(t *testing.T) {
+	alert := &Alert{
+		Title:  "Disk full",
+		Labels: map[string]string{"severity": "critical", "env": "production"},
+	}
+
+	tests := []struct {
+		name     string
+		matchers []string
+		template string
+		want     bool
+	}{
+		{name: "no filters", want: true},
+		{name: "all matchers match", matchers: []string{`severity="critical"`, `env=~"prod.*"`}, want: true},
+		{name: "one matcher does not match", matchers: []string{`severity="critical"`, `env="staging"`}, want: false},
+		{name: "template renders true", template: `{{ eq .labels.severity "critical" }}`, want: true},
+		{name: "template renders false", template: `{{ eq .labels.severity "info" }}`, want: false},
+		{name: "template renders something else", template: `{{ .title }}`, want: false},
+		{name: "matchers and template", matchers: []string{`env="production"`}, template: `{{ eq .title "Disk full" }}`, want: true},
+		{name: "matchers checked before template", matchers: []string{`env="staging"`}, template: `true`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Route{ID: "r", TemplateExpression: tt.template}
+			for _, s := range tt.matchers {
+				m, err := ParseMatcher(s)
+				if err != nil {
+					t.Fatalf("ParseMatcher() error = %v", err)
+				}
+				r.LabelMatchers = append(r.LabelMatchers, m)
+			}
+
+			if err := r.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			got, err := r.Matches(alert)
+			if err != nil {
+				t.Fatalf("Matches() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteValidate(t *testing.T) {
+	severity, err := ParseMatcher(`severity="critical"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		route   Route
+		wantErr bool
+	}{
+		{name: "default route", route: Route{Default: true}},
+		{name: "default route with matchers", route: Route{Default: true, LabelMatchers: []*Matcher{severity}}, wantErr: true},
+		{name: "default route with template", route: Route{Default: true, TemplateExpression: "true"}, wantErr: true},
+		{name: "invalid template", route: Route{TemplateExpression: "{{ .title "}, wantErr: true},
+		{name: "channel name", route: Route{ChatChannel: "#ops-alerts"}},
+		{name: "channel name without hash", route: Route{ChatChannel: "ops_alerts"}},
+		{name: "channel ID", route: Route{ChatChannel: "C0123ABCD"}},
+		{name: "private channel ID", route: Route{ChatChannel: "G0123ABCD"}},
+		{name: "channel name with spaces", route: Route{ChatChannel: "ops alerts"}, wantErr: true},
+		{name: "channel name in capitals", route: Route{ChatChannel: "#Ops"}, wantErr: true},
+		{name: "webhook URL", route: Route{ChatChannel: "https://example.com/hook"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.route.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRoute) {
+					t.Errorf("Validate() error = %v, want %v", err, ErrInvalidRoute)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeAlertmanager    Type = "alertmanager"
+	TypeGrafanaAlerting Type = "grafana_alerting"
+	TypeWebhook         Type = "webhook"
+)
+
+var Types = []Type{TypeAlertmanager, TypeGrafanaAlerting, TypeWebhook}
+
+// slackChannel matches the Slack channels routes can post to, by name, with
+// or without a leading #, or by ID.
+var slackChannel = regexp.MustCompile(`^(#?[a-z0-9_-]{1,80}|[CG][A-Z0-9]{6,})$`)
+
+var (
+	ErrIntegrationNotFound = errors.New("integration not found")
+	ErrInvalidIntegration  = errors.New("invalid integration")
+	ErrRouteNotFound       = errors.New("route not found")
+	ErrInvalidRoute        = errors.New("invalid route")
+)
+
+type Integration struct {
+	ID     string
+	Name   string
+	Type   Type
+	TeamID string
+	// Token authenticates the alert sources posting to the integration URL.
+	Token string
+	// HeartbeatTimeout is how long the integration may go without a
+	// heartbeat before an alert is raised, zero disables heartbeat
+	// monitoring.
+	HeartbeatTimeout time.Duration
+	LastHeartbeatAt  *time.Time
+	// HeartbeatExpired is set while the heartbeat alert is firing.
+	HeartbeatExpired bool
+	CreatedAt        time.Time
+}
+
+// Route decides which escalation chain and Slack channel handle the alert
+// groups of an integration. Routes are evaluated in order of position and
+// the default route is used when none of the others match.
+type Route struct {
+	ID            string
+	IntegrationID string
+	Position      int
+	Default       bool
+	// TemplateExpression is a Go template evaluated against the alert which
+	// matches when it renders to true.
+	TemplateExpression string
+	// LabelMatchers must all match the labels of the alert.
+	LabelMatchers     []*Matcher
+	EscalationChainID string
+	// ChatChannel is the Slack channel alert groups are posted to instead of
+	// the default channel of the Slack notifier. The other chat notifiers
+	// post to the destination they are configured with.
+	ChatChannel string
+
+	tmpl *template.Template
+}
+
+// Alert is a single alert received by an integration.
+type Alert struct {
+	Title   string
+	Message string
+	Labels  map[string]string
+	// GroupingKey identifies the alert group the alert belongs to.
+	GroupingKey string
+	Resolved    bool
+	Payload     map[string]any
+}
+
+func (i *Integration) Validate() error {
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidIntegration)
+	}
+
+	if !slices.Contains(Types, i.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidIntegration, i.Type)
+	}
+
+	if i.HeartbeatTimeout != 0 && i.HeartbeatTimeout < MinHeartbeatTimeout {
+		return fmt.Errorf("%w: heartbeat timeout must be at least %s", ErrInvalidIntegration, MinHeartbeatTimeout)
+	}
+
+	return nil
+}
+
+// Validate checks the route and prepares it for evaluation.
+func (r *Route) Validate() error {
+	if r.Default && (r.TemplateExpression != "" || len(r.LabelMatchers) > 0) {
+		return fmt.Errorf("%w: the default route cannot have filters", ErrInvalidRoute)
+	}
+
+	if r.ChatChannel != "" && !slackChannel.MatchString(r.ChatChannel) {
+		return fmt.Errorf("%w: chat channel %q is not a Slack channel name or ID", ErrInvalidRoute, r.ChatChannel)
+	}
+
+	return r.compile()
+}
+
+// compile prepares the template expression of the route for evaluation.
+func (r *Route) compile() error {
+	r.tmpl = nil
+	if r.TemplateExpression == "" {
+		return nil
+	}
+
+	tmpl, err := template.New("route").Funcs(templateFuncs).Option("missingkey=zero").Parse(r.TemplateExpression)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRoute, err)
+	}
+
+	r.tmpl = tmpl
+
+	return nil
+}
+
+// Matches reports whether the alert is routed by the route. Routes without
+// any filters match every alert.
+func (r *Route) Matches(a *Alert) (bool, error) {
+	for _, m := range r.LabelMatchers {
+		if !m.Matches(a.Labels) {
+			return false, nil
+		}
+	}
+
+	if r.tmpl == nil {
+		return true, nil
+	}
+
+	var sb strings.Builder
+	if err := r.tmpl.Execute(&sb, templateData(a)); err != nil {
+		return false, fmt.Errorf("failed to evaluate route %s: %w", r.ID, err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(sb.String())) {
+	case "true", "1", "yes":
+		return true, nil
+	}
+
+	return false, nil
+}
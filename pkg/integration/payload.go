@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// alertmanagerPayload is the body of an Alertmanager webhook notification,
+// which Grafana Alerting also sends.
+type alertmanagerPayload struct {
+	Status            string            `json:"status"`
+	GroupKey          string            `json:"groupKey"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	Alerts            []struct {
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"alerts"`
+}
+
+// webhookPayload is the body accepted by generic webhook integrations.
+type webhookPayload struct {
+	AlertUID string            `json:"alert_uid"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	State    string            `json:"state"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// ParseAlert decodes the body posted to an integration of the given type.
+func ParseAlert(t Type, body []byte) (*Alert, error) {
+	a := &Alert{}
+	if err := json.Unmarshal(body, &a.Payload); err != nil {
+		return nil, fmt.Errorf("invalid alert payload: %w", err)
+	}
+
+	switch t {
+	case TypeAlertmanager, TypeGrafanaAlerting:
+		p := &alertmanagerPayload{}
+		if err := json.Unmarshal(body, p); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", t, err)
+		}
+
+		labels := maps.Clone(p.CommonLabels)
+		annotations := maps.Clone(p.CommonAnnotations)
+		if len(p.Alerts) == 1 {
+			labels = p.Alerts[0].Labels
+			annotations = p.Alerts[0].Annotations
+		}
+
+		a.Labels = labels
+		a.Title = firstNonEmpty(annotations["summary"], labels["alertname"], "Alert")
+		a.Message = annotations["description"]
+		a.GroupingKey = firstNonEmpty(p.GroupKey, labelsKey(labels))
+		a.Resolved = p.Status == "resolved"
+	case TypeWebhook:
+		p := &webhookPayload{}
+		if err := json.Unmarshal(body, p); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", t, err)
+		}
+
+		a.Labels = p.Labels
+		a.Title = firstNonEmpty(p.Title, "Alert")
+		a.Message = p.Message
+		a.GroupingKey = firstNonEmpty(p.AlertUID, p.Title)
+		a.Resolved = p.State == "ok" || p.State == "resolved"
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidIntegration, t)
+	}
+
+	return a, nil
+}
+
+// labelsKey derives a stable grouping key from a set of labels.
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, labels[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,366 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+// JobIngest processes an alert received by an integration, with the
+// integration ID and the raw payload as arguments.
+const JobIngest worker.JobType = "alert_ingest"
+
+type Service struct {
+	mtx         sync.Mutex
+	store       Store
+	alertGroups *alertgroup.Service
+	escalations *escalation.Service
+	worker      worker.Worker
+	now         func() time.Time
+}
+
+func NewService(store Store, alertGroups *alertgroup.Service, escalations *escalation.Service, w worker.Worker) *Service {
+	return &Service{
+		store:       store,
+		alertGroups: alertGroups,
+		escalations: escalations,
+		worker:      w,
+		now:         time.Now,
+	}
+}
+
+// CreateIntegration stores the integration together with its default route.
+func (s *Service) CreateIntegration(ctx context.Context, i *Integration) error {
+	if err := i.Validate(); err != nil {
+		return err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	i.ID = uuid.NewString()
+	i.Token = token
+	i.CreatedAt = s.now()
+
+	if err := s.store.CreateIntegration(ctx, i); err != nil {
+		return err
+	}
+
+	return s.store.SaveRoute(ctx, &Route{
+		ID:            uuid.NewString(),
+		IntegrationID: i.ID,
+		Default:       true,
+	})
+}
+
+func (s *Service) GetIntegration(ctx context.Context, id string) (*Integration, error) {
+	return s.store.GetIntegration(ctx, id)
+}
+
+func (s *Service) ListIntegrations(ctx context.Context) ([]*Integration, error) {
+	return s.store.ListIntegrations(ctx)
+}
+
+// UpdateIntegration changes the name, team and heartbeat timeout of an
+// integration.
+func (s *Service) UpdateIntegration(ctx context.Context, i *Integration) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	existing, err := s.store.GetIntegration(ctx, i.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = i.Name
+	existing.TeamID = i.TeamID
+	existing.HeartbeatTimeout = i.HeartbeatTimeout
+
+	if err := existing.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.store.UpdateIntegration(ctx, existing); err != nil {
+		return err
+	}
+
+	*i = *existing
+
+	return nil
+}
+
+func (s *Service) DeleteIntegration(ctx context.Context, id string) error {
+	return s.store.DeleteIntegration(ctx, id)
+}
+
+// Authenticate returns the integration of the type the token belongs to.
+func (s *Service) Authenticate(ctx context.Context, t Type, token string) (*Integration, error) {
+	i, err := s.store.GetIntegrationByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Type != t {
+		return nil, ErrIntegrationNotFound
+	}
+
+	return i, nil
+}
+
+// Receive queues an alert posted to the integration URL for ingestion.
+func (s *Service) Receive(ctx context.Context, t Type, token string, payload []byte) error {
+	i, err := s.Authenticate(ctx, t, token)
+	if err != nil {
+		return err
+	}
+
+	return s.worker.Enqueue(ctx, JobIngest, i.ID, string(payload))
+}
+
+// HandleIngest groups a received alert into an alert group, creating and
+// routing a new one to its escalation chain when there is no unresolved
+// group for it yet.
+func (s *Service) HandleIngest(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	i, err := s.store.GetIntegration(ctx, job.Args[0])
+	if err != nil {
+		fmt.Printf("Failed to load integration %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	a, err := ParseAlert(i.Type, []byte(job.Args[1]))
+	if err != nil {
+		fmt.Printf("Discarding alert for integration %s: %s\n", i.ID, err)
+		return
+	}
+
+	if err := s.ingest(ctx, i, a); err != nil {
+		fmt.Printf("Failed to ingest alert for integration %s: %s\n", i.ID, err)
+	}
+}
+
+// ingest groups the alert holding the lock of its grouping key, so that
+// workers ingesting alerts with the same key at once do not each create an
+// alert group for them. The escalation starts once the alert group has been
+// stored.
+func (s *Service) ingest(ctx context.Context, i *Integration, a *Alert) error {
+	var (
+		created *alertgroup.AlertGroup
+		route   *Route
+	)
+
+	err := s.alertGroups.Grouping(ctx, i.ID, a.GroupingKey, func(ctx context.Context) error {
+		existing, err := s.alertGroups.FindUnresolved(ctx, i.ID, a.GroupingKey)
+		if err != nil && !errors.Is(err, alertgroup.ErrAlertGroupNotFound) {
+			return err
+		}
+
+		if existing != nil {
+			if a.Resolved {
+				_, err = s.alertGroups.Resolve(ctx, existing.ID, alertgroup.SourceActor)
+			} else {
+				_, err = s.alertGroups.AddAlert(ctx, existing.ID)
+			}
+			return err
+		}
+
+		// Nothing to resolve.
+		if a.Resolved {
+			return nil
+		}
+
+		if route, err = s.SelectRoute(ctx, i.ID, a); err != nil {
+			return err
+		}
+
+		g := &alertgroup.AlertGroup{
+			IntegrationID: i.ID,
+			TeamID:        i.TeamID,
+			Title:         a.Title,
+			Message:       a.Message,
+			Labels:        a.Labels,
+			GroupingKey:   a.GroupingKey,
+			RouteID:       route.ID,
+		}
+
+		if err := s.alertGroups.Create(ctx, g); err != nil {
+			return err
+		}
+
+		created = g
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if created == nil || route.EscalationChainID == "" {
+		return nil
+	}
+
+	return s.escalations.Start(ctx, created.ID, route.EscalationChainID)
+}
+
+// SelectRoute returns the first route of the integration matching the alert,
+// or the default route when none do. Routes which fail to evaluate are
+// skipped.
+func (s *Service) SelectRoute(ctx context.Context, integrationID string, a *Alert) (*Route, error) {
+	routes, err := s.store.ListRoutes(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range routes {
+		if r.Default {
+			return r, nil
+		}
+
+		if err := r.compile(); err != nil {
+			fmt.Printf("Skipping route %s: %s\n", r.ID, err)
+			continue
+		}
+
+		ok, err := r.Matches(a)
+		if err != nil {
+			fmt.Printf("Skipping route %s: %s\n", r.ID, err)
+			continue
+		}
+
+		if ok {
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("integration %s has no default route", integrationID)
+}
+
+func (s *Service) GetRoute(ctx context.Context, id string) (*Route, error) {
+	return s.store.GetRoute(ctx, id)
+}
+
+func (s *Service) ListRoutes(ctx context.Context, integrationID string) ([]*Route, error) {
+	if _, err := s.store.GetIntegration(ctx, integrationID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListRoutes(ctx, integrationID)
+}
+
+// CreateRoute inserts a non-default route at its position, moving later
+// routes down.
+func (s *Service) CreateRoute(ctx context.Context, r *Route) error {
+	if _, err := s.store.GetIntegration(ctx, r.IntegrationID); err != nil {
+		return err
+	}
+
+	r.ID = uuid.NewString()
+	r.Default = false
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.place(ctx, r)
+}
+
+// UpdateRoute changes the filters, destination and position of a route. The
+// integration and whether the route is the default cannot change.
+func (s *Service) UpdateRoute(ctx context.Context, r *Route) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	existing, err := s.store.GetRoute(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+
+	r.IntegrationID = existing.IntegrationID
+	r.Default = existing.Default
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	if r.Default {
+		return s.store.SaveRoute(ctx, r)
+	}
+
+	return s.place(ctx, r)
+}
+
+func (s *Service) DeleteRoute(ctx context.Context, id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	r, err := s.store.GetRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if r.Default {
+		return fmt.Errorf("%w: the default route cannot be deleted", ErrInvalidRoute)
+	}
+
+	return s.store.DeleteRoute(ctx, id)
+}
+
+// place saves the route at its position among the other non-default routes
+// of the integration and renumbers them.
+func (s *Service) place(ctx context.Context, r *Route) error {
+	routes, err := s.store.ListRoutes(ctx, r.IntegrationID)
+	if err != nil {
+		return err
+	}
+
+	ordered := make([]*Route, 0, len(routes))
+	for _, other := range routes {
+		if !other.Default && other.ID != r.ID {
+			ordered = append(ordered, other)
+		}
+	}
+
+	pos := min(max(r.Position, 0), len(ordered))
+	ordered = append(ordered[:pos], append([]*Route{r}, ordered[pos:]...)...)
+
+	for i, route := range ordered {
+		if route.Position == i && route != r {
+			continue
+		}
+
+		route.Position = i
+		if err := s.store.SaveRoute(ctx, route); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 15)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate integration token: %w", err)
+	}
+
+	return strings.ToLower(base32.StdEncoding.EncodeToString(b)), nil
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps integrations and routes in the SQL database, which the
+// server and workers share.
+type SQLStore struct {
+	db           *sqlstore.DB
+	integrations *sqlstore.Table[Integration]
+	routes       *sqlstore.Table[Route]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	integrations, err := sqlstore.NewTable(db, "integrations", func(i *Integration) string { return i.ID },
+		sqlstore.Column[Integration]{Name: "token", Value: func(i *Integration) string { return i.Token }})
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := sqlstore.NewTable(db, "integration_routes", func(r *Route) string { return r.ID },
+		sqlstore.Column[Route]{Name: "integration_id", Value: func(r *Route) string { return r.IntegrationID }})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, integrations: integrations, routes: routes}, nil
+}
+
+func (s *SQLStore) CreateIntegration(ctx context.Context, i *Integration) error {
+	return s.integrations.Insert(ctx, i)
+}
+
+func (s *SQLStore) GetIntegration(ctx context.Context, id string) (*Integration, error) {
+	i, err := s.integrations.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrIntegrationNotFound
+	}
+
+	return i, err
+}
+
+func (s *SQLStore) GetIntegrationByToken(ctx context.Context, token string) (*Integration, error) {
+	i, err := s.integrations.FindOne(ctx, sqlstore.Where{"token": token})
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrIntegrationNotFound
+	}
+
+	return i, err
+}
+
+func (s *SQLStore) ListIntegrations(ctx context.Context) ([]*Integration, error) {
+	integrations, err := s.integrations.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(integrations, func(a, b int) bool {
+		return integrations[a].Name < integrations[b].Name
+	})
+
+	return integrations, nil
+}
+
+func (s *SQLStore) UpdateIntegration(ctx context.Context, i *Integration) error {
+	err := s.integrations.Update(ctx, i)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrIntegrationNotFound
+	}
+
+	return err
+}
+
+func (s *SQLStore) DeleteIntegration(ctx context.Context, id string) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		err := s.integrations.Delete(ctx, id)
+		if errors.Is(err, sqlstore.ErrNotFound) {
+			return ErrIntegrationNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = s.routes.DeleteWhere(ctx, sqlstore.Where{"integration_id": id})
+		return err
+	})
+}
+
+func (s *SQLStore) GetRoute(ctx context.Context, id string) (*Route, error) {
+	r, err := s.routes.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrRouteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.prepare(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *SQLStore) ListRoutes(ctx context.Context, integrationID string) ([]*Route, error) {
+	routes, err := s.routes.Find(ctx, sqlstore.Where{"integration_id": integrationID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range routes {
+		if err := r.prepare(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(routes, func(a, b int) bool {
+		if routes[a].Default != routes[b].Default {
+			return routes[b].Default
+		}
+		if routes[a].Position != routes[b].Position {
+			return routes[a].Position < routes[b].Position
+		}
+		return routes[a].ID < routes[b].ID
+	})
+
+	return routes, nil
+}
+
+func (s *SQLStore) SaveRoute(ctx context.Context, r *Route) error {
+	return s.routes.Save(ctx, r)
+}
+
+func (s *SQLStore) DeleteRoute(ctx context.Context, id string) error {
+	err := s.routes.Delete(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrRouteNotFound
+	}
+
+	return err
+}
+
+// prepare compiles the template expression and matchers of a route read
+// from the database, which only keeps their source.
+func (r *Route) prepare() error {
+	for _, m := range r.LabelMatchers {
+		if err := m.compile(); err != nil {
+			return err
+		}
+	}
+
+	return r.compile()
+}
@@ -0,0 +1,19 @@
+package integration
+
+import "context"
+
+type Store interface {
+	CreateIntegration(ctx context.Context, i *Integration) error
+	GetIntegration(ctx context.Context, id string) (*Integration, error)
+	GetIntegrationByToken(ctx context.Context, token string) (*Integration, error)
+	ListIntegrations(ctx context.Context) ([]*Integration, error)
+	UpdateIntegration(ctx context.Context, i *Integration) error
+	DeleteIntegration(ctx context.Context, id string) error
+
+	GetRoute(ctx context.Context, id string) (*Route, error)
+	// ListRoutes returns the routes of an integration ordered by position,
+	// with the default route last.
+	ListRoutes(ctx context.Context, integrationID string) ([]*Route, error)
+	SaveRoute(ctx context.Context, r *Route) error
+	DeleteRoute(ctx context.Context, id string) error
+}
@@ -0,0 +1,29 @@
+package integration
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"match": func(pattern, s string) (bool, error) {
+		return regexp.MatchString(pattern, s)
+	},
+}
+
+// templateData is what route templates are evaluated against, for example
+// {{ match "db-.*" .labels.instance }} or {{ eq .payload.status "firing" }}.
+func templateData(a *Alert) map[string]any {
+	return map[string]any{
+		"title":   a.Title,
+		"message": a.Message,
+		"labels":  a.Labels,
+		"payload": a.Payload,
+	}
+}
@@ -0,0 +1,116 @@
+package notificationpolicy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Importance string
+
+const (
+	ImportanceDefault   Importance = "default"
+	ImportanceImportant Importance = "important"
+)
+
+type StepType string
+
+const (
+	// StepNotify notifies the user by Method.
+	StepNotify StepType = "notify"
+	// StepWait delays the next step by Duration.
+	StepWait StepType = "wait"
+)
+
+// DefaultMethod is used to notify users who have not set up a policy.
+const DefaultMethod = "email"
+
+// MaxSteps limits the length of a policy.
+const MaxSteps = 20
+
+var (
+	ErrInvalidPolicy = errors.New("invalid notification policy")
+)
+
+// Policy is the ordered steps taken to notify a user of an alert group at
+// an importance.
+type Policy struct {
+	UserID     string
+	Importance Importance
+	Steps      []*Step
+}
+
+type Step struct {
+	Type StepType
+	// Method names how the user is notified, such as sms or phone_call.
+	Method   string
+	Duration time.Duration
+}
+
+// Execution tracks the progress of notifying a user of an alert group.
+type Execution struct {
+	AlertGroupID string
+	UserID       string
+	Importance   Importance
+	// StepIndex is the next step to be executed.
+	StepIndex int
+	Done      bool
+	// Generation is incremented whenever the user is notified of the alert
+	// group again so that step jobs of the earlier run are ignored.
+	Generation int
+	UpdatedAt  time.Time
+}
+
+// DefaultPolicy applies to users who have not set up a policy of the
+// importance.
+func DefaultPolicy(userID string, importance Importance) *Policy {
+	return &Policy{
+		UserID:     userID,
+		Importance: importance,
+		Steps:      []*Step{{Type: StepNotify, Method: DefaultMethod}},
+	}
+}
+
+func ParseImportance(s string) (Importance, error) {
+	switch Importance(s) {
+	case ImportanceDefault, ImportanceImportant:
+		return Importance(s), nil
+	default:
+		return "", fmt.Errorf("%w: unknown importance %q", ErrInvalidPolicy, s)
+	}
+}
+
+func (p *Policy) Validate() error {
+	if _, err := ParseImportance(string(p.Importance)); err != nil {
+		return err
+	}
+
+	if len(p.Steps) > MaxSteps {
+		return fmt.Errorf("%w: at most %d steps are allowed", ErrInvalidPolicy, MaxSteps)
+	}
+
+	for i, step := range p.Steps {
+		if err := step.Validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Step) Validate() error {
+	switch s.Type {
+	case StepNotify:
+		if s.Method == "" {
+			return fmt.Errorf("%w: notification method is required", ErrInvalidPolicy)
+		}
+	case StepWait:
+		if s.Duration <= 0 {
+			return fmt.Errorf("%w: wait duration must be positive", ErrInvalidPolicy)
+		}
+	default:
+		return fmt.Errorf("%w: unknown step type %q", ErrInvalidPolicy, s.Type)
+	}
+
+	return nil
+}
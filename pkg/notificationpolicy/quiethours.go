@@ -0,0 +1,161 @@
+package notificationpolicy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type QuietAction string
+
+const (
+	// QuietDefer holds notifications back until quiet hours are over.
+	QuietDefer QuietAction = "defer"
+	// QuietDowngrade sends notifications by the downgrade method instead.
+	QuietDowngrade QuietAction = "downgrade"
+)
+
+// MaxQuietWindows limits the windows of quiet hours.
+const MaxQuietWindows = 14
+
+var ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+// QuietHours hold back notifications of default importance while the user
+// is not on call. Important notifications always go through.
+type QuietHours struct {
+	UserID  string
+	Windows []*QuietWindow
+	Action  QuietAction
+	// DowngradeMethod replaces the method of notify steps when Action is
+	// QuietDowngrade.
+	DowngradeMethod string
+	// DoNotDisturbUntil makes the user quiet until then, regardless of the
+	// windows.
+	DoNotDisturbUntil *time.Time
+}
+
+// QuietWindow is a daily period, in the time zone of the user, during which
+// the user is quiet. A window ending before it starts runs past midnight.
+type QuietWindow struct {
+	// Days are the days on which the window starts, every day if empty.
+	Days []time.Weekday
+	// Start and End are times of day, as durations since midnight.
+	Start time.Duration
+	End   time.Duration
+}
+
+// DefaultQuietHours apply to users who have not set up quiet hours, and
+// never hold anything back.
+func DefaultQuietHours(userID string) *QuietHours {
+	return &QuietHours{
+		UserID: userID,
+		Action: QuietDefer,
+	}
+}
+
+func (q *QuietHours) Validate() error {
+	switch q.Action {
+	case QuietDefer:
+	case QuietDowngrade:
+		if q.DowngradeMethod == "" {
+			return fmt.Errorf("%w: downgrade method is required", ErrInvalidQuietHours)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidQuietHours, q.Action)
+	}
+
+	if len(q.Windows) > MaxQuietWindows {
+		return fmt.Errorf("%w: at most %d windows are allowed", ErrInvalidQuietHours, MaxQuietWindows)
+	}
+
+	for i, w := range q.Windows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("window %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *QuietWindow) Validate() error {
+	const day = 24 * time.Hour
+
+	if w.Start < 0 || w.Start >= day || w.End < 0 || w.End >= day {
+		return fmt.Errorf("%w: start and end must be times of day", ErrInvalidQuietHours)
+	}
+
+	if w.Start == w.End {
+		return fmt.Errorf("%w: start and end must differ", ErrInvalidQuietHours)
+	}
+
+	for _, d := range w.Days {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("%w: unknown day of the week %d", ErrInvalidQuietHours, d)
+		}
+	}
+
+	return nil
+}
+
+// QuietUntil reports whether the user is quiet at the time, and if so when
+// they stop being quiet. Windows are evaluated in loc.
+func (q *QuietHours) QuietUntil(at time.Time, loc *time.Location) (time.Time, bool) {
+	var until time.Time
+
+	if q.DoNotDisturbUntil != nil && at.Before(*q.DoNotDisturbUntil) {
+		until = *q.DoNotDisturbUntil
+	}
+
+	local := at.In(loc)
+
+	// A window which started the day before may still be running.
+	for offset := -1; offset <= 0; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+
+		for _, w := range q.Windows {
+			if !w.startsOn(day.Weekday()) {
+				continue
+			}
+
+			start := timeOfDay(day, w.Start)
+			end := timeOfDay(day, w.End)
+			if w.End < w.Start {
+				end = timeOfDay(day.AddDate(0, 0, 1), w.End)
+			}
+
+			if !at.Before(start) && at.Before(end) && end.After(until) {
+				until = end
+			}
+		}
+	}
+
+	return until, !until.IsZero()
+}
+
+func (w *QuietWindow) startsOn(d time.Weekday) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, d)
+}
+
+// timeOfDay returns the wall clock time on the day, which stays correct
+// across daylight saving changes.
+func timeOfDay(day time.Time, d time.Duration) time.Time {
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location())
+}
+
+func (q *QuietHours) clone() *QuietHours {
+	c := *q
+	c.Windows = make([]*QuietWindow, 0, len(q.Windows))
+	for _, w := range q.Windows {
+		wc := *w
+		wc.Days = slices.Clone(w.Days)
+		c.Windows = append(c.Windows, &wc)
+	}
+	if q.DoNotDisturbUntil != nil {
+		t := *q.DoNotDisturbUntil
+		c.DoNotDisturbUntil = &t
+	}
+	return &c
+}
@@ -0,0 +1,331 @@
+package notificationpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// JobStep executes a single step of the policy of a user, with the alert
+// group ID, the user ID, the execution generation and the step index as
+// arguments.
+const JobStep worker.JobType = "notification_policy_step"
+
+type Service struct {
+	mtx         sync.Mutex
+	store       Store
+	alertGroups *alertgroup.Service
+	users       *user.Service
+	schedules   *schedule.Service
+	notifiers   *notifier.Service
+	worker      worker.Worker
+	now         func() time.Time
+}
+
+func NewService(store Store, alertGroups *alertgroup.Service, users *user.Service, schedules *schedule.Service, notifiers *notifier.Service, w worker.Worker) *Service {
+	return &Service{
+		store:       store,
+		alertGroups: alertGroups,
+		users:       users,
+		schedules:   schedules,
+		notifiers:   notifiers,
+		worker:      w,
+		now:         time.Now,
+	}
+}
+
+// Policy returns the policy of the user at the importance, or the default
+// policy when the user has not set one up.
+func (s *Service) Policy(ctx context.Context, userID string, importance Importance) (*Policy, error) {
+	p, err := s.store.GetPolicy(ctx, userID, importance)
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil {
+		return DefaultPolicy(userID, importance), nil
+	}
+
+	return p, nil
+}
+
+// PreferredMethod returns the method of the first notify step of the default
+// policy of the user, which is how they are told about things other than
+// alert groups, such as their shifts.
+func (s *Service) PreferredMethod(ctx context.Context, userID string) (string, error) {
+	p, err := s.Policy(ctx, userID, ImportanceDefault)
+	if err != nil {
+		return "", err
+	}
+
+	for _, step := range p.Steps {
+		if step.Type == StepNotify {
+			return step.Method, nil
+		}
+	}
+
+	return DefaultMethod, nil
+}
+
+func (s *Service) SavePolicy(ctx context.Context, p *Policy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	for i, step := range p.Steps {
+		if step.Type == StepNotify && !s.notifiers.Enabled(step.Method) {
+			return fmt.Errorf("step %d: %w: notification method %q is not enabled", i+1, ErrInvalidPolicy, step.Method)
+		}
+	}
+
+	return s.store.SavePolicy(ctx, p)
+}
+
+// QuietHours returns the quiet hours of the user, or the default ones when
+// the user has not set them up.
+func (s *Service) QuietHours(ctx context.Context, userID string) (*QuietHours, error) {
+	q, err := s.store.GetQuietHours(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if q == nil {
+		return DefaultQuietHours(userID), nil
+	}
+
+	return q, nil
+}
+
+func (s *Service) SaveQuietHours(ctx context.Context, q *QuietHours) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	if q.Action == QuietDowngrade && !s.notifiers.Enabled(q.DowngradeMethod) {
+		return fmt.Errorf("%w: notification method %q is not enabled", ErrInvalidQuietHours, q.DowngradeMethod)
+	}
+
+	return s.store.SaveQuietHours(ctx, q)
+}
+
+// Notify starts notifying the user of the alert group by their policy of the
+// importance. A run already under way for the same user and alert group is
+// replaced.
+func (s *Service) Notify(ctx context.Context, alertGroupID, userID string, importance Importance) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, err := s.store.GetExecution(ctx, alertGroupID, userID)
+	if err != nil {
+		return err
+	}
+
+	if e == nil {
+		e = &Execution{AlertGroupID: alertGroupID, UserID: userID}
+	}
+
+	e.Importance = importance
+	e.StepIndex = 0
+	e.Done = false
+	e.Generation++
+	e.UpdatedAt = s.now()
+
+	if err := s.store.SaveExecution(ctx, e); err != nil {
+		return err
+	}
+
+	return s.schedule(ctx, e, 0)
+}
+
+// HandleNotifyUser starts a policy run for an escalation notifying a user,
+// with the alert group ID, the user ID and the importance as arguments.
+func (s *Service) HandleNotifyUser(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 3 {
+		fmt.Printf("Invalid %s job %s, expected 3 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	importance, err := ParseImportance(job.Args[2])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s: %s\n", job.Type, job.ID, err)
+		return
+	}
+
+	if err := s.Notify(ctx, job.Args[0], job.Args[1], importance); err != nil {
+		fmt.Printf("Failed to notify %s of alert group %s: %s\n", job.Args[1], job.Args[0], err)
+	}
+}
+
+func (s *Service) HandleStep(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 4 {
+		fmt.Printf("Invalid %s job %s, expected 4 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	alertGroupID, userID := job.Args[0], job.Args[1]
+	generation, err := strconv.Atoi(job.Args[2])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s, bad generation %q\n", job.Type, job.ID, job.Args[2])
+		return
+	}
+
+	index, err := strconv.Atoi(job.Args[3])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s, bad step index %q\n", job.Type, job.ID, job.Args[3])
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	e, err := s.store.GetExecution(ctx, alertGroupID, userID)
+	if err != nil {
+		fmt.Printf("Failed to load notification of %s for alert group %s: %s\n", userID, alertGroupID, err)
+		return
+	}
+
+	// Jobs published before the run finished or was restarted are stale.
+	if e == nil || e.Done || e.Generation != generation || e.StepIndex != index {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	// Acknowledging, resolving, silencing or attaching the alert group
+	// stops notifying its users.
+	if !g.IsActive() {
+		s.finish(ctx, e)
+		return
+	}
+
+	p, err := s.Policy(ctx, userID, e.Importance)
+	if err != nil {
+		fmt.Printf("Failed to load notification policy of %s: %s\n", userID, err)
+		return
+	}
+
+	if index >= len(p.Steps) {
+		s.finish(ctx, e)
+		return
+	}
+
+	var delay time.Duration
+	switch step := p.Steps[index]; step.Type {
+	case StepNotify:
+		method := step.Method
+
+		if e.Importance == ImportanceDefault {
+			q, until, err := s.quietUntil(ctx, userID)
+			if err != nil {
+				fmt.Printf("Failed to check quiet hours of %s: %s\n", userID, err)
+			}
+
+			if !until.IsZero() {
+				if q.Action == QuietDefer {
+					// Retry the same step once the user is no longer quiet.
+					if err := s.schedule(ctx, e, until.Sub(s.now())); err != nil {
+						fmt.Printf("Failed to defer notification of %s: %s\n", userID, err)
+					}
+					return
+				}
+
+				method = q.DowngradeMethod
+			}
+		}
+
+		m := &notifier.Message{
+			UserID:           userID,
+			Title:            g.Title,
+			Text:             g.Message,
+			Importance:       string(e.Importance),
+			AlertGroupID:     alertGroupID,
+			AlertGroupNumber: g.Number,
+		}
+
+		if err := s.notifiers.Notify(ctx, method, m); err != nil {
+			fmt.Printf("Failed to publish %s notification of %s: %s\n", method, userID, err)
+		}
+	case StepWait:
+		delay = step.Duration
+	}
+
+	e.StepIndex = index + 1
+	e.UpdatedAt = s.now()
+
+	if err := s.store.SaveExecution(ctx, e); err != nil {
+		fmt.Printf("Failed to save notification of %s for alert group %s: %s\n", userID, alertGroupID, err)
+		return
+	}
+
+	if err := s.schedule(ctx, e, delay); err != nil {
+		fmt.Printf("Failed to schedule next notification step of %s: %s\n", userID, err)
+	}
+}
+
+// quietUntil returns the quiet hours of the user and when they stop being
+// quiet, or the zero time if the user is not quiet or is on call.
+func (s *Service) quietUntil(ctx context.Context, userID string) (*QuietHours, time.Time, error) {
+	q, err := s.QuietHours(ctx, userID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	loc := time.UTC
+	u, err := s.users.Get(ctx, userID)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return nil, time.Time{}, err
+	}
+	if u != nil {
+		if l, err := time.LoadLocation(u.TimeZone); err == nil {
+			loc = l
+		}
+	}
+
+	now := s.now()
+	until, quiet := q.QuietUntil(now, loc)
+	if !quiet {
+		return q, time.Time{}, nil
+	}
+
+	shifts, err := s.schedules.UserShifts(ctx, userID, now, now.Add(time.Second))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if len(shifts) > 0 {
+		return q, time.Time{}, nil
+	}
+
+	return q, until, nil
+}
+
+func (s *Service) finish(ctx context.Context, e *Execution) {
+	e.Done = true
+	e.UpdatedAt = s.now()
+
+	if err := s.store.SaveExecution(ctx, e); err != nil {
+		fmt.Printf("Failed to save notification of %s for alert group %s: %s\n", e.UserID, e.AlertGroupID, err)
+	}
+}
+
+func (s *Service) schedule(ctx context.Context, e *Execution, delay time.Duration) error {
+	args := []string{e.AlertGroupID, e.UserID, strconv.Itoa(e.Generation), strconv.Itoa(e.StepIndex)}
+	if delay > 0 {
+		return s.worker.EnqueueIn(ctx, delay, JobStep, args...)
+	}
+
+	return s.worker.Enqueue(ctx, JobStep, args...)
+}
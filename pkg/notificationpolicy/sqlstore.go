@@ -0,0 +1,84 @@
+package notificationpolicy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps policies in the SQL database, which the server and workers
+// share.
+type SQLStore struct {
+	policies   *sqlstore.Table[Policy]
+	executions *sqlstore.Table[Execution]
+	quietHours *sqlstore.Table[QuietHours]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	policies, err := sqlstore.NewTable(db, "notification_policies", func(p *Policy) string { return policyID(p.UserID, p.Importance) })
+	if err != nil {
+		return nil, err
+	}
+
+	executions, err := sqlstore.NewTable(db, "notification_policy_executions", func(e *Execution) string { return executionID(e.AlertGroupID, e.UserID) })
+	if err != nil {
+		return nil, err
+	}
+
+	quietHours, err := sqlstore.NewTable(db, "quiet_hours", func(q *QuietHours) string { return q.UserID })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{policies: policies, executions: executions, quietHours: quietHours}, nil
+}
+
+func (st *SQLStore) GetPolicy(ctx context.Context, userID string, importance Importance) (*Policy, error) {
+	p, err := st.policies.Get(ctx, policyID(userID, importance))
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return p, err
+}
+
+func (st *SQLStore) SavePolicy(ctx context.Context, p *Policy) error {
+	return st.policies.Save(ctx, p)
+}
+
+func (st *SQLStore) GetExecution(ctx context.Context, alertGroupID, userID string) (*Execution, error) {
+	e, err := st.executions.Get(ctx, executionID(alertGroupID, userID))
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return e, err
+}
+
+func (st *SQLStore) SaveExecution(ctx context.Context, e *Execution) error {
+	return st.executions.Save(ctx, e)
+}
+
+func (st *SQLStore) GetQuietHours(ctx context.Context, userID string) (*QuietHours, error) {
+	q, err := st.quietHours.Get(ctx, userID)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return q, err
+}
+
+func (st *SQLStore) SaveQuietHours(ctx context.Context, q *QuietHours) error {
+	return st.quietHours.Save(ctx, q)
+}
+
+func policyID(userID string, importance Importance) string {
+	return userID + "/" + string(importance)
+}
+
+func executionID(alertGroupID, userID string) string {
+	return alertGroupID + "/" + userID
+}
@@ -0,0 +1,20 @@
+package notificationpolicy
+
+import "context"
+
+type Store interface {
+	// GetPolicy returns the policy of the user at the importance, or nil if
+	// they have not set one up.
+	GetPolicy(ctx context.Context, userID string, importance Importance) (*Policy, error)
+	SavePolicy(ctx context.Context, p *Policy) error
+
+	// GetExecution returns the execution for the user and alert group, or
+	// nil if the user has not been notified of it.
+	GetExecution(ctx context.Context, alertGroupID, userID string) (*Execution, error)
+	SaveExecution(ctx context.Context, e *Execution) error
+
+	// GetQuietHours returns the quiet hours of the user, or nil if they have
+	// not set them up.
+	GetQuietHours(ctx context.Context, userID string) (*QuietHours, error)
+	SaveQuietHours(ctx context.Context, q *QuietHours) error
+}
@@ -0,0 +1,190 @@
+// Package chat holds what the chat notifiers share about alert groups: the
+// actions offered on their messages, how their state is shown and how
+// changes to them are described.
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/user"
+)
+
+// IDs of the actions offered on alert group messages.
+const (
+	ActionAcknowledge   = "acknowledge"
+	ActionUnacknowledge = "unacknowledge"
+	ActionResolve       = "resolve"
+	ActionUnresolve     = "unresolve"
+	ActionSilence       = "silence"
+	ActionUnsilence     = "unsilence"
+	ActionEscalate      = "escalate"
+)
+
+type Style string
+
+const (
+	StyleDefault Style = ""
+	StylePrimary Style = "primary"
+	StyleDanger  Style = "danger"
+)
+
+// Action is a button offered on alert group messages. The silence action
+// is offered with the SilenceOptions.
+type Action struct {
+	ID    string
+	Label string
+	Style Style
+}
+
+// SilenceOption is a duration offered to silence an alert group for, zero
+// meaning until it is unsilenced.
+type SilenceOption struct {
+	Label    string
+	Duration time.Duration
+}
+
+var SilenceOptions = []SilenceOption{
+	{"30 minutes", 30 * time.Minute},
+	{"1 hour", time.Hour},
+	{"4 hours", 4 * time.Hour},
+	{"24 hours", 24 * time.Hour},
+	{"Forever", 0},
+}
+
+// StateEmoji shows the state of alert groups in chats which render unicode
+// emoji.
+var StateEmoji = map[alertgroup.State]string{
+	alertgroup.StateFiring:       "🔴",
+	alertgroup.StateAcknowledged: "🟠",
+	alertgroup.StateSilenced:     "⚪",
+	alertgroup.StateResolved:     "🟢",
+}
+
+// StateColor is the accent color of alert group messages by state.
+var StateColor = map[alertgroup.State]string{
+	alertgroup.StateFiring:       "#E02F44",
+	alertgroup.StateAcknowledged: "#FF9830",
+	alertgroup.StateSilenced:     "#8E8E8E",
+	alertgroup.StateResolved:     "#56A64B",
+}
+
+// ActionText describes alert group actions in replies to alert group
+// messages.
+var ActionText = map[alertgroup.Action]string{
+	alertgroup.ActionAcknowledge:   "Acknowledged",
+	alertgroup.ActionUnacknowledge: "Unacknowledged",
+	alertgroup.ActionResolve:       "Resolved",
+	alertgroup.ActionUnresolve:     "Unresolved",
+	alertgroup.ActionSilence:       "Silenced",
+	alertgroup.ActionUnsilence:     "Unsilenced",
+	alertgroup.ActionAttach:        "Attached to another alert group",
+	alertgroup.ActionUnattach:      "Unattached",
+}
+
+// Actions returns the actions that apply to the state of the alert group.
+func Actions(g *alertgroup.AlertGroup) []*Action {
+	silence := &Action{ID: ActionSilence, Label: "Silence"}
+
+	switch g.State {
+	case alertgroup.StateFiring:
+		return []*Action{
+			{ID: ActionAcknowledge, Label: "Acknowledge", Style: StylePrimary},
+			{ID: ActionResolve, Label: "Resolve"},
+			silence,
+			{ID: ActionEscalate, Label: "Escalate", Style: StyleDanger},
+		}
+	case alertgroup.StateAcknowledged:
+		return []*Action{
+			{ID: ActionUnacknowledge, Label: "Unacknowledge"},
+			{ID: ActionResolve, Label: "Resolve", Style: StylePrimary},
+			silence,
+		}
+	case alertgroup.StateSilenced:
+		return []*Action{
+			{ID: ActionUnsilence, Label: "Unsilence"},
+			{ID: ActionResolve, Label: "Resolve", Style: StylePrimary},
+		}
+	case alertgroup.StateResolved:
+		return []*Action{
+			{ID: ActionUnresolve, Label: "Unresolve"},
+		}
+	}
+
+	return nil
+}
+
+// Apply takes the action on the alert group as the user. Silence is how
+// long the silence action silences it for.
+func Apply(ctx context.Context, alertGroups *alertgroup.Service, escalations *escalation.Service, alertGroupID, action string, silence time.Duration, userID string) error {
+	var err error
+	switch action {
+	case ActionAcknowledge:
+		_, err = alertGroups.Acknowledge(ctx, alertGroupID, userID)
+	case ActionUnacknowledge:
+		_, err = alertGroups.Unacknowledge(ctx, alertGroupID, userID)
+	case ActionResolve:
+		_, err = alertGroups.Resolve(ctx, alertGroupID, userID)
+	case ActionUnresolve:
+		_, err = alertGroups.Unresolve(ctx, alertGroupID, userID)
+	case ActionSilence:
+		_, err = alertGroups.Silence(ctx, alertGroupID, userID, silence)
+	case ActionUnsilence:
+		_, err = alertGroups.Unsilence(ctx, alertGroupID, userID)
+	case ActionEscalate:
+		err = escalations.Escalate(ctx, alertGroupID)
+	default:
+		err = fmt.Errorf("unknown action %q", action)
+	}
+
+	return err
+}
+
+// Status describes the last change of the alert group by the action, such
+// as "Acknowledged by alice", naming the actor with name and formatting
+// times with date. It is empty for actions which are not described.
+func Status(ctx context.Context, alertGroups *alertgroup.Service, g *alertgroup.AlertGroup, action alertgroup.Action, name func(actor string) string, date func(t time.Time) string) string {
+	text, ok := ActionText[action]
+	if !ok {
+		return ""
+	}
+
+	transitions, err := alertGroups.Transitions(ctx, g.ID)
+	if err != nil || len(transitions) == 0 {
+		return text
+	}
+
+	t := transitions[len(transitions)-1]
+	text += " by " + name(t.Actor)
+	if t.SilencedUntil != nil {
+		text += " until " + date(*t.SilencedUntil)
+	}
+
+	return text
+}
+
+// Date formats times in UTC, for chats which cannot show them in the time
+// zone of the reader.
+func Date(t time.Time) string {
+	return t.UTC().Format(time.RFC1123)
+}
+
+// ActorName names the actor of an alert group action.
+func ActorName(ctx context.Context, users *user.Service, actor string) string {
+	switch actor {
+	case alertgroup.SystemActor:
+		return "OnCall"
+	case alertgroup.SourceActor:
+		return "the alert source"
+	}
+
+	u, err := users.Get(ctx, actor)
+	if err != nil {
+		return actor
+	}
+
+	return u.Username
+}
@@ -0,0 +1,75 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"time"
+)
+
+// message is an email with plain text and HTML alternatives.
+type message struct {
+	From    *mail.Address
+	To      string
+	ReplyTo string
+	Subject string
+	ID      string
+	Date    time.Time
+	Text    string
+	HTML    string
+}
+
+// bytes encodes the message as a MIME multipart/alternative message.
+func (m *message) bytes() ([]byte, error) {
+	var b bytes.Buffer
+
+	header := func(k, v string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+
+	header("From", m.From.String())
+	header("To", m.To)
+	if m.ReplyTo != "" {
+		header("Reply-To", m.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Message-ID", m.ID)
+	header("Date", m.Date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Auto-Submitted", "auto-generated")
+
+	w := multipart.NewWriter(&b)
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", w.Boundary()))
+	b.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,323 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/base32"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/web"
+	"github.com/google/uuid"
+)
+
+const Type = "email"
+
+// Security of the connection to the SMTP server.
+const (
+	SecurityStartTLS = "starttls"
+	SecurityTLS      = "tls"
+	SecurityNone     = "none"
+)
+
+const (
+	DefaultPort = 587
+
+	// ReplyTokenTTL is how long replies to a notification are accepted.
+	ReplyTokenTTL = 7 * 24 * time.Hour
+
+	// templateName is the name of the HTML and plain text email templates.
+	templateName = "notification"
+
+	timeout = 30 * time.Second
+)
+
+var tokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Notifier sends emails through an SMTP server. It is configured by the
+// [notifier.email] section.
+type Notifier struct {
+	store     Store
+	templates *web.Templates
+	// templatesErr is reported by ValidateConfig.
+	templatesErr error
+
+	host     string
+	port     string
+	username string
+	password string
+	security string
+	from     *mail.Address
+	fromErr  error
+	// replyAddress receives replies when set, with the reply token as its
+	// subaddress.
+	replyAddress string
+	inboundToken string
+	now          func() time.Time
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+// New returns the factory of email notifiers, which render the templates in
+// templatesDir and save reply tokens in the store.
+func New(store Store, templatesDir string) notifier.Factory {
+	return func(settings map[string]string) notifier.Notifier {
+		n := &Notifier{
+			store:        store,
+			host:         settings["host"],
+			port:         settings["port"],
+			username:     settings["username"],
+			password:     settings["password"],
+			security:     settings["security"],
+			replyAddress: settings["reply_address"],
+			inboundToken: settings["inbound_token"],
+			now:          time.Now,
+		}
+
+		if n.port == "" {
+			n.port = strconv.Itoa(DefaultPort)
+		}
+
+		if n.security == "" {
+			n.security = SecurityStartTLS
+		}
+
+		n.from, n.fromErr = mail.ParseAddress(settings["from_address"])
+		if n.fromErr == nil && settings["from_name"] != "" {
+			n.from.Name = settings["from_name"]
+		}
+
+		n.templates, n.templatesErr = web.LoadTemplates(templatesDir, "[[", "]]")
+
+		return n
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address: notifier.AddressEmail,
+		Actions: n.replyAddress != "",
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	if n.host == "" {
+		return fmt.Errorf("%w: host is required", notifier.ErrInvalidConfig)
+	}
+
+	if _, err := strconv.ParseUint(n.port, 10, 16); err != nil {
+		return fmt.Errorf("%w: invalid port %q", notifier.ErrInvalidConfig, n.port)
+	}
+
+	switch n.security {
+	case SecurityStartTLS, SecurityTLS, SecurityNone:
+	default:
+		return fmt.Errorf("%w: security must be starttls, tls or none", notifier.ErrInvalidConfig)
+	}
+
+	if n.fromErr != nil {
+		return fmt.Errorf("%w: invalid from_address: %s", notifier.ErrInvalidConfig, n.fromErr)
+	}
+
+	if n.replyAddress != "" {
+		if _, err := mail.ParseAddress(n.replyAddress); err != nil || strings.Contains(n.replyAddress, "+") {
+			return fmt.Errorf("%w: reply_address must be an email address without a subaddress", notifier.ErrInvalidConfig)
+		}
+
+		if n.inboundToken == "" {
+			return fmt.Errorf("%w: inbound_token is required to receive replies", notifier.ErrInvalidConfig)
+		}
+	}
+
+	if n.templatesErr != nil {
+		return fmt.Errorf("%w: %s", notifier.ErrInvalidConfig, n.templatesErr)
+	}
+
+	if _, _, err := n.render(&content{Subject: "Test", Title: "Test"}); err != nil {
+		return fmt.Errorf("%w: %s", notifier.ErrInvalidConfig, err)
+	}
+
+	return nil
+}
+
+// content is the data the email templates are rendered with.
+type content struct {
+	Subject string
+	Title   string
+	Text    string
+	URL     string
+	// Number is the number of the alert group, when Reply is set.
+	Number int
+	// Reply is set when replies to the email can acknowledge or resolve
+	// the alert group.
+	Reply bool
+}
+
+func (n *Notifier) render(c *content) (string, string, error) {
+	var text, html bytes.Buffer
+	if err := n.templates.Text(&text, templateName, c); err != nil {
+		return "", "", err
+	}
+
+	if err := n.templates.HTML(&html, templateName, c); err != nil {
+		return "", "", err
+	}
+
+	return text.String(), html.String(), nil
+}
+
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	c := &content{
+		Subject: m.Title,
+		Title:   m.Title,
+		Text:    m.Text,
+		URL:     m.URL,
+		Number:  m.AlertGroupNumber,
+	}
+
+	if m.AlertGroupNumber > 0 {
+		c.Subject = fmt.Sprintf("[OnCall #%d] %s", m.AlertGroupNumber, m.Title)
+	}
+
+	var replyTo string
+	if n.replyAddress != "" && m.AlertGroupID != "" && m.UserID != "" {
+		var err error
+		if replyTo, err = n.replyTo(ctx, m); err != nil {
+			return nil, err
+		}
+		c.Reply = true
+	}
+
+	text, html, err := n.render(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+
+	msg := &message{
+		From:    n.from,
+		To:      address,
+		ReplyTo: replyTo,
+		Subject: c.Subject,
+		ID:      fmt.Sprintf("<%s@%s>", uuid.NewString(), domain(n.from.Address)),
+		Date:    n.now(),
+		Text:    text,
+		HTML:    html,
+	}
+
+	data, err := msg.bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.deliver(ctx, address, data); err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: msg.ID}, nil
+}
+
+// replyTo saves a reply token for the notification and returns the reply
+// address with it as the subaddress.
+func (n *Notifier) replyTo(ctx context.Context, m *notifier.Message) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reply token: %w", err)
+	}
+
+	t := &ReplyToken{
+		Token:        strings.ToLower(tokenEncoding.EncodeToString(b)),
+		AlertGroupID: m.AlertGroupID,
+		UserID:       m.UserID,
+		ExpiresAt:    n.now().Add(ReplyTokenTTL),
+	}
+
+	if err := n.store.SaveReplyToken(ctx, t); err != nil {
+		return "", err
+	}
+
+	local, host, _ := strings.Cut(n.replyAddress, "@")
+	return local + "+" + t.Token + "@" + host, nil
+}
+
+// deliver sends the message to the SMTP server.
+func (n *Notifier) deliver(ctx context.Context, to string, data []byte) error {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.host, n.port))
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	tlsConfig := &tls.Config{ServerName: n.host}
+	if n.security == SecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if n.security == SecurityStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+	}
+
+	if n.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+	}
+
+	if err := c.Mail(n.from.Address); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// Verify checks the token inbound emails are posted with.
+func (n *Notifier) Verify(token string) error {
+	if n.inboundToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(n.inboundToken)) != 1 {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+func domain(address string) string {
+	_, d, _ := strings.Cut(address, "@")
+	return d
+}
@@ -0,0 +1,161 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/user"
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid inbound email token")
+	ErrUnknownReply   = errors.New("reply does not answer a notification, or it expired")
+	ErrUnknownSender  = errors.New("reply was not sent by the notified user")
+	ErrUnknownCommand = errors.New("reply does not start with ack, unack, resolve or unresolve")
+)
+
+// replyActions maps the first word of replies to the actions they take.
+var replyActions = map[string]alertgroup.Action{
+	"ack":           alertgroup.ActionAcknowledge,
+	"acknowledge":   alertgroup.ActionAcknowledge,
+	"unack":         alertgroup.ActionUnacknowledge,
+	"unacknowledge": alertgroup.ActionUnacknowledge,
+	"resolve":       alertgroup.ActionResolve,
+	"unresolve":     alertgroup.ActionUnresolve,
+}
+
+// Service applies the actions of replies to notification emails, which are
+// posted by the inbound email service of the mail provider.
+type Service struct {
+	store       Store
+	notifier    *Notifier
+	alertGroups *alertgroup.Service
+	users       *user.Service
+	now         func() time.Time
+}
+
+// NewService creates the inbound email service, which does nothing unless
+// the email notifier is enabled in the registry with a reply address.
+func NewService(store Store, registry *notifier.Registry, alertGroups *alertgroup.Service, users *user.Service) *Service {
+	s := &Service{
+		store:       store,
+		alertGroups: alertGroups,
+		users:       users,
+		now:         time.Now,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+// Enabled reports whether replies to notification emails are received.
+func (s *Service) Enabled() bool {
+	return s.notifier != nil && s.notifier.replyAddress != ""
+}
+
+// Verify checks the token inbound emails are posted with.
+func (s *Service) Verify(token string) error {
+	return s.notifier.Verify(token)
+}
+
+// Reply is an email received by the inbound email service.
+type Reply struct {
+	// Recipient holds the addresses the email was sent to, one of which is
+	// the reply address of a notification.
+	Recipient string
+	Sender    string
+	Text      string
+}
+
+// HandleReply applies the action the reply starts with to the alert group of
+// the notification it answers, as the notified user, and returns the updated
+// alert group.
+func (s *Service) HandleReply(ctx context.Context, r *Reply) (*alertgroup.AlertGroup, error) {
+	t, err := s.replyToken(ctx, r.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.users.Get(ctx, t.UserID)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return nil, ErrUnknownReply
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := mail.ParseAddress(r.Sender)
+	if err != nil || !strings.EqualFold(sender.Address, u.Email) {
+		return nil, ErrUnknownSender
+	}
+
+	action, ok := replyActions[firstWord(r.Text)]
+	if !ok {
+		return nil, ErrUnknownCommand
+	}
+
+	switch action {
+	case alertgroup.ActionAcknowledge:
+		return s.alertGroups.Acknowledge(ctx, t.AlertGroupID, u.ID)
+	case alertgroup.ActionUnacknowledge:
+		return s.alertGroups.Unacknowledge(ctx, t.AlertGroupID, u.ID)
+	case alertgroup.ActionResolve:
+		return s.alertGroups.Resolve(ctx, t.AlertGroupID, u.ID)
+	default:
+		return s.alertGroups.Unresolve(ctx, t.AlertGroupID, u.ID)
+	}
+}
+
+// replyToken finds the reply token in the subaddress of the recipient that
+// is the reply address.
+func (s *Service) replyToken(ctx context.Context, recipient string) (*ReplyToken, error) {
+	addresses, err := mail.ParseAddressList(recipient)
+	if err != nil {
+		return nil, ErrUnknownReply
+	}
+
+	local, host, _ := strings.Cut(s.notifier.replyAddress, "@")
+
+	for _, a := range addresses {
+		l, h, _ := strings.Cut(a.Address, "@")
+		prefix, token, ok := strings.Cut(l, "+")
+		if !ok || !strings.EqualFold(prefix, local) || !strings.EqualFold(h, host) {
+			continue
+		}
+
+		t, err := s.store.GetReplyToken(ctx, strings.ToLower(token))
+		if err != nil {
+			return nil, err
+		}
+
+		if t != nil && s.now().Before(t.ExpiresAt) {
+			return t, nil
+		}
+	}
+
+	return nil, ErrUnknownReply
+}
+
+// firstWord returns the first word of the reply, ignoring blank lines and
+// the quoted notification.
+func firstWord(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ">") {
+			continue
+		}
+
+		word, _, _ := strings.Cut(line, " ")
+		return strings.ToLower(strings.Trim(word, ".,!"))
+	}
+
+	return ""
+}
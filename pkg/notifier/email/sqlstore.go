@@ -0,0 +1,38 @@
+package email
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps reply tokens in the SQL database, which the server and
+// workers share.
+type SQLStore struct {
+	tokens *sqlstore.Table[ReplyToken]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	tokens, err := sqlstore.NewTable(db, "email_reply_tokens", func(t *ReplyToken) string { return t.Token })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{tokens: tokens}, nil
+}
+
+func (st *SQLStore) SaveReplyToken(ctx context.Context, t *ReplyToken) error {
+	return st.tokens.Save(ctx, t)
+}
+
+func (st *SQLStore) GetReplyToken(ctx context.Context, token string) (*ReplyToken, error) {
+	t, err := st.tokens.Get(ctx, token)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return t, err
+}
@@ -0,0 +1,21 @@
+package email
+
+import (
+	"context"
+	"time"
+)
+
+// ReplyToken identifies the notification an email reply answers. It is the
+// subaddress of the Reply-To address of the notification.
+type ReplyToken struct {
+	Token        string
+	AlertGroupID string
+	UserID       string
+	ExpiresAt    time.Time
+}
+
+type Store interface {
+	SaveReplyToken(ctx context.Context, t *ReplyToken) error
+	// GetReplyToken returns the token, or nil if there is no such token.
+	GetReplyToken(ctx context.Context, token string) (*ReplyToken, error)
+}
@@ -0,0 +1,65 @@
+package gotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Client sends messages to a Gotify server.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// Message is a message sent to an application.
+type Message struct {
+	Title    string         `json:"title,omitempty"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+type messageResponse struct {
+	ID               int64  `json:"id"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+// CreateMessage sends the message to the application with the token and
+// returns its ID.
+func (c *Client) CreateMessage(ctx context.Context, appToken string, m *Message) (string, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.url, "/")+"/message", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Gotify-Key", appToken)
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		return "", fmt.Errorf("gotify message: %w", err)
+	}
+	defer res.Body.Close()
+
+	var resp messageResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", fmt.Errorf("gotify message: unexpected response with status %s", res.Status)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gotify message: %s: %s", resp.Error, resp.ErrorDescription)
+	}
+
+	return strconv.FormatInt(resp.ID, 10), nil
+}
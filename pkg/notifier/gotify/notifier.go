@@ -0,0 +1,91 @@
+package gotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+)
+
+// Type is the notifier type, and the type of the contact method holding
+// the token of the Gotify application users receive notifications in.
+const Type = "gotify"
+
+// Priorities of notifications by importance, on the scale of 0 to 10 of
+// Gotify. The Android app alerts with sound from 4, and pops up from 8.
+const (
+	priorityDefault   = 8
+	priorityImportant = 10
+)
+
+// Notifier sends push notifications through a Gotify server. It is
+// configured by the [notifier.gotify] section.
+type Notifier struct {
+	client    *Client
+	serverURL string
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+func New(settings map[string]string) notifier.Notifier {
+	return &Notifier{
+		client: &Client{
+			url:  settings["server_url"],
+			http: &http.Client{Timeout: 10 * time.Second},
+		},
+		serverURL: settings["server_url"],
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:       notifier.AddressContactMethod,
+		SecretAddress: true,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	u, err := url.Parse(n.serverURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: server_url must be the URL of a Gotify server", notifier.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Send sends the message to the application with the token in address,
+// opening the URL of the message when the notification is clicked.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	msg := &Message{
+		Title:    m.Title,
+		Message:  m.Text,
+		Priority: priorityDefault,
+	}
+
+	if msg.Message == "" {
+		msg.Message = m.Title
+	}
+
+	if m.Importance == string(notificationpolicy.ImportanceImportant) {
+		msg.Priority = priorityImportant
+	}
+
+	if m.URL != "" {
+		msg.Extras = map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]string{"url": m.URL},
+			},
+		}
+	}
+
+	id, err := n.client.CreateMessage(ctx, address, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: id}, nil
+}
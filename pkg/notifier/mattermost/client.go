@@ -0,0 +1,114 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Client posts to a Mattermost incoming webhook.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// Post is a message posted to the webhook.
+type Post struct {
+	// Channel overrides the channel of the webhook, by name or as @username
+	// for a direct message.
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Props    *Props `json:"props,omitempty"`
+	// Attachments are only sent to the webhook, updates carry them in Props.
+	Attachments []*Attachment `json:"attachments,omitempty"`
+}
+
+type Props struct {
+	Attachments []*Attachment `json:"attachments"`
+}
+
+// Attachment is a message attachment.
+type Attachment struct {
+	Fallback string    `json:"fallback"`
+	Color    string    `json:"color,omitempty"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text,omitempty"`
+	Fields   []*Field  `json:"fields,omitempty"`
+	Footer   string    `json:"footer,omitempty"`
+	Actions  []*Action `json:"actions,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Action is an interactive button or menu. Mattermost posts to the URL of
+// its integration with the context when it is used.
+type Action struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	Style       string       `json:"style,omitempty"`
+	Options     []*Option    `json:"options,omitempty"`
+	Integration *Integration `json:"integration"`
+}
+
+type Option struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type Integration struct {
+	URL     string         `json:"url"`
+	Context *ActionContext `json:"context"`
+}
+
+// ActionContext is sent back to the integration URL of an action. Mattermost
+// adds the value of the chosen option of menus as SelectedOption.
+type ActionContext struct {
+	Action         string `json:"action"`
+	AlertGroupID   string `json:"alert_group_id"`
+	Token          string `json:"token"`
+	SelectedOption string `json:"selected_option,omitempty"`
+}
+
+// Send posts the message to the webhook.
+func (c *Client) Send(ctx context.Context, p *Post) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		// The webhook URL is its credential, so it is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("mattermost webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("mattermost webhook: unexpected status %s: %s", res.Status, bytes.TrimSpace(b))
+	}
+
+	return nil
+}
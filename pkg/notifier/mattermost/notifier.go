@@ -0,0 +1,124 @@
+package mattermost
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// Type is the notifier type, and the type of the contact method holding
+// the Mattermost username of users.
+const Type = "mattermost"
+
+// ActionsPath is where Mattermost posts the actions taken with the buttons
+// of alert group messages, relative to the root URL.
+const ActionsPath = "mattermost/actions"
+
+// maxTextLength is the longest message Mattermost accepts.
+const maxTextLength = 16383
+
+var ErrInvalidToken = errors.New("invalid mattermost action token")
+
+// Notifier posts to a Mattermost incoming webhook. It is configured by the
+// [notifier.mattermost] section.
+type Notifier struct {
+	client     *Client
+	webhookURL string
+	// channel is where alert groups are posted, the channel of the webhook
+	// when empty.
+	channel      string
+	username     string
+	actionSecret string
+	// rootURL is where Mattermost reaches the actions endpoint.
+	rootURL string
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+func New(rootURL string) notifier.Factory {
+	return func(settings map[string]string) notifier.Notifier {
+		return &Notifier{
+			client: &Client{
+				url:  settings["webhook_url"],
+				http: &http.Client{Timeout: 10 * time.Second},
+			},
+			webhookURL:   settings["webhook_url"],
+			channel:      settings["channel"],
+			username:     settings["username"],
+			actionSecret: settings["action_secret"],
+			rootURL:      rootURL,
+		}
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:   notifier.AddressContactMethod,
+		Channel:   true,
+		Actions:   true,
+		MaxLength: maxTextLength,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	u, err := url.Parse(n.webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook_url must be the URL of an incoming webhook", notifier.ErrInvalidConfig)
+	}
+
+	if n.actionSecret == "" {
+		return fmt.Errorf("%w: action_secret is required", notifier.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Send posts the message to a channel, or as a direct message to the
+// username in address, with buttons to acknowledge and resolve the alert
+// group it is about.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	channel := address
+	if m.Channel == "" {
+		channel = "@" + strings.TrimPrefix(address, "@")
+	}
+
+	a := &Attachment{
+		Fallback: m.Title,
+		Title:    escape(m.Title),
+		Text:     escape(m.Text),
+	}
+	if m.URL != "" {
+		a.Text += fmt.Sprintf("\n[Open](%s)", m.URL)
+	}
+
+	if m.AlertGroupID != "" {
+		a.Actions = []*Action{
+			n.button(&chat.Action{ID: chat.ActionAcknowledge, Label: "Acknowledge"}, m.AlertGroupID),
+			n.button(&chat.Action{ID: chat.ActionResolve, Label: "Resolve"}, m.AlertGroupID),
+		}
+	}
+
+	if err := n.client.Send(ctx, &Post{Channel: channel, Username: n.username, Attachments: []*Attachment{a}}); err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{}, nil
+}
+
+// Verify checks the token in the context of an action request against the
+// action and alert group it names.
+func (n *Notifier) Verify(c *ActionContext) error {
+	if c == nil || n.actionSecret == "" || subtle.ConstantTimeCompare([]byte(n.sign(c.Action, c.AlertGroupID)), []byte(c.Token)) != 1 {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
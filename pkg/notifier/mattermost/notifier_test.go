@@ -0,0 +1,53 @@
+package mattermost
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+func TestVerify(t *testing.T) {
+	n := &Notifier{actionSecret: "secret"}
+	other := &Notifier{actionSecret: "other"}
+
+	context := func(n *Notifier, action, alertGroupID string) *ActionContext {
+		return &ActionContext{Action: action, AlertGroupID: alertGroupID, Token: n.sign(action, alertGroupID)}
+	}
+
+	tampered := func(c *ActionContext, change func(c *ActionContext)) *ActionContext {
+		change(c)
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		notifier *Notifier
+		context  *ActionContext
+		wantErr  bool
+	}{
+		{name: "valid token", notifier: n, context: context(n, chat.ActionAcknowledge, "g1")},
+		{name: "other action", notifier: n, context: tampered(context(n, chat.ActionAcknowledge, "g1"), func(c *ActionContext) { c.Action = chat.ActionResolve }), wantErr: true},
+		{name: "other alert group", notifier: n, context: tampered(context(n, chat.ActionAcknowledge, "g1"), func(c *ActionContext) { c.AlertGroupID = "g2" }), wantErr: true},
+		{name: "wrong secret", notifier: n, context: context(other, chat.ActionAcknowledge, "g1"), wantErr: true},
+		{name: "no token", notifier: n, context: &ActionContext{Action: chat.ActionAcknowledge, AlertGroupID: "g1"}, wantErr: true},
+		{name: "no context", notifier: n, wantErr: true},
+		{name: "no secret configured", notifier: &Notifier{}, context: context(&Notifier{}, chat.ActionAcknowledge, "g1"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notifier.Verify(tt.context)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Verify() error = %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package mattermost
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// alertGroupAttachment renders the alert group with the actions that apply
+// to its state, with status in its footer when it is set.
+func (n *Notifier) alertGroupAttachment(g *alertgroup.AlertGroup, status string) *Attachment {
+	return &Attachment{
+		Fallback: fmt.Sprintf("[%s] #%d %s", g.State, g.Number, g.Title),
+		Color:    chat.StateColor[g.State],
+		Title:    fmt.Sprintf("%s #%d %s", chat.StateEmoji[g.State], g.Number, escape(g.Title)),
+		Text:     escape(g.Message),
+		Fields: []*Field{
+			{Title: "State", Value: string(g.State), Short: true},
+			{Title: "Alerts", Value: strconv.Itoa(g.AlertsCount), Short: true},
+		},
+		Footer:  status,
+		Actions: n.alertGroupActions(g),
+	}
+}
+
+func (n *Notifier) alertGroupActions(g *alertgroup.AlertGroup) []*Action {
+	var actions []*Action
+	for _, a := range chat.Actions(g) {
+		action := n.button(a, g.ID)
+		if a.ID == chat.ActionSilence {
+			action.Type = "select"
+			for _, o := range chat.SilenceOptions {
+				action.Options = append(action.Options, &Option{Text: o.Label, Value: strconv.Itoa(int(o.Duration.Seconds()))})
+			}
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions
+}
+
+// button creates a button posting the action on the alert group to the
+// actions endpoint.
+func (n *Notifier) button(a *chat.Action, alertGroupID string) *Action {
+	return &Action{
+		ID:    a.ID,
+		Name:  a.Label,
+		Type:  "button",
+		Style: string(a.Style),
+		Integration: &Integration{
+			URL: n.rootURL + ActionsPath,
+			Context: &ActionContext{
+				Action:       a.ID,
+				AlertGroupID: alertGroupID,
+				Token:        n.sign(a.ID, alertGroupID),
+			},
+		},
+	}
+}
+
+// sign creates the token carried in the context of an action on the
+// messages of the alert group. Mattermost leaves the context out of the
+// posts it shows, so the token proves an action request was sent by it, and
+// signing the action keeps the token of one button from being replayed with
+// another action.
+func (n *Notifier) sign(action, alertGroupID string) string {
+	mac := hmac.New(sha256.New, []byte(n.actionSecret))
+	mac.Write([]byte(action + ":" + alertGroupID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// escape keeps Mattermost from notifying everyone in the channel when the
+// text mentions them, by breaking the mention with a zero width space.
+func escape(s string) string {
+	return strings.NewReplacer("@channel", "@\u200bchannel", "@here", "@\u200bhere", "@all", "@\u200ball").Replace(s)
+}
@@ -0,0 +1,193 @@
+package mattermost
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+var ErrNotLinked = errors.New("your Mattermost account is not linked to an OnCall user, add your Mattermost username to your contact methods")
+
+// ActionRequest is posted by Mattermost when a button or menu of a message
+// is used.
+type ActionRequest struct {
+	UserID    string         `json:"user_id"`
+	UserName  string         `json:"user_name"`
+	ChannelID string         `json:"channel_id"`
+	PostID    string         `json:"post_id"`
+	Context   *ActionContext `json:"context"`
+}
+
+// ActionResponse updates the message the action was taken on, and tells the
+// user privately when it failed.
+type ActionResponse struct {
+	Update        *Post  `json:"update,omitempty"`
+	EphemeralText string `json:"ephemeral_text,omitempty"`
+}
+
+// Service posts alert groups to the configured Mattermost channel and
+// applies the actions taken with their buttons. Posts of incoming webhooks
+// cannot be edited later, so changes of state made elsewhere are posted as
+// messages of their own.
+type Service struct {
+	notifier    *Notifier
+	notifiers   *notifier.Service
+	alertGroups *alertgroup.Service
+	escalations *escalation.Service
+	users       *user.Service
+}
+
+// NewService creates the Mattermost service, which does nothing unless the
+// Mattermost notifier is enabled in the registry.
+func NewService(registry *notifier.Registry, notifiers *notifier.Service, alertGroups *alertgroup.Service, escalations *escalation.Service, users *user.Service) *Service {
+	s := &Service{
+		notifiers:   notifiers,
+		alertGroups: alertGroups,
+		escalations: escalations,
+		users:       users,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s.notifier != nil
+}
+
+// HandleAlertGroupCreated posts a new alert group to the configured channel.
+func (s *Service) HandleAlertGroupCreated(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.post(ctx, job.Args[0], func(g *alertgroup.AlertGroup) *Post {
+		return &Post{Attachments: []*Attachment{s.notifier.alertGroupAttachment(g, "")}}
+	})
+}
+
+// HandleStateChanged tells the configured channel who changed the state of
+// an alert group.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	s.post(ctx, job.Args[0], func(g *alertgroup.AlertGroup) *Post {
+		status := chat.Status(ctx, s.alertGroups, g, alertgroup.Action(job.Args[1]), func(actor string) string {
+			return chat.ActorName(ctx, s.users, actor)
+		}, chat.Date)
+		if status == "" {
+			return nil
+		}
+
+		return &Post{Text: escape(fmt.Sprintf("#%d %s: %s", g.Number, g.Title, status))}
+	})
+}
+
+// post sends the post returned by render to the configured channel, unless
+// it is nil.
+func (s *Service) post(ctx context.Context, alertGroupID string, render func(g *alertgroup.AlertGroup) *Post) {
+	if !s.Enabled() {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	p := render(g)
+	if p == nil {
+		return
+	}
+
+	p.Channel = s.notifier.channel
+	p.Username = s.notifier.username
+
+	// Posts without a channel go to the channel of the incoming webhook.
+	channel := cmp.Or(p.Channel, "webhook")
+	err = s.notifier.client.Send(ctx, p)
+	s.notifiers.Record(ctx, Type, p.Channel, &notifier.Message{Channel: channel, Title: g.Title, AlertGroupID: g.ID}, nil, err)
+	if err != nil {
+		fmt.Printf("Failed to post alert group %s to Mattermost: %s\n", g.ID, err)
+	}
+}
+
+// Action applies an action taken on a message as the user linked to the
+// Mattermost account that took it, and returns the message updated to the
+// current state of the alert group.
+//
+// Mattermost does not sign action requests, so the token in the context only
+// proves the action and alert group came from one of our posts. The user name
+// is trusted as Mattermost sends it, which relies on the actions endpoint
+// being reachable by the Mattermost server alone.
+func (s *Service) Action(ctx context.Context, req *ActionRequest) (*ActionResponse, error) {
+	if err := s.notifier.Verify(req.Context); err != nil {
+		return nil, err
+	}
+
+	c := req.Context
+	res := &ActionResponse{}
+	if err := s.apply(ctx, c, req.UserName); err != nil {
+		res.EphemeralText = err.Error()
+	}
+
+	g, err := s.alertGroups.Get(ctx, c.AlertGroupID)
+	if err != nil {
+		if res.EphemeralText == "" {
+			res.EphemeralText = err.Error()
+		}
+		return res, nil
+	}
+
+	status := ""
+	if res.EphemeralText == "" {
+		status = chat.ActionText[alertgroup.Action(c.Action)] + " by @" + req.UserName
+		if c.Action == chat.ActionEscalate {
+			status = "Escalated by @" + req.UserName
+		}
+	}
+
+	res.Update = &Post{Props: &Props{Attachments: []*Attachment{s.notifier.alertGroupAttachment(g, status)}}}
+
+	return res, nil
+}
+
+func (s *Service) apply(ctx context.Context, c *ActionContext, username string) error {
+	u, err := s.users.GetByContactMethod(ctx, Type, username)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return ErrNotLinked
+	}
+	if err != nil {
+		return err
+	}
+
+	var silence time.Duration
+	if c.Action == chat.ActionSilence {
+		seconds, err := strconv.Atoi(c.SelectedOption)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("invalid silence duration %q", c.SelectedOption)
+		}
+
+		silence = time.Duration(seconds) * time.Second
+	}
+
+	return chat.Apply(ctx, s.alertGroups, s.escalations, c.AlertGroupID, c.Action, silence, u.ID)
+}
@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AddressKind names the address of a user that a notifier sends to.
+type AddressKind string
+
+const (
+	// AddressPhone is the verified phone number of the user.
+	AddressPhone AddressKind = "phone"
+	// AddressEmail is the email address of the user.
+	AddressEmail AddressKind = "email"
+	// AddressContactMethod is the contact method of the user with the same
+	// type as the notifier, such as their Slack member ID.
+	AddressContactMethod AddressKind = "contact_method"
+)
+
+type Status string
+
+const (
+	StatusSent Status = "sent"
+	// StatusDelivered is reported by services that confirm the message
+	// reached the recipient, such as an answered call.
+	StatusDelivered Status = "delivered"
+	StatusFailed    Status = "failed"
+)
+
+var (
+	ErrNotifierNotEnabled = errors.New("notifier is not enabled")
+	ErrInvalidConfig      = errors.New("invalid notifier configuration")
+	ErrNoAddress          = errors.New("recipient has no address")
+	ErrUnsupported        = errors.New("notification is not supported by notifier")
+	ErrDeliveryNotFound   = errors.New("delivery not found")
+)
+
+// Notifier delivers notifications over one type of channel, such as Slack
+// or SMS.
+type Notifier interface {
+	Capabilities() Capabilities
+	// ValidateConfig checks the settings the notifier was created with
+	// before it is used.
+	ValidateConfig() error
+	// Send delivers the message to the address, which is either the address
+	// of the recipient user or a channel.
+	Send(ctx context.Context, address string, m *Message) (*Result, error)
+}
+
+// Factory creates a notifier from the keys of its [notifier.<type>] section.
+type Factory func(settings map[string]string) Notifier
+
+type Capabilities struct {
+	// Address is how notifications to users are addressed.
+	Address AddressKind
+	// Channel is set when notifications can be posted to a shared channel,
+	// such as the chat channel of a team.
+	Channel bool
+	// Actions is set when recipients can acknowledge and resolve alert
+	// groups from the notification.
+	Actions bool
+	// MaxLength limits the length of the text in characters, zero for no
+	// limit.
+	MaxLength int
+	// SecretAddress is set when addresses are credentials, such as webhook
+	// URLs or application tokens, which are only recorded redacted.
+	SecretAddress bool
+}
+
+// Message is a notification to a user or a channel.
+type Message struct {
+	// UserID is the recipient, unless Channel is set.
+	UserID  string
+	Channel string
+	Title   string
+	Text    string
+	// URL links to more details, such as the alert group.
+	URL          string
+	Importance   string
+	AlertGroupID string
+	// AlertGroupNumber is the short number of the alert group, which users
+	// refer to in replies to notifiers that accept them.
+	AlertGroupNumber int
+}
+
+// Result describes a message accepted by the service behind a notifier.
+type Result struct {
+	// ExternalID identifies the message in the service, when it reports one.
+	ExternalID string
+}
+
+// Delivery records the outcome of sending a notification.
+type Delivery struct {
+	ID           string
+	Type         string
+	UserID       string
+	Channel      string
+	Address      string
+	AlertGroupID string
+	Title        string
+	Status       Status
+	Error        string
+	ExternalID   string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
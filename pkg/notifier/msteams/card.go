@@ -0,0 +1,100 @@
+package msteams
+
+import (
+	"fmt"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+const cardVersion = "1.4"
+
+// Card is an adaptive card.
+type Card struct {
+	Schema  string       `json:"$schema"`
+	Type    string       `json:"type"`
+	Version string       `json:"version"`
+	Body    []*Element   `json:"body"`
+	Actions []*Action    `json:"actions,omitempty"`
+	MSTeams *CardOptions `json:"msteams,omitempty"`
+}
+
+// CardOptions are the Teams specific properties of a card.
+type CardOptions struct {
+	Width string `json:"width,omitempty"`
+}
+
+// Element is a TextBlock, or a FactSet of Facts.
+type Element struct {
+	Type     string  `json:"type"`
+	Text     string  `json:"text,omitempty"`
+	Weight   string  `json:"weight,omitempty"`
+	Size     string  `json:"size,omitempty"`
+	Color    string  `json:"color,omitempty"`
+	IsSubtle bool    `json:"isSubtle,omitempty"`
+	Wrap     bool    `json:"wrap,omitempty"`
+	Facts    []*Fact `json:"facts,omitempty"`
+}
+
+type Fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// Action is an Action.OpenUrl button. Incoming webhooks cannot receive
+// anything back from Teams, so buttons can only open links.
+type Action struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// stateColor is the color of the title of alert group cards, as one of the
+// colors adaptive cards name.
+var stateColor = map[alertgroup.State]string{
+	alertgroup.StateFiring:       "attention",
+	alertgroup.StateAcknowledged: "warning",
+	alertgroup.StateSilenced:     "default",
+	alertgroup.StateResolved:     "good",
+}
+
+func newCard(body ...*Element) *Card {
+	return &Card{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: cardVersion,
+		Body:    body,
+		MSTeams: &CardOptions{Width: "Full"},
+	}
+}
+
+func text(s string) *Element {
+	return &Element{Type: "TextBlock", Text: s, Wrap: true}
+}
+
+// alertGroupCard renders the alert group, with status below it when it is
+// set.
+func alertGroupCard(g *alertgroup.AlertGroup, status string) *Card {
+	title := text(fmt.Sprintf("%s #%d %s", chat.StateEmoji[g.State], g.Number, g.Title))
+	title.Weight = "Bolder"
+	title.Size = "Medium"
+	title.Color = stateColor[g.State]
+
+	body := []*Element{title}
+	if g.Message != "" {
+		body = append(body, text(g.Message))
+	}
+
+	body = append(body, &Element{Type: "FactSet", Facts: []*Fact{
+		{Title: "State", Value: string(g.State)},
+		{Title: "Alerts", Value: fmt.Sprint(g.AlertsCount)},
+	}})
+
+	if status != "" {
+		s := text(status)
+		s.IsSubtle = true
+		body = append(body, s)
+	}
+
+	return newCard(body...)
+}
@@ -0,0 +1,64 @@
+package msteams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Client posts to Teams incoming webhooks, either Workflows webhooks or the
+// older Office 365 connectors, which both accept adaptive cards.
+type Client struct {
+	http *http.Client
+}
+
+type message struct {
+	Type        string        `json:"type"`
+	Attachments []*attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string `json:"contentType"`
+	Content     *Card  `json:"content"`
+}
+
+// Post posts the card to the webhook.
+func (c *Client) Post(ctx context.Context, webhookURL string, card *Card) error {
+	body, err := json.Marshal(&message{
+		Type:        "message",
+		Attachments: []*attachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	})
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		// The webhook URL is its credential, so it is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("teams webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("teams webhook: unexpected status %s: %s", res.Status, bytes.TrimSpace(b))
+	}
+
+	return nil
+}
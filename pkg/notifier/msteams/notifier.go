@@ -0,0 +1,82 @@
+package msteams
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+)
+
+// Type is the notifier type, and the type of the contact method holding
+// the incoming webhook URL of a Teams chat or channel users read their
+// notifications in.
+const Type = "msteams"
+
+// Notifier posts adaptive cards to Microsoft Teams incoming webhooks. It is
+// configured by the [notifier.msteams] section.
+type Notifier struct {
+	client *Client
+	// webhookURL is where alert groups are posted.
+	webhookURL string
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+func New(settings map[string]string) notifier.Notifier {
+	return &Notifier{
+		client:     &Client{http: &http.Client{Timeout: 10 * time.Second}},
+		webhookURL: settings["webhook_url"],
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:       notifier.AddressContactMethod,
+		Channel:       true,
+		SecretAddress: true,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	if n.webhookURL == "" {
+		return nil
+	}
+
+	if !validURL(n.webhookURL) {
+		return fmt.Errorf("%w: invalid webhook_url", notifier.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Send posts the message to the incoming webhook URL in address.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	if !validURL(address) {
+		return nil, fmt.Errorf("%w: not a Teams incoming webhook URL", notifier.ErrNoAddress)
+	}
+
+	title := text(m.Title)
+	title.Weight = "Bolder"
+
+	card := newCard(title)
+	if m.Text != "" {
+		card.Body = append(card.Body, text(m.Text))
+	}
+	if m.URL != "" {
+		card.Actions = []*Action{{Type: "Action.OpenUrl", Title: "Open", URL: m.URL}}
+	}
+
+	if err := n.client.Post(ctx, address, card); err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{}, nil
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
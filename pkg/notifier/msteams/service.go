@@ -0,0 +1,95 @@
+package msteams
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// Service posts alert groups to the configured Teams webhook. Cards posted
+// to incoming webhooks cannot be edited, so every change of state is posted
+// as a card of its own.
+type Service struct {
+	notifier    *Notifier
+	notifiers   *notifier.Service
+	alertGroups *alertgroup.Service
+	users       *user.Service
+}
+
+// NewService creates the Teams service, which does nothing unless the Teams
+// notifier is enabled in the registry with a webhook_url.
+func NewService(registry *notifier.Registry, notifiers *notifier.Service, alertGroups *alertgroup.Service, users *user.Service) *Service {
+	s := &Service{
+		notifiers:   notifiers,
+		alertGroups: alertGroups,
+		users:       users,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s.notifier != nil
+}
+
+// HandleAlertGroupCreated posts a new alert group to the configured webhook.
+func (s *Service) HandleAlertGroupCreated(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.post(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		return ""
+	})
+}
+
+// HandleStateChanged posts the alert group to the configured webhook again
+// after it changed state, with who changed it.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	action := alertgroup.Action(job.Args[1])
+	if _, ok := chat.ActionText[action]; !ok {
+		return
+	}
+
+	s.post(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		return chat.Status(ctx, s.alertGroups, g, action, func(actor string) string {
+			return chat.ActorName(ctx, s.users, actor)
+		}, chat.Date)
+	})
+}
+
+// post posts the alert group card with the status returned by status.
+func (s *Service) post(ctx context.Context, alertGroupID string, status func(g *alertgroup.AlertGroup) string) {
+	if !s.Enabled() || s.notifier.webhookURL == "" {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	// The webhook URL is a secret, so deliveries are recorded as posted to
+	// the "webhook" channel rather than to the URL.
+	err = s.notifier.client.Post(ctx, s.notifier.webhookURL, alertGroupCard(g, status(g)))
+	s.notifiers.Record(ctx, Type, "", &notifier.Message{Channel: "webhook", Title: g.Title, AlertGroupID: g.ID}, nil, err)
+	if err != nil {
+		fmt.Printf("Failed to post alert group %s to Teams: %s\n", g.ID, err)
+	}
+}
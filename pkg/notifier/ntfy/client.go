@@ -0,0 +1,85 @@
+package ntfy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Client publishes messages to a ntfy server.
+type Client struct {
+	url string
+	// token is an access token, used instead of username and password when
+	// set.
+	token    string
+	username string
+	password string
+	http     *http.Client
+}
+
+// Publication is a message published to a topic.
+type Publication struct {
+	Topic    string    `json:"topic"`
+	Title    string    `json:"title,omitempty"`
+	Message  string    `json:"message"`
+	Priority int       `json:"priority,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	Click    string    `json:"click,omitempty"`
+	Actions  []*Action `json:"actions,omitempty"`
+}
+
+// Action is a http action button, which makes the ntfy app send a request
+// to the URL when it is pressed.
+type Action struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+	Method string `json:"method,omitempty"`
+	// Clear dismisses the notification after the request succeeded.
+	Clear bool `json:"clear,omitempty"`
+}
+
+type publishResponse struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// Publish publishes the message and returns its ID.
+func (c *Client) Publish(ctx context.Context, p *Publication) (string, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.url, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		r.SetBasicAuth(c.username, c.password)
+	}
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		return "", fmt.Errorf("ntfy publish: %w", err)
+	}
+	defer res.Body.Close()
+
+	var resp publishResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", fmt.Errorf("ntfy publish: unexpected response with status %s", res.Status)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ntfy publish: %s", resp.Error)
+	}
+
+	return resp.ID, nil
+}
@@ -0,0 +1,170 @@
+package ntfy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// Type is the notifier type, and the type of the contact method holding
+// the topic users subscribed to in the ntfy app.
+const Type = "ntfy"
+
+const DefaultServerURL = "https://ntfy.sh"
+
+// ActionsPath is where the action buttons of notifications post to,
+// followed by the action token and the action, relative to the root URL.
+const ActionsPath = "ntfy/actions"
+
+// ActionTokenTTL is how long the action buttons of a notification work.
+const ActionTokenTTL = 7 * 24 * time.Hour
+
+// Priorities of notifications by importance, on the scale of 1 to 5 of ntfy.
+const (
+	priorityDefault   = 4
+	priorityImportant = 5
+)
+
+// maxMessageLength is the longest message ntfy shows in full, longer ones
+// become attachments.
+const maxMessageLength = 4096
+
+var tokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Notifier publishes push notifications to a ntfy server. It is configured
+// by the [notifier.ntfy] section.
+type Notifier struct {
+	store     Store
+	client    *Client
+	serverURL string
+	// rootURL is where the ntfy app reaches the actions endpoint.
+	rootURL string
+	now     func() time.Time
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+// New returns the factory of ntfy notifiers, which save the action tokens
+// of notifications in the store.
+func New(store Store, rootURL string) notifier.Factory {
+	return func(settings map[string]string) notifier.Notifier {
+		serverURL := settings["server_url"]
+		if serverURL == "" {
+			serverURL = DefaultServerURL
+		}
+
+		return &Notifier{
+			store: store,
+			client: &Client{
+				url:      serverURL,
+				token:    settings["access_token"],
+				username: settings["username"],
+				password: settings["password"],
+				http:     &http.Client{Timeout: 10 * time.Second},
+			},
+			serverURL: serverURL,
+			rootURL:   rootURL,
+			now:       time.Now,
+		}
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:       notifier.AddressContactMethod,
+		Actions:       true,
+		MaxLength:     maxMessageLength,
+		SecretAddress: true,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	u, err := url.Parse(n.serverURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid server_url %q", notifier.ErrInvalidConfig, n.serverURL)
+	}
+
+	if n.client.username != "" && n.client.token != "" {
+		return fmt.Errorf("%w: set either access_token or username and password", notifier.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Send publishes the message to the topic in address, with buttons to
+// acknowledge and resolve the alert group it is about.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	p := &Publication{
+		Topic:    address,
+		Title:    m.Title,
+		Message:  m.Text,
+		Priority: priorityDefault,
+		Tags:     []string{"rotating_light"},
+		Click:    m.URL,
+	}
+
+	if p.Message == "" {
+		p.Message = m.Title
+	}
+
+	if m.Importance == string(notificationpolicy.ImportanceImportant) {
+		p.Priority = priorityImportant
+	}
+
+	if m.AlertGroupID != "" {
+		token, err := n.actionToken(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range []*chat.Action{
+			{ID: chat.ActionAcknowledge, Label: "Acknowledge"},
+			{ID: chat.ActionResolve, Label: "Resolve"},
+		} {
+			p.Actions = append(p.Actions, &Action{
+				Action: "http",
+				Label:  a.Label,
+				URL:    n.rootURL + ActionsPath + "/" + token + "/" + a.ID,
+				Method: http.MethodPost,
+				Clear:  true,
+			})
+		}
+	}
+
+	id, err := n.client.Publish(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: id}, nil
+}
+
+// actionToken saves an action token for the notification.
+func (n *Notifier) actionToken(ctx context.Context, m *notifier.Message) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate action token: %w", err)
+	}
+
+	t := &ActionToken{
+		Token:        strings.ToLower(tokenEncoding.EncodeToString(b)),
+		AlertGroupID: m.AlertGroupID,
+		UserID:       m.UserID,
+		ExpiresAt:    n.now().Add(ActionTokenTTL),
+	}
+
+	if err := n.store.SaveActionToken(ctx, t); err != nil {
+		return "", err
+	}
+
+	return t.Token, nil
+}
@@ -0,0 +1,67 @@
+package ntfy
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid or expired ntfy action token")
+	ErrUnknownAction = errors.New("unknown action, expected acknowledge or resolve")
+)
+
+// Service applies the actions of the buttons on ntfy notifications.
+type Service struct {
+	store       Store
+	notifier    *Notifier
+	alertGroups *alertgroup.Service
+	now         func() time.Time
+}
+
+// NewService creates the ntfy service, which does nothing unless the ntfy
+// notifier is enabled in the registry.
+func NewService(store Store, registry *notifier.Registry, alertGroups *alertgroup.Service) *Service {
+	s := &Service{
+		store:       store,
+		alertGroups: alertGroups,
+		now:         time.Now,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s.notifier != nil
+}
+
+// Action applies the action of a button on the notification the token was
+// created for, as the notified user, and returns the updated alert group.
+func (s *Service) Action(ctx context.Context, token, action string) (*alertgroup.AlertGroup, error) {
+	t, err := s.store.GetActionToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil || !s.now().Before(t.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	if action != chat.ActionAcknowledge && action != chat.ActionResolve {
+		return nil, ErrUnknownAction
+	}
+
+	if err := chat.Apply(ctx, s.alertGroups, nil, t.AlertGroupID, action, 0, t.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.alertGroups.Get(ctx, t.AlertGroupID)
+}
@@ -0,0 +1,89 @@
+package ntfy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// nopWorker drops the jobs published instead of sending them anywhere.
+type nopWorker struct{}
+
+func (nopWorker) Enqueue(ctx context.Context, t worker.JobType, args ...string) error {
+	return nil
+}
+
+func (nopWorker) EnqueueIn(ctx context.Context, delay time.Duration, t worker.JobType, args ...string) error {
+	return nil
+}
+
+func (nopWorker) RegisterHandler(worker.JobType, worker.JobHandler, any) {}
+
+func (nopWorker) Stop(ctx context.Context) {}
+
+func TestAction(t *testing.T) {
+	now := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		token     string
+		action    string
+		expiresAt time.Time
+		wantErr   error
+		wantState alertgroup.State
+	}{
+		{name: "acknowledge", token: "t1", action: chat.ActionAcknowledge, expiresAt: now.Add(time.Hour), wantState: alertgroup.StateAcknowledged},
+		{name: "resolve", token: "t1", action: chat.ActionResolve, expiresAt: now.Add(time.Hour), wantState: alertgroup.StateResolved},
+		{name: "unknown token", token: "t2", action: chat.ActionAcknowledge, expiresAt: now.Add(time.Hour), wantErr: ErrInvalidToken, wantState: alertgroup.StateFiring},
+		{name: "expired token", token: "t1", action: chat.ActionAcknowledge, expiresAt: now, wantErr: ErrInvalidToken, wantState: alertgroup.StateFiring},
+		{name: "unknown action", token: "t1", action: chat.ActionSilence, expiresAt: now.Add(time.Hour), wantErr: ErrUnknownAction, wantState: alertgroup.StateFiring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := sqlstore.InitTestDB(t)
+
+			groups, err := alertgroup.NewSQLStore(db)
+			if err != nil {
+				t.Fatalf("alertgroup.NewSQLStore() error = %v", err)
+			}
+			alertGroups := alertgroup.NewService(groups, nopWorker{})
+
+			store, err := NewSQLStore(db)
+			if err != nil {
+				t.Fatalf("NewSQLStore() error = %v", err)
+			}
+
+			s := &Service{store: store, alertGroups: alertGroups, now: func() time.Time { return now }}
+
+			g := &alertgroup.AlertGroup{IntegrationID: "i", Title: "disk full"}
+			if err := alertGroups.Create(ctx, g); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if err := store.SaveActionToken(ctx, &ActionToken{Token: "t1", AlertGroupID: g.ID, UserID: "alice", ExpiresAt: tt.expiresAt}); err != nil {
+				t.Fatalf("SaveActionToken() error = %v", err)
+			}
+
+			_, err = s.Action(ctx, tt.token, tt.action)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Action() error = %v, want %v", err, tt.wantErr)
+			}
+
+			got, err := alertGroups.Get(ctx, g.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got.State != tt.wantState {
+				t.Errorf("state = %s, want %s", got.State, tt.wantState)
+			}
+		})
+	}
+}
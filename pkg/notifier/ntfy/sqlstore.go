@@ -0,0 +1,38 @@
+package ntfy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps action tokens in the SQL database, which the server and
+// workers share.
+type SQLStore struct {
+	tokens *sqlstore.Table[ActionToken]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	tokens, err := sqlstore.NewTable(db, "ntfy_action_tokens", func(t *ActionToken) string { return t.Token })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{tokens: tokens}, nil
+}
+
+func (st *SQLStore) SaveActionToken(ctx context.Context, t *ActionToken) error {
+	return st.tokens.Save(ctx, t)
+}
+
+func (st *SQLStore) GetActionToken(ctx context.Context, token string) (*ActionToken, error) {
+	t, err := st.tokens.Get(ctx, token)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return t, err
+}
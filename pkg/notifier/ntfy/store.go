@@ -0,0 +1,21 @@
+package ntfy
+
+import (
+	"context"
+	"time"
+)
+
+// ActionToken authenticates the action buttons of a notification as the
+// notified user. It is part of the URLs the buttons post to.
+type ActionToken struct {
+	Token        string
+	AlertGroupID string
+	UserID       string
+	ExpiresAt    time.Time
+}
+
+type Store interface {
+	SaveActionToken(ctx context.Context, t *ActionToken) error
+	// GetActionToken returns the token, or nil if there is no such token.
+	GetActionToken(ctx context.Context, token string) (*ActionToken, error)
+}
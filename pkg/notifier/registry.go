@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/setting"
+)
+
+// Registry holds the notifier factories by channel type, and the notifiers
+// enabled in the configuration.
+type Registry struct {
+	factories map[string]Factory
+	notifiers map[string]Notifier
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+		notifiers: make(map[string]Notifier),
+	}
+}
+
+// Register makes a type of notifier available to be enabled.
+func (r *Registry) Register(t string, f Factory) {
+	r.factories[t] = f
+}
+
+// Configure creates the notifiers enabled in the settings and validates
+// their configuration.
+func (r *Registry) Configure(settings map[string]*setting.NotifierSettings) error {
+	for t, ns := range settings {
+		if !ns.Enabled {
+			continue
+		}
+
+		f, ok := r.factories[t]
+		if !ok {
+			return fmt.Errorf("unknown notifier %q", t)
+		}
+
+		n := f(ns.Settings)
+		if err := n.ValidateConfig(); err != nil {
+			return fmt.Errorf("notifier %s: %w", t, err)
+		}
+
+		r.notifiers[t] = n
+	}
+
+	return nil
+}
+
+// Get returns the enabled notifier of the type.
+func (r *Registry) Get(t string) (Notifier, error) {
+	n, ok := r.notifiers[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotifierNotEnabled, t)
+	}
+
+	return n, nil
+}
+
+// Types returns the types of the enabled notifiers in order.
+func (r *Registry) Types() []string {
+	result := make([]string, 0, len(r.notifiers))
+	for t := range r.notifiers {
+		result = append(result, t)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
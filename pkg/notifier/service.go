@@ -0,0 +1,221 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+// JobNotify sends a notification, with the notifier type and the JSON
+// encoded message as arguments.
+const JobNotify worker.JobType = "notify"
+
+type Service struct {
+	store    Store
+	registry *Registry
+	users    *user.Service
+	worker   worker.Worker
+	now      func() time.Time
+}
+
+func NewService(store Store, registry *Registry, users *user.Service, w worker.Worker) *Service {
+	return &Service{
+		store:    store,
+		registry: registry,
+		users:    users,
+		worker:   w,
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether the notifier of the type is enabled.
+func (s *Service) Enabled(t string) bool {
+	_, err := s.registry.Get(t)
+	return err == nil
+}
+
+// Notifiers returns the capabilities of the enabled notifiers by type.
+func (s *Service) Notifiers() map[string]Capabilities {
+	result := make(map[string]Capabilities)
+	for _, t := range s.registry.Types() {
+		n, _ := s.registry.Get(t)
+		result[t] = n.Capabilities()
+	}
+
+	return result
+}
+
+// Notify queues the message to be sent by the notifier of the type. Failures
+// to send it are recorded as deliveries rather than returned, failures to
+// queue it are both recorded and returned.
+func (s *Service) Notify(ctx context.Context, t string, m *Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if err := s.worker.Enqueue(ctx, JobNotify, t, string(data)); err != nil {
+		s.Record(ctx, t, "", m, nil, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) Deliveries(ctx context.Context, alertGroupID string) ([]*Delivery, error) {
+	return s.store.ListDeliveries(ctx, alertGroupID)
+}
+
+// UpdateDelivery records the status of a delivery reported later by the
+// service behind the notifier of the type, such as whether a call was
+// answered.
+func (s *Service) UpdateDelivery(ctx context.Context, t, externalID string, status Status, reason string) error {
+	d, err := s.store.GetDeliveryByExternalID(ctx, t, externalID)
+	if err != nil {
+		return err
+	}
+
+	d.Status = status
+	d.Error = reason
+	d.UpdatedAt = s.now()
+
+	return s.store.UpdateDelivery(ctx, d)
+}
+
+func (s *Service) HandleNotify(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	var m Message
+	if err := json.Unmarshal([]byte(job.Args[1]), &m); err != nil {
+		fmt.Printf("Invalid %s job %s: %s\n", job.Type, job.ID, err)
+		return
+	}
+
+	address, res, err := s.deliver(ctx, job.Args[0], &m)
+	if err != nil {
+		fmt.Printf("Failed to send %s notification: %s\n", job.Args[0], err)
+	}
+
+	s.Record(ctx, job.Args[0], address, &m, res, err)
+}
+
+// Record records the outcome of sending the message to the address with the
+// notifier of the type. Notifications sent by Notify are recorded already,
+// this records those sent by chat integrations, such as alert groups posted
+// to channels.
+func (s *Service) Record(ctx context.Context, t, address string, m *Message, res *Result, err error) {
+	now := s.now()
+	d := &Delivery{
+		ID:           uuid.NewString(),
+		Type:         t,
+		UserID:       m.UserID,
+		Channel:      m.Channel,
+		Address:      address,
+		AlertGroupID: m.AlertGroupID,
+		Title:        m.Title,
+		Status:       StatusSent,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if n, err := s.registry.Get(t); err == nil && n.Capabilities().SecretAddress {
+		d.Address = redact(d.Address)
+		d.Channel = redact(d.Channel)
+	}
+
+	if err != nil {
+		d.Status = StatusFailed
+		d.Error = err.Error()
+	} else if res != nil {
+		d.ExternalID = res.ExternalID
+	}
+
+	if err := s.store.SaveDelivery(ctx, d); err != nil {
+		fmt.Printf("Failed to record %s notification: %s\n", t, err)
+	}
+}
+
+// deliver sends the message with the notifier of the type, and returns the
+// address it was sent to.
+func (s *Service) deliver(ctx context.Context, t string, m *Message) (string, *Result, error) {
+	n, err := s.registry.Get(t)
+	if err != nil {
+		return "", nil, err
+	}
+
+	caps := n.Capabilities()
+
+	address := m.Channel
+	if m.Channel != "" {
+		if !caps.Channel {
+			return address, nil, fmt.Errorf("%w: %s cannot post to channels", ErrUnsupported, t)
+		}
+	} else {
+		if address, err = s.address(ctx, t, caps.Address, m.UserID); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if caps.MaxLength > 0 {
+		m.Text = truncate(m.Text, caps.MaxLength)
+	}
+
+	res, err := n.Send(ctx, address, m)
+
+	return address, res, err
+}
+
+// address returns the address of the user that notifiers of the kind send
+// to.
+func (s *Service) address(ctx context.Context, t string, kind AddressKind, userID string) (string, error) {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	var address string
+	switch kind {
+	case AddressPhone:
+		if u.PhoneVerified {
+			address = u.Phone
+		}
+	case AddressEmail:
+		address = u.Email
+	case AddressContactMethod:
+		if cm := u.ContactMethod(t); cm != nil && cm.Trusted() {
+			address = cm.Address
+		}
+	}
+
+	if address == "" {
+		return "", fmt.Errorf("%w: user %s has no %s address", ErrNoAddress, u.Username, t)
+	}
+
+	return address, nil
+}
+
+// redact hides all but the last characters of a secret address.
+func redact(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return "****" + s[len(s)-4:]
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,121 @@
+package signal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls a signal-cli REST API, sending and receiving messages as the
+// registered number.
+type Client struct {
+	url    string
+	number string
+	http   *http.Client
+}
+
+type sendRequest struct {
+	Message    string   `json:"message"`
+	Number     string   `json:"number"`
+	Recipients []string `json:"recipients"`
+}
+
+type sendResponse struct {
+	Timestamp string `json:"timestamp"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Envelope is a message received by the registered number.
+type Envelope struct {
+	Source       string       `json:"source"`
+	SourceNumber string       `json:"sourceNumber"`
+	Timestamp    int64        `json:"timestamp"`
+	DataMessage  *DataMessage `json:"dataMessage"`
+}
+
+type DataMessage struct {
+	Message   string     `json:"message"`
+	GroupInfo *GroupInfo `json:"groupInfo"`
+}
+
+type GroupInfo struct {
+	GroupID string `json:"groupId"`
+}
+
+type received struct {
+	Envelope *Envelope `json:"envelope"`
+}
+
+// Send sends the message to phone numbers or groups, and returns its
+// timestamp, which identifies it in Signal.
+func (c *Client) Send(ctx context.Context, recipients []string, message string) (string, error) {
+	body, err := json.Marshal(&sendRequest{
+		Message:    message,
+		Number:     c.number,
+		Recipients: recipients,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var res sendResponse
+	if err := c.do(ctx, http.MethodPost, "/v2/send", bytes.NewReader(body), &res); err != nil {
+		return "", err
+	}
+
+	return res.Timestamp, nil
+}
+
+// Receive returns the messages received since it was last called.
+func (c *Client) Receive(ctx context.Context) ([]*Envelope, error) {
+	var res []*received
+	if err := c.do(ctx, http.MethodGet, "/v1/receive/"+url.PathEscape(c.number), nil, &res); err != nil {
+		return nil, err
+	}
+
+	envelopes := make([]*Envelope, 0, len(res))
+	for _, r := range res {
+		if r.Envelope != nil {
+			envelopes = append(envelopes, r.Envelope)
+		}
+	}
+
+	return envelopes, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, result any) error {
+	r, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.url, "/")+path, body)
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		return fmt.Errorf("signal: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.NewDecoder(res.Body).Decode(&e); err == nil && e.Error != "" {
+			return fmt.Errorf("signal: %s", e.Error)
+		}
+		return fmt.Errorf("signal: unexpected response with status %s", res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("signal: invalid response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package signal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+)
+
+const Type = "signal"
+
+const DefaultPollInterval = 10 * time.Second
+
+// maxTextLength keeps messages short enough to read on a phone.
+const maxTextLength = 2000
+
+// Notifier sends Signal messages through a signal-cli REST API. It is
+// configured by the [notifier.signal] section.
+type Notifier struct {
+	client       *Client
+	apiURL       string
+	number       string
+	groupID      string
+	pollInterval time.Duration
+	// pollIntervalErr is reported by ValidateConfig.
+	pollIntervalErr error
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+func New(settings map[string]string) notifier.Notifier {
+	n := &Notifier{
+		client: &Client{
+			url:    settings["api_url"],
+			number: settings["number"],
+			http:   &http.Client{Timeout: 10 * time.Second},
+		},
+		apiURL:       settings["api_url"],
+		number:       settings["number"],
+		groupID:      settings["group_id"],
+		pollInterval: DefaultPollInterval,
+	}
+
+	if v := settings["poll_interval"]; v != "" {
+		n.pollInterval, n.pollIntervalErr = time.ParseDuration(v)
+	}
+
+	return n
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:   notifier.AddressPhone,
+		Channel:   true,
+		Actions:   true,
+		MaxLength: maxTextLength,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	u, err := url.Parse(n.apiURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid api_url %q", notifier.ErrInvalidConfig, n.apiURL)
+	}
+
+	if !strings.HasPrefix(n.number, "+") {
+		return fmt.Errorf("%w: number must be the registered phone number in E.164 format", notifier.ErrInvalidConfig)
+	}
+
+	if n.groupID != "" && !strings.HasPrefix(n.groupID, "group.") {
+		return fmt.Errorf("%w: group_id must be a group ID starting with group.", notifier.ErrInvalidConfig)
+	}
+
+	if n.pollIntervalErr != nil || n.pollInterval <= 0 {
+		return fmt.Errorf("%w: invalid poll_interval", notifier.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// isGroup reports whether the group ID of a received message is the
+// configured group. Received messages carry the internal ID of the group,
+// which the REST API encodes in base64 after the group. prefix.
+func (n *Notifier) isGroup(id string) bool {
+	if n.groupID == "" || id == "" {
+		return false
+	}
+
+	return n.groupID == id ||
+		n.groupID == "group."+id ||
+		n.groupID == "group."+base64.StdEncoding.EncodeToString([]byte(id))
+}
+
+// Send sends the message to a phone number or group, telling the recipient
+// how to reply to acknowledge or resolve the alert group it is about.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	text := m.Title
+	if m.Text != "" {
+		text += "\n" + m.Text
+	}
+	if m.URL != "" {
+		text += "\n" + m.URL
+	}
+	if m.AlertGroupNumber > 0 {
+		text += "\n\n" + replyHint(m.AlertGroupNumber)
+	}
+
+	ts, err := n.client.Send(ctx, []string{address}, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: ts}, nil
+}
+
+func replyHint(number int) string {
+	return fmt.Sprintf("Reply \"ack %d\" to acknowledge or \"resolve %d\" to resolve.", number, number)
+}
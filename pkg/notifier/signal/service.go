@@ -0,0 +1,297 @@
+package signal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+const commandHelp = "Reply with:\n" +
+	"list - the alert groups that are not resolved\n" +
+	"ack <number> - acknowledge an alert group\n" +
+	"unack <number> - unacknowledge an alert group\n" +
+	"resolve <number> - resolve an alert group\n" +
+	"unresolve <number> - unresolve an alert group\n" +
+	"silence <number> [duration] - silence an alert group, such as for 2h\n" +
+	"unsilence <number> - unsilence an alert group"
+
+// maxListed limits the alert groups listed by the list command.
+const maxListed = 20
+
+var ErrNotLinked = errors.New("this number is not the verified phone number of an OnCall user")
+
+// commandActions maps the words of replies to the actions they take.
+var commandActions = map[string]string{
+	"ack":           chat.ActionAcknowledge,
+	"acknowledge":   chat.ActionAcknowledge,
+	"unack":         chat.ActionUnacknowledge,
+	"unacknowledge": chat.ActionUnacknowledge,
+	"resolve":       chat.ActionResolve,
+	"unresolve":     chat.ActionUnresolve,
+	"silence":       chat.ActionSilence,
+	"unsilence":     chat.ActionUnsilence,
+}
+
+// Service posts alert groups to the configured Signal group and applies the
+// actions users reply with, such as "ack 1234".
+type Service struct {
+	notifier    *Notifier
+	notifiers   *notifier.Service
+	alertGroups *alertgroup.Service
+	users       *user.Service
+	now         func() time.Time
+}
+
+// NewService creates the Signal service, which does nothing unless the
+// Signal notifier is enabled in the registry.
+func NewService(registry *notifier.Registry, notifiers *notifier.Service, alertGroups *alertgroup.Service, users *user.Service) *Service {
+	s := &Service{
+		notifiers:   notifiers,
+		alertGroups: alertGroups,
+		users:       users,
+		now:         time.Now,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s.notifier != nil
+}
+
+// HandleAlertGroupCreated posts a new alert group to the configured group.
+func (s *Service) HandleAlertGroupCreated(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.post(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		text := fmt.Sprintf("#%d %s", g.Number, g.Title)
+		if g.Message != "" {
+			text += "\n" + g.Message
+		}
+
+		return text + "\n\n" + replyHint(g.Number)
+	})
+}
+
+// HandleStateChanged tells the configured group who changed the state of an
+// alert group.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	s.post(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		status := chat.Status(ctx, s.alertGroups, g, alertgroup.Action(job.Args[1]), func(actor string) string {
+			return chat.ActorName(ctx, s.users, actor)
+		}, chat.Date)
+		if status == "" {
+			return ""
+		}
+
+		return fmt.Sprintf("#%d %s: %s", g.Number, g.Title, status)
+	})
+}
+
+// post sends the text returned by render to the configured group, unless it
+// is empty.
+func (s *Service) post(ctx context.Context, alertGroupID string, render func(g *alertgroup.AlertGroup) string) {
+	if !s.Enabled() || s.notifier.groupID == "" {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	text := render(g)
+	if text == "" {
+		return
+	}
+
+	ts, err := s.notifier.client.Send(ctx, []string{s.notifier.groupID}, text)
+	s.notifiers.Record(ctx, Type, s.notifier.groupID, &notifier.Message{Channel: s.notifier.groupID, Title: g.Title, AlertGroupID: g.ID}, &notifier.Result{ExternalID: ts}, err)
+	if err != nil {
+		fmt.Printf("Failed to post alert group %s to Signal: %s\n", g.ID, err)
+	}
+}
+
+// Receive fetches the messages sent to the registered number and replies to
+// each with the result of the command it contains. Replies to commands sent
+// in the configured group go to the group, and messages in other groups are
+// ignored.
+func (s *Service) Receive(ctx context.Context) error {
+	envelopes, err := s.notifier.client.Receive(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range envelopes {
+		if e.DataMessage == nil || strings.TrimSpace(e.DataMessage.Message) == "" {
+			continue
+		}
+
+		sender := e.SourceNumber
+		if sender == "" {
+			sender = e.Source
+		}
+
+		recipient := sender
+		if e.DataMessage.GroupInfo != nil {
+			if !s.notifier.isGroup(e.DataMessage.GroupInfo.GroupID) || !isCommand(e.DataMessage.Message) {
+				continue
+			}
+			recipient = s.notifier.groupID
+		}
+
+		reply := s.command(ctx, sender, e.DataMessage.Message)
+		if _, err := s.notifier.client.Send(ctx, []string{recipient}, reply); err != nil {
+			fmt.Printf("Failed to reply to Signal message from %s: %s\n", sender, err)
+		}
+	}
+
+	return nil
+}
+
+// command runs the command in a message sent from the phone number and
+// returns the reply. Only OnCall users may run commands, even those which
+// change nothing, so the number must be the verified phone number of one.
+func (s *Service) command(ctx context.Context, phone, text string) string {
+	args := strings.Fields(text)
+
+	u, err := s.users.GetByPhone(ctx, phone)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return ErrNotLinked.Error()
+	}
+	if err != nil {
+		return err.Error()
+	}
+
+	switch name := strings.ToLower(args[0]); name {
+	case "help":
+		return commandHelp
+	case "list":
+		reply, err := s.listCommand(ctx)
+		if err != nil {
+			return err.Error()
+		}
+		return reply
+	default:
+		action, ok := commandActions[name]
+		if !ok {
+			return fmt.Sprintf("Unknown command %q\n\n%s", args[0], commandHelp)
+		}
+
+		reply, err := s.actionCommand(ctx, u, action, args[1:])
+		if err != nil {
+			return err.Error()
+		}
+		return reply
+	}
+}
+
+func isCommand(text string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(text)), " ")
+	_, ok := commandActions[name]
+	return ok || name == "help" || name == "list"
+}
+
+func (s *Service) listCommand(ctx context.Context) (string, error) {
+	page, err := s.alertGroups.Search(ctx, &alertgroup.Query{
+		States:     []alertgroup.State{alertgroup.StateFiring, alertgroup.StateAcknowledged, alertgroup.StateSilenced},
+		Descending: true,
+		Limit:      maxListed,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(page.AlertGroups) == 0 {
+		return "No alert groups need attention", nil
+	}
+
+	var b strings.Builder
+	for _, g := range page.AlertGroups {
+		fmt.Fprintf(&b, "#%d %s (%s, %d alerts)\n", g.Number, g.Title, g.State, g.AlertsCount)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func (s *Service) actionCommand(ctx context.Context, u *user.User, action string, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing alert group number, reply \"list\" to see them")
+	}
+
+	number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return "", fmt.Errorf("invalid alert group number %q", args[0])
+	}
+
+	g, err := s.alertGroups.GetByNumber(ctx, number)
+	if err != nil {
+		return "", err
+	}
+
+	var silence time.Duration
+	if action == chat.ActionSilence && len(args) > 1 {
+		if silence, err = time.ParseDuration(args[1]); err != nil || silence < 0 {
+			return "", fmt.Errorf("invalid duration %q, such as 30m or 2h", args[1])
+		}
+	}
+
+	// There is no escalate command, so no escalations to apply it with.
+	if err := chat.Apply(ctx, s.alertGroups, nil, g.ID, action, silence, u.ID); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s #%d %s", chat.ActionText[alertgroup.Action(action)], g.Number, g.Title), nil
+}
+
+// Poller periodically receives the messages sent to the registered number.
+type Poller struct {
+	service *Service
+}
+
+func NewPoller(service *Service) *Poller {
+	return &Poller{service: service}
+}
+
+func (p *Poller) Run(ctx context.Context) error {
+	if !p.service.Enabled() {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(p.service.notifier.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := p.service.Receive(ctx); err != nil {
+				fmt.Printf("Failed to receive Signal messages: %s\n", err)
+			}
+		}
+	}
+}
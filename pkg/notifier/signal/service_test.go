@@ -0,0 +1,59 @@
+package signal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/user"
+)
+
+func TestCommandRequiresLinkedNumber(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := user.NewSQLStore(sqlstore.InitTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+	users := user.NewService(store, nil, nil)
+
+	alice := &user.User{Username: "alice", Phone: "+14155550100", TimeZone: "UTC", Role: user.RoleEditor}
+	if err := users.Create(ctx, alice); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Phone numbers are verified with a code sent by SMS, which the store
+	// is told of directly here.
+	alice.PhoneVerified = true
+	if err := store.Update(ctx, alice); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	bob := &user.User{Username: "bob", Phone: "+14155550101", TimeZone: "UTC", Role: user.RoleEditor}
+	if err := users.Create(ctx, bob); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	s := &Service{users: users}
+
+	tests := []struct {
+		name  string
+		phone string
+		text  string
+		want  string
+	}{
+		{name: "help from a linked number", phone: alice.Phone, text: "help", want: commandHelp},
+		{name: "help from an unknown number", phone: "+14155550199", text: "help", want: ErrNotLinked.Error()},
+		{name: "list from an unknown number", phone: "+14155550199", text: "list", want: ErrNotLinked.Error()},
+		{name: "ack from an unknown number", phone: "+14155550199", text: "ack 1", want: ErrNotLinked.Error()},
+		{name: "help from an unverified number", phone: bob.Phone, text: "help", want: ErrNotLinked.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.command(ctx, tt.phone, tt.text); got != tt.want {
+				t.Errorf("command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
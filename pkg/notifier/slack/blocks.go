@@ -0,0 +1,126 @@
+package slack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// Block is a Slack Block Kit layout block.
+type Block struct {
+	Type    string `json:"type"`
+	BlockID string `json:"block_id,omitempty"`
+	Text    *Text  `json:"text,omitempty"`
+	// Elements are Text for context blocks, and Button or Select for
+	// actions blocks.
+	Elements []any `json:"elements,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Button struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text"`
+	ActionID string `json:"action_id"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+type Select struct {
+	Type        string    `json:"type"`
+	Placeholder *Text     `json:"placeholder"`
+	ActionID    string    `json:"action_id"`
+	Options     []*Option `json:"options"`
+}
+
+type Option struct {
+	Text  *Text  `json:"text"`
+	Value string `json:"value"`
+}
+
+var stateEmoji = map[alertgroup.State]string{
+	alertgroup.StateFiring:       ":red_circle:",
+	alertgroup.StateAcknowledged: ":large_orange_circle:",
+	alertgroup.StateSilenced:     ":white_circle:",
+	alertgroup.StateResolved:     ":large_green_circle:",
+}
+
+func plain(s string) *Text {
+	return &Text{Type: "plain_text", Text: s}
+}
+
+func markdown(s string) *Text {
+	return &Text{Type: "mrkdwn", Text: s}
+}
+
+func button(label, actionID, style string) *Button {
+	return &Button{Type: "button", Text: plain(label), ActionID: actionID, Value: actionID, Style: style}
+}
+
+// alertGroupText is the fallback text of alert group messages, shown in
+// notifications.
+func alertGroupText(g *alertgroup.AlertGroup) string {
+	return fmt.Sprintf("[%s] %s", g.State, g.Title)
+}
+
+// alertGroupBlocks renders the alert group with the actions that apply to
+// its state. status describes who last changed it, if anyone.
+func alertGroupBlocks(g *alertgroup.AlertGroup, status string) []*Block {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s *%s*", stateEmoji[g.State], escape(g.Title))
+	if g.Message != "" {
+		fmt.Fprintf(&b, "\n%s", escape(g.Message))
+	}
+
+	context := fmt.Sprintf("State: *%s* · Alerts: *%d*", g.State, g.AlertsCount)
+	if status != "" {
+		context += " · " + status
+	}
+
+	blocks := []*Block{
+		{Type: "section", Text: markdown(b.String())},
+		{Type: "context", Elements: []any{markdown(context)}},
+	}
+
+	if actions := alertGroupActions(g); len(actions) > 0 {
+		// The block ID carries the alert group to the interactivity
+		// endpoint.
+		blocks = append(blocks, &Block{Type: "actions", BlockID: g.ID, Elements: actions})
+	}
+
+	return blocks
+}
+
+func alertGroupActions(g *alertgroup.AlertGroup) []any {
+	var elements []any
+	for _, a := range chat.Actions(g) {
+		if a.ID != chat.ActionSilence {
+			elements = append(elements, button(a.Label, a.ID, string(a.Style)))
+			continue
+		}
+
+		silence := &Select{
+			Type:        "static_select",
+			Placeholder: plain(a.Label),
+			ActionID:    a.ID,
+		}
+		for _, o := range chat.SilenceOptions {
+			silence.Options = append(silence.Options, &Option{Text: plain(o.Label), Value: strconv.Itoa(int(o.Duration.Seconds()))})
+		}
+		elements = append(elements, silence)
+	}
+
+	return elements
+}
+
+// escape escapes the characters Slack treats as control sequences in
+// message text.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,124 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Client calls the Slack Web API.
+type Client struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type postMessageRequest struct {
+	Channel  string   `json:"channel"`
+	Text     string   `json:"text"`
+	Blocks   []*Block `json:"blocks,omitempty"`
+	ThreadTS string   `json:"thread_ts,omitempty"`
+	User     string   `json:"user,omitempty"`
+}
+
+type postMessageResponse struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+type updateMessageRequest struct {
+	Channel string   `json:"channel"`
+	TS      string   `json:"ts"`
+	Text    string   `json:"text"`
+	Blocks  []*Block `json:"blocks"`
+}
+
+// PostMessage posts to the channel, or to the thread of the message with
+// the timestamp threadTS when it is set, and returns the channel ID and the
+// timestamp of the new message.
+func (c *Client) PostMessage(ctx context.Context, channel, threadTS, text string, blocks []*Block) (string, string, error) {
+	var resp postMessageResponse
+	err := c.call(ctx, "chat.postMessage", &postMessageRequest{
+		Channel:  channel,
+		Text:     text,
+		Blocks:   blocks,
+		ThreadTS: threadTS,
+	}, &resp)
+	if err != nil {
+		return "", "", err
+	}
+
+	return resp.Channel, resp.TS, nil
+}
+
+// PostEphemeral posts a message to the channel that only the user can see.
+func (c *Client) PostEphemeral(ctx context.Context, channel, userID, text string) error {
+	return c.call(ctx, "chat.postEphemeral", &postMessageRequest{
+		Channel: channel,
+		Text:    text,
+		User:    userID,
+	}, nil)
+}
+
+// UpdateMessage replaces the message with the timestamp in the channel.
+func (c *Client) UpdateMessage(ctx context.Context, channel, ts, text string, blocks []*Block) error {
+	return c.call(ctx, "chat.update", &updateMessageRequest{
+		Channel: channel,
+		TS:      ts,
+		Text:    text,
+		Blocks:  blocks,
+	}, nil)
+}
+
+func (c *Client) call(ctx context.Context, method string, req, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.url, "/")+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("Authorization", "Bearer "+c.token)
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s: unexpected status %s", method, res.Status)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+
+	var status apiResponse
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+
+	if !status.OK {
+		return fmt.Errorf("slack %s: %s", method, status.Error)
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	return json.Unmarshal(raw, resp)
+}
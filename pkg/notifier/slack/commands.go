@@ -0,0 +1,176 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/user"
+)
+
+const commandHelp = "Usage:\n" +
+	"`/oncall list` lists the alert groups that are not resolved\n" +
+	"`/oncall who [schedule]` shows who is on call\n" +
+	"`/oncall ack <alert group>` acknowledges an alert group\n" +
+	"`/oncall unack <alert group>` unacknowledges an alert group\n" +
+	"`/oncall resolve <alert group>` resolves an alert group\n" +
+	"`/oncall unresolve <alert group>` unresolves an alert group\n" +
+	"`/oncall silence <alert group> [duration]` silences an alert group, such as for 2h\n" +
+	"`/oncall unsilence <alert group>` unsilences an alert group\n" +
+	"`/oncall escalate <alert group>` runs the next escalation step now"
+
+// maxListed limits the alert groups listed by /oncall list.
+const maxListed = 20
+
+// commandActions maps the slash command words to the actions they take.
+var commandActions = map[string]string{
+	"ack":           chat.ActionAcknowledge,
+	"acknowledge":   chat.ActionAcknowledge,
+	"unack":         chat.ActionUnacknowledge,
+	"unacknowledge": chat.ActionUnacknowledge,
+	"resolve":       chat.ActionResolve,
+	"unresolve":     chat.ActionUnresolve,
+	"silence":       chat.ActionSilence,
+	"unsilence":     chat.ActionUnsilence,
+	"escalate":      chat.ActionEscalate,
+}
+
+// CommandResponse is the reply to a slash command.
+type CommandResponse struct {
+	// ResponseType is ephemeral for replies only the user sees, or
+	// in_channel.
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// Command runs the /oncall slash command sent by the Slack user.
+func (s *Service) Command(ctx context.Context, slackUserID, text string) *CommandResponse {
+	args := strings.Fields(text)
+	if len(args) == 0 {
+		return ephemeral(commandHelp)
+	}
+
+	var (
+		reply string
+		err   error
+	)
+
+	switch name := strings.ToLower(args[0]); name {
+	case "help":
+		reply = commandHelp
+	case "list":
+		reply, err = s.listCommand(ctx)
+	case "who":
+		reply, err = s.whoCommand(ctx, strings.Join(args[1:], " "))
+	default:
+		action, ok := commandActions[name]
+		if !ok {
+			return ephemeral(fmt.Sprintf("Unknown command %q, try `/oncall help`", args[0]))
+		}
+		reply, err = s.actionCommand(ctx, slackUserID, action, args[1:])
+	}
+
+	if err != nil {
+		return ephemeral(err.Error())
+	}
+
+	return ephemeral(reply)
+}
+
+func (s *Service) listCommand(ctx context.Context) (string, error) {
+	page, err := s.alertGroups.Search(ctx, &alertgroup.Query{
+		States:     []alertgroup.State{alertgroup.StateFiring, alertgroup.StateAcknowledged, alertgroup.StateSilenced},
+		Descending: true,
+		Limit:      maxListed,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(page.AlertGroups) == 0 {
+		return "No alert groups need attention", nil
+	}
+
+	var b strings.Builder
+	for _, g := range page.AlertGroups {
+		fmt.Fprintf(&b, "%s `%s` %s (%d alerts)\n", stateEmoji[g.State], g.ID, escape(g.Title), g.AlertsCount)
+	}
+
+	return b.String(), nil
+}
+
+func (s *Service) whoCommand(ctx context.Context, name string) (string, error) {
+	schedules, err := s.schedules.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, sched := range schedules {
+		if name != "" && !strings.Contains(strings.ToLower(sched.Name), strings.ToLower(name)) {
+			continue
+		}
+
+		userIDs, err := s.schedules.OnCallUserIDs(ctx, sched.ID, s.now())
+		if err != nil {
+			return "", err
+		}
+
+		mentions := make([]string, 0, len(userIDs))
+		for _, id := range userIDs {
+			mentions = append(mentions, s.mention(ctx, id))
+		}
+
+		if len(mentions) == 0 {
+			mentions = append(mentions, "nobody")
+		}
+
+		fmt.Fprintf(&b, "*%s*: %s\n", escape(sched.Name), strings.Join(mentions, ", "))
+	}
+
+	if b.Len() == 0 {
+		return "No schedules found", nil
+	}
+
+	return b.String(), nil
+}
+
+func (s *Service) actionCommand(ctx context.Context, slackUserID, action string, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing alert group, try `/oncall list`")
+	}
+
+	u, err := s.users.GetByContactMethod(ctx, Type, slackUserID)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return "", ErrNotLinked
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var silence time.Duration
+	if action == chat.ActionSilence && len(args) > 1 {
+		if silence, err = time.ParseDuration(args[1]); err != nil || silence < 0 {
+			return "", fmt.Errorf("invalid duration %q, such as 30m or 2h", args[1])
+		}
+	}
+
+	if err := s.apply(ctx, args[0], action, silence, u); err != nil {
+		return "", err
+	}
+
+	done, ok := chat.ActionText[alertgroup.Action(action)]
+	if !ok {
+		done = "Escalated"
+	}
+
+	return fmt.Sprintf("%s alert group `%s`", done, args[0]), nil
+}
+
+func ephemeral(text string) *CommandResponse {
+	return &CommandResponse{ResponseType: "ephemeral", Text: text}
+}
@@ -0,0 +1,109 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// Type is the notifier type, and the type of the contact method holding
+// the Slack member ID of users.
+const Type = "slack"
+
+const DefaultAPIURL = "https://slack.com/api"
+
+// maxTextLength is the longest text Slack shows in a section block.
+const maxTextLength = 3000
+
+// Notifier posts to Slack as the bot of the Slack app. It is configured by
+// the [notifier.slack] section.
+type Notifier struct {
+	client         *Client
+	apiURL         string
+	token          string
+	signingSecret  string
+	defaultChannel string
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+func New(settings map[string]string) notifier.Notifier {
+	apiURL := settings["api_url"]
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	return &Notifier{
+		client: &Client{
+			url:   apiURL,
+			token: settings["bot_token"],
+			http:  &http.Client{Timeout: 10 * time.Second},
+		},
+		apiURL:         apiURL,
+		token:          settings["bot_token"],
+		signingSecret:  settings["signing_secret"],
+		defaultChannel: settings["default_channel"],
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:   notifier.AddressContactMethod,
+		Channel:   true,
+		Actions:   true,
+		MaxLength: maxTextLength,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	if n.token == "" {
+		return fmt.Errorf("%w: bot_token is required", notifier.ErrInvalidConfig)
+	}
+
+	if n.signingSecret == "" {
+		return fmt.Errorf("%w: signing_secret is required", notifier.ErrInvalidConfig)
+	}
+
+	u, err := url.Parse(n.apiURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid api_url %q", notifier.ErrInvalidConfig, n.apiURL)
+	}
+
+	return nil
+}
+
+// Send posts the message to the channel or user ID, with buttons to
+// acknowledge and resolve the alert group it is about.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	text := fmt.Sprintf("*%s*", escape(m.Title))
+	if m.Text != "" {
+		text += "\n" + escape(m.Text)
+	}
+	if m.URL != "" {
+		text += fmt.Sprintf("\n<%s|Open>", m.URL)
+	}
+
+	blocks := []*Block{{Type: "section", Text: markdown(text)}}
+	if m.AlertGroupID != "" {
+		blocks = append(blocks, &Block{
+			Type:    "actions",
+			BlockID: m.AlertGroupID,
+			Elements: []any{
+				button("Acknowledge", chat.ActionAcknowledge, string(chat.StylePrimary)),
+				button("Resolve", chat.ActionResolve, ""),
+			},
+		})
+	}
+
+	_, ts, err := n.client.PostMessage(ctx, address, "", m.Title, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: ts}, nil
+}
@@ -0,0 +1,285 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/integration"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+var ErrNotLinked = errors.New("your Slack account is not linked to an OnCall user, add your Slack member ID to your contact methods")
+
+// Service is the Slack app. It posts alert groups to the chat channel of
+// their route, keeps the messages up to date and applies the actions taken
+// on them in Slack.
+type Service struct {
+	store        Store
+	notifier     *Notifier
+	notifiers    *notifier.Service
+	alertGroups  *alertgroup.Service
+	escalations  *escalation.Service
+	integrations *integration.Service
+	schedules    *schedule.Service
+	users        *user.Service
+	now          func() time.Time
+}
+
+// NewService creates the Slack app, which does nothing unless the Slack
+// notifier is enabled in the registry.
+func NewService(store Store, registry *notifier.Registry, notifiers *notifier.Service, alertGroups *alertgroup.Service, escalations *escalation.Service, integrations *integration.Service, schedules *schedule.Service, users *user.Service) *Service {
+	s := &Service{
+		store:        store,
+		notifiers:    notifiers,
+		alertGroups:  alertGroups,
+		escalations:  escalations,
+		integrations: integrations,
+		schedules:    schedules,
+		users:        users,
+		now:          time.Now,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s.notifier != nil
+}
+
+// Verify checks that a request to the app was sent by Slack.
+func (s *Service) Verify(header http.Header, body []byte) error {
+	return s.notifier.Verify(header, body, s.now())
+}
+
+// HandleAlertGroupCreated posts a new alert group to the chat channel of its
+// route, or the default channel.
+func (s *Service) HandleAlertGroupCreated(ctx context.Context, job *worker.Job) {
+	if !s.Enabled() {
+		return
+	}
+
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, job.Args[0])
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	channel := s.notifier.defaultChannel
+	if g.RouteID != "" {
+		route, err := s.integrations.GetRoute(ctx, g.RouteID)
+		if err == nil && route.ChatChannel != "" {
+			channel = route.ChatChannel
+		}
+	}
+
+	if channel == "" {
+		return
+	}
+
+	channelID, ts, err := s.notifier.client.PostMessage(ctx, channel, "", alertGroupText(g), alertGroupBlocks(g, ""))
+	s.notifiers.Record(ctx, Type, channel, &notifier.Message{Channel: channel, Title: g.Title, AlertGroupID: g.ID}, &notifier.Result{ExternalID: ts}, err)
+	if err != nil {
+		fmt.Printf("Failed to post alert group %s to Slack: %s\n", g.ID, err)
+		return
+	}
+
+	if err := s.store.SaveThread(ctx, &Thread{AlertGroupID: g.ID, Channel: channelID, TS: ts}); err != nil {
+		fmt.Printf("Failed to save Slack thread of alert group %s: %s\n", g.ID, err)
+	}
+}
+
+// HandleAlertAdded refreshes the alert count of the alert group message and
+// replies in its thread.
+func (s *Service) HandleAlertAdded(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.update(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		return fmt.Sprintf("New alert received, %d in total", g.AlertsCount)
+	})
+}
+
+// HandleStateChanged updates the alert group message after it changed state
+// and replies in its thread with who changed it.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	s.update(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		return chat.Status(ctx, s.alertGroups, g, alertgroup.Action(job.Args[1]), func(actor string) string {
+			return s.mention(ctx, actor)
+		}, date)
+	})
+}
+
+// date formats the time in the time zone of the reader.
+func date(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", t.Unix(), chat.Date(t))
+}
+
+// update refreshes the message of the alert group, replying in its thread
+// with the text returned by reply unless it is empty.
+func (s *Service) update(ctx context.Context, alertGroupID string, reply func(g *alertgroup.AlertGroup) string) {
+	if !s.Enabled() {
+		return
+	}
+
+	t, err := s.store.GetThread(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load Slack thread of alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	if t == nil {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	text := reply(g)
+
+	if err := s.notifier.client.UpdateMessage(ctx, t.Channel, t.TS, alertGroupText(g), alertGroupBlocks(g, text)); err != nil {
+		fmt.Printf("Failed to update Slack message of alert group %s: %s\n", g.ID, err)
+	}
+
+	if text == "" {
+		return
+	}
+
+	if _, _, err := s.notifier.client.PostMessage(ctx, t.Channel, t.TS, text, nil); err != nil {
+		fmt.Printf("Failed to reply to Slack thread of alert group %s: %s\n", g.ID, err)
+	}
+}
+
+// reply posts to the thread of the alert group, if it has one.
+func (s *Service) reply(ctx context.Context, alertGroupID, text string) {
+	t, err := s.store.GetThread(ctx, alertGroupID)
+	if err != nil || t == nil {
+		return
+	}
+
+	if _, _, err := s.notifier.client.PostMessage(ctx, t.Channel, t.TS, text, nil); err != nil {
+		fmt.Printf("Failed to reply to Slack thread of alert group %s: %s\n", alertGroupID, err)
+	}
+}
+
+// mention refers to the actor of an alert group action, mentioning users
+// with a linked Slack account.
+func (s *Service) mention(ctx context.Context, actor string) string {
+	switch actor {
+	case alertgroup.SystemActor:
+		return "OnCall"
+	case alertgroup.SourceActor:
+		return "the alert source"
+	}
+
+	u, err := s.users.Get(ctx, actor)
+	if err != nil {
+		return actor
+	}
+
+	if cm := u.ContactMethod(Type); cm != nil {
+		return fmt.Sprintf("<@%s>", cm.Address)
+	}
+
+	return u.Username
+}
+
+type interaction struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Actions []struct {
+		ActionID       string `json:"action_id"`
+		BlockID        string `json:"block_id"`
+		Value          string `json:"value"`
+		SelectedOption *struct {
+			Value string `json:"value"`
+		} `json:"selected_option"`
+	} `json:"actions"`
+}
+
+// Interact applies the actions taken with the buttons and menus of a
+// message, telling the user privately when they fail.
+func (s *Service) Interact(ctx context.Context, payload []byte) error {
+	var p interaction
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid interaction payload: %w", err)
+	}
+
+	if p.Type != "block_actions" {
+		return nil
+	}
+
+	u, err := s.users.GetByContactMethod(ctx, Type, p.User.ID)
+	if errors.Is(err, user.ErrUserNotFound) {
+		err = ErrNotLinked
+	}
+
+	for _, a := range p.Actions {
+		if err != nil {
+			break
+		}
+
+		var silence time.Duration
+		if a.ActionID == chat.ActionSilence && a.SelectedOption != nil {
+			seconds, _ := strconv.Atoi(a.SelectedOption.Value)
+			silence = time.Duration(seconds) * time.Second
+		}
+
+		err = s.apply(ctx, a.BlockID, a.ActionID, silence, u)
+	}
+
+	if err != nil {
+		if err := s.notifier.client.PostEphemeral(ctx, p.Channel.ID, p.User.ID, err.Error()); err != nil {
+			fmt.Printf("Failed to post Slack error to %s: %s\n", p.User.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// apply takes the action on the alert group as the user.
+func (s *Service) apply(ctx context.Context, alertGroupID, action string, silence time.Duration, u *user.User) error {
+	if err := chat.Apply(ctx, s.alertGroups, s.escalations, alertGroupID, action, silence, u.ID); err != nil {
+		return err
+	}
+
+	if action == chat.ActionEscalate {
+		s.reply(ctx, alertGroupID, "Escalated by "+s.mention(ctx, u.ID))
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package slack
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps threads in the SQL database, which the server and workers
+// share.
+type SQLStore struct {
+	threads *sqlstore.Table[Thread]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	threads, err := sqlstore.NewTable(db, "slack_threads", func(t *Thread) string { return t.AlertGroupID })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{threads: threads}, nil
+}
+
+func (st *SQLStore) GetThread(ctx context.Context, alertGroupID string) (*Thread, error) {
+	t, err := st.threads.Get(ctx, alertGroupID)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return t, err
+}
+
+func (st *SQLStore) SaveThread(ctx context.Context, t *Thread) error {
+	return st.threads.Save(ctx, t)
+}
@@ -0,0 +1,19 @@
+package slack
+
+import "context"
+
+// Thread is the message an alert group was posted as, whose thread receives
+// the updates of the alert group.
+type Thread struct {
+	AlertGroupID string
+	// Channel is the ID of the channel, as returned by Slack.
+	Channel string
+	TS      string
+}
+
+type Store interface {
+	// GetThread returns the thread of the alert group, or nil if it was not
+	// posted to Slack.
+	GetThread(ctx context.Context, alertGroupID string) (*Thread, error)
+	SaveThread(ctx context.Context, t *Thread) error
+}
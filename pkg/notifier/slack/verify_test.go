@@ -0,0 +1,62 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret string, timestamp time.Time, body string) http.Header {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", ts)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return header
+}
+
+func TestVerify(t *testing.T) {
+	n := &Notifier{signingSecret: "secret"}
+	now := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+	body := "payload=%7B%22type%22%3A%22block_actions%22%7D"
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    string
+		wantErr bool
+	}{
+		{name: "valid signature", header: sign("secret", now, body), body: body},
+		{name: "recent timestamp", header: sign("secret", now.Add(-4*time.Minute), body), body: body},
+		{name: "tampered body", header: sign("secret", now, body), body: body + "x", wantErr: true},
+		{name: "stale timestamp", header: sign("secret", now.Add(-6*time.Minute), body), body: body, wantErr: true},
+		{name: "future timestamp", header: sign("secret", now.Add(6*time.Minute), body), body: body, wantErr: true},
+		{name: "wrong secret", header: sign("other", now, body), body: body, wantErr: true},
+		{name: "no signature", header: http.Header{}, body: body, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := n.Verify(tt.header, []byte(tt.body), now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps deliveries in the SQL database, which the server and
+// workers share.
+type SQLStore struct {
+	deliveries *sqlstore.Table[Delivery]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	deliveries, err := sqlstore.NewTable(db, "notifier_deliveries", func(d *Delivery) string { return d.ID },
+		sqlstore.Column[Delivery]{Name: "alert_group_id", Value: func(d *Delivery) string { return d.AlertGroupID }},
+		sqlstore.Column[Delivery]{Name: "external_id", Value: func(d *Delivery) string { return d.Type + "/" + d.ExternalID }},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{deliveries: deliveries}, nil
+}
+
+func (st *SQLStore) SaveDelivery(ctx context.Context, d *Delivery) error {
+	return st.deliveries.Insert(ctx, d)
+}
+
+func (st *SQLStore) UpdateDelivery(ctx context.Context, d *Delivery) error {
+	err := st.deliveries.Update(ctx, d)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrDeliveryNotFound
+	}
+
+	return err
+}
+
+func (st *SQLStore) GetDeliveryByExternalID(ctx context.Context, t, externalID string) (*Delivery, error) {
+	d, err := st.deliveries.FindOne(ctx, sqlstore.Where{"external_id": t + "/" + externalID})
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+
+	return d, err
+}
+
+func (st *SQLStore) ListDeliveries(ctx context.Context, alertGroupID string) ([]*Delivery, error) {
+	return st.deliveries.Find(ctx, sqlstore.Where{"alert_group_id": alertGroupID})
+}
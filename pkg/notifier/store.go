@@ -0,0 +1,14 @@
+package notifier
+
+import "context"
+
+type Store interface {
+	SaveDelivery(ctx context.Context, d *Delivery) error
+	UpdateDelivery(ctx context.Context, d *Delivery) error
+	// GetDeliveryByExternalID returns the delivery of the notifier type
+	// with the ID the service behind the notifier reported.
+	GetDeliveryByExternalID(ctx context.Context, t, externalID string) (*Delivery, error)
+	// ListDeliveries returns the deliveries for the alert group, oldest
+	// first.
+	ListDeliveries(ctx context.Context, alertGroupID string) ([]*Delivery, error)
+}
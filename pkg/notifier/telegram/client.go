@@ -0,0 +1,142 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the Telegram Bot API.
+type Client struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// Keyboard is an inline keyboard, as rows of buttons.
+type Keyboard struct {
+	InlineKeyboard [][]*Button `json:"inline_keyboard"`
+}
+
+type Button struct {
+	Text string `json:"text"`
+	// CallbackData is sent back in a callback query when the button is
+	// pressed, and is at most 64 bytes.
+	CallbackData string `json:"callback_data"`
+}
+
+type sendMessageRequest struct {
+	ChatID      string    `json:"chat_id"`
+	Text        string    `json:"text"`
+	ParseMode   string    `json:"parse_mode"`
+	ReplyMarkup *Keyboard `json:"reply_markup,omitempty"`
+	ReplyTo     int64     `json:"reply_to_message_id,omitempty"`
+}
+
+type editMessageRequest struct {
+	ChatID      string    `json:"chat_id"`
+	MessageID   int64     `json:"message_id"`
+	Text        string    `json:"text"`
+	ParseMode   string    `json:"parse_mode"`
+	ReplyMarkup *Keyboard `json:"reply_markup,omitempty"`
+}
+
+type answerCallbackRequest struct {
+	CallbackQueryID string `json:"callback_query_id"`
+	Text            string `json:"text,omitempty"`
+	ShowAlert       bool   `json:"show_alert,omitempty"`
+}
+
+type message struct {
+	MessageID int64 `json:"message_id"`
+}
+
+// SendMessage sends HTML text to the chat, as a reply to the message with
+// the ID replyTo unless it is zero, and returns the ID of the new message.
+func (c *Client) SendMessage(ctx context.Context, chatID, text string, keyboard *Keyboard, replyTo int64) (int64, error) {
+	var m message
+	err := c.call(ctx, "sendMessage", &sendMessageRequest{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   "HTML",
+		ReplyMarkup: keyboard,
+		ReplyTo:     replyTo,
+	}, &m)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.MessageID, nil
+}
+
+// EditMessage replaces the text and keyboard of a message.
+func (c *Client) EditMessage(ctx context.Context, chatID string, messageID int64, text string, keyboard *Keyboard) error {
+	return c.call(ctx, "editMessageText", &editMessageRequest{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		Text:        text,
+		ParseMode:   "HTML",
+		ReplyMarkup: keyboard,
+	}, nil)
+}
+
+// AnswerCallback tells the user who pressed a button how it went.
+func (c *Client) AnswerCallback(ctx context.Context, id, text string, alert bool) error {
+	return c.call(ctx, "answerCallbackQuery", &answerCallbackRequest{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       alert,
+	}, nil)
+}
+
+func (c *Client) call(ctx context.Context, method string, req, result any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", strings.TrimSuffix(c.url, "/"), c.token, method)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		// The URL contains the bot token, so it is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer res.Body.Close()
+
+	var resp apiResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("telegram %s: unexpected response with status %s", method, res.Status)
+	}
+
+	if !resp.OK {
+		return fmt.Errorf("telegram %s: %s", method, resp.Description)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
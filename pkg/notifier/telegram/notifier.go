@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// Type is the notifier type, and the type of the contact method holding
+// the chat ID of users who linked their Telegram account.
+const Type = "telegram"
+
+const DefaultAPIURL = "https://api.telegram.org"
+
+// maxTextLength is the longest message Telegram accepts.
+const maxTextLength = 4096
+
+var ErrInvalidSecret = errors.New("invalid telegram webhook secret")
+
+// Notifier sends messages as a Telegram bot. It is configured by the
+// [notifier.telegram] section.
+type Notifier struct {
+	client        *Client
+	apiURL        string
+	token         string
+	webhookSecret string
+	chatID        string
+}
+
+var _ notifier.Notifier = &Notifier{}
+
+func New(settings map[string]string) notifier.Notifier {
+	apiURL := settings["api_url"]
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	return &Notifier{
+		client: &Client{
+			url:   apiURL,
+			token: settings["bot_token"],
+			http:  &http.Client{Timeout: 10 * time.Second},
+		},
+		apiURL:        apiURL,
+		token:         settings["bot_token"],
+		webhookSecret: settings["webhook_secret"],
+		chatID:        settings["chat_id"],
+	}
+}
+
+func (n *Notifier) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:   notifier.AddressContactMethod,
+		Channel:   true,
+		Actions:   true,
+		MaxLength: maxTextLength,
+	}
+}
+
+func (n *Notifier) ValidateConfig() error {
+	if n.token == "" {
+		return fmt.Errorf("%w: bot_token is required", notifier.ErrInvalidConfig)
+	}
+
+	if n.webhookSecret == "" {
+		return fmt.Errorf("%w: webhook_secret is required", notifier.ErrInvalidConfig)
+	}
+
+	if n.chatID != "" {
+		if _, err := strconv.ParseInt(n.chatID, 10, 64); err != nil && n.chatID[0] != '@' {
+			return fmt.Errorf("%w: chat_id must be a chat ID or @channelusername", notifier.ErrInvalidConfig)
+		}
+	}
+
+	u, err := url.Parse(n.apiURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid api_url %q", notifier.ErrInvalidConfig, n.apiURL)
+	}
+
+	return nil
+}
+
+// Send sends the message to the chat, with buttons to acknowledge and
+// resolve the alert group it is about.
+func (n *Notifier) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	text := fmt.Sprintf("<b>%s</b>", html.EscapeString(m.Title))
+	if m.Text != "" {
+		text += "\n" + html.EscapeString(m.Text)
+	}
+	if m.URL != "" {
+		text += fmt.Sprintf("\n<a href=\"%s\">Open</a>", html.EscapeString(m.URL))
+	}
+
+	var keyboard *Keyboard
+	if m.AlertGroupID != "" {
+		keyboard = &Keyboard{InlineKeyboard: [][]*Button{{
+			button("Acknowledge", chat.ActionAcknowledge, m.AlertGroupID),
+			button("Resolve", chat.ActionResolve, m.AlertGroupID),
+		}}}
+	}
+
+	id, err := n.client.SendMessage(ctx, address, text, keyboard, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: strconv.FormatInt(id, 10)}, nil
+}
+
+// Verify checks the secret token Telegram sends with webhook requests, which
+// is set when the webhook of the bot is registered. Without a secret no
+// request is trusted.
+func (n *Notifier) Verify(header http.Header) error {
+	token := header.Get("X-Telegram-Bot-Api-Secret-Token")
+	if n.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(n.webhookSecret)) != 1 {
+		return ErrInvalidSecret
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	header := func(token string) http.Header {
+		h := http.Header{}
+		if token != "" {
+			h.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		}
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		wantErr bool
+	}{
+		{name: "valid secret", secret: "secret", header: header("secret")},
+		{name: "wrong secret", secret: "secret", header: header("other"), wantErr: true},
+		{name: "prefix of the secret", secret: "secret", header: header("secre"), wantErr: true},
+		{name: "no secret sent", secret: "secret", header: header(""), wantErr: true},
+		{name: "no secret configured", header: header(""), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Notifier{webhookSecret: tt.secret}
+
+			err := n.Verify(tt.header)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSecret) {
+					t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSecret)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+)
+
+// button creates a button whose callback data is the action, the alert
+// group ID and optionally an argument, separated by colons.
+func button(label, action, alertGroupID string, arg ...string) *Button {
+	data := append([]string{action, alertGroupID}, arg...)
+	return &Button{Text: label, CallbackData: strings.Join(data, ":")}
+}
+
+// parseCallback splits callback data created by button.
+func parseCallback(data string) (action, alertGroupID, arg string, ok bool) {
+	parts := strings.SplitN(data, ":", 3)
+	if len(parts) < 2 {
+		return "", "", "", false
+	}
+
+	if len(parts) == 3 {
+		arg = parts[2]
+	}
+
+	return parts[0], parts[1], arg, true
+}
+
+// alertGroupText renders the alert group as HTML, followed by status when
+// it is set.
+func alertGroupText(g *alertgroup.AlertGroup, status string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s <b>%s</b>", chat.StateEmoji[g.State], html.EscapeString(g.Title))
+	if g.Message != "" {
+		fmt.Fprintf(&b, "\n%s", html.EscapeString(g.Message))
+	}
+
+	fmt.Fprintf(&b, "\n\nState: <b>%s</b> · Alerts: <b>%d</b>", g.State, g.AlertsCount)
+	if status != "" {
+		fmt.Fprintf(&b, "\n%s", html.EscapeString(status))
+	}
+
+	return b.String()
+}
+
+// alertGroupKeyboard offers the actions that apply to the state of the
+// alert group, with the silence durations on a row of their own.
+func alertGroupKeyboard(g *alertgroup.AlertGroup) *Keyboard {
+	var actions, silence []*Button
+	for _, a := range chat.Actions(g) {
+		if a.ID != chat.ActionSilence {
+			actions = append(actions, button(a.Label, a.ID, g.ID))
+			continue
+		}
+
+		for _, o := range chat.SilenceOptions {
+			silence = append(silence, button(o.Label, a.ID, g.ID, strconv.Itoa(int(o.Duration.Seconds()))))
+		}
+	}
+
+	rows := [][]*Button{actions}
+	if len(silence) > 0 {
+		rows = append(rows, silence)
+	}
+
+	return &Keyboard{InlineKeyboard: rows}
+}
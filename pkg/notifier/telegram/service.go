@@ -0,0 +1,358 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/escalation"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/notifier/chat"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+const (
+	// LinkCodeTTL is how long a link code can be sent to the bot.
+	LinkCodeTTL = 10 * time.Minute
+
+	linkCodeLength   = 8
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var (
+	ErrNotLinked       = errors.New("your Telegram account is not linked to an OnCall user")
+	ErrInvalidLinkCode = errors.New("invalid or expired link code")
+)
+
+// Service is the Telegram bot. It posts alert groups to the configured chat,
+// keeps the messages up to date, applies the actions taken with their
+// buttons and links the Telegram accounts of users for direct messages.
+type Service struct {
+	store       Store
+	notifier    *Notifier
+	notifiers   *notifier.Service
+	alertGroups *alertgroup.Service
+	escalations *escalation.Service
+	users       *user.Service
+	now         func() time.Time
+}
+
+// NewService creates the Telegram bot, which does nothing unless the
+// Telegram notifier is enabled in the registry.
+func NewService(store Store, registry *notifier.Registry, notifiers *notifier.Service, alertGroups *alertgroup.Service, escalations *escalation.Service, users *user.Service) *Service {
+	s := &Service{
+		store:       store,
+		notifiers:   notifiers,
+		alertGroups: alertGroups,
+		escalations: escalations,
+		users:       users,
+		now:         time.Now,
+	}
+
+	if n, err := registry.Get(Type); err == nil {
+		s.notifier = n.(*Notifier)
+	}
+
+	return s
+}
+
+func (s *Service) Enabled() bool {
+	return s.notifier != nil
+}
+
+// Verify checks that a webhook request was sent by Telegram.
+func (s *Service) Verify(header http.Header) error {
+	return s.notifier.Verify(header)
+}
+
+// HandleAlertGroupCreated posts a new alert group to the configured chat.
+func (s *Service) HandleAlertGroupCreated(ctx context.Context, job *worker.Job) {
+	if !s.Enabled() || s.notifier.chatID == "" {
+		return
+	}
+
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, job.Args[0])
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	id, err := s.notifier.client.SendMessage(ctx, s.notifier.chatID, alertGroupText(g, ""), alertGroupKeyboard(g), 0)
+	s.notifiers.Record(ctx, Type, s.notifier.chatID, &notifier.Message{Channel: s.notifier.chatID, Title: g.Title, AlertGroupID: g.ID}, &notifier.Result{ExternalID: strconv.FormatInt(id, 10)}, err)
+	if err != nil {
+		fmt.Printf("Failed to post alert group %s to Telegram: %s\n", g.ID, err)
+		return
+	}
+
+	if err := s.store.SavePost(ctx, &Post{AlertGroupID: g.ID, ChatID: s.notifier.chatID, MessageID: id}); err != nil {
+		fmt.Printf("Failed to save Telegram message of alert group %s: %s\n", g.ID, err)
+	}
+}
+
+// HandleAlertAdded refreshes the alert count of the alert group message.
+func (s *Service) HandleAlertAdded(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.update(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		return fmt.Sprintf("New alert received, %d in total", g.AlertsCount)
+	}, false)
+}
+
+// HandleStateChanged updates the alert group message after it changed state
+// and replies to it with who changed it.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	s.update(ctx, job.Args[0], func(g *alertgroup.AlertGroup) string {
+		return chat.Status(ctx, s.alertGroups, g, alertgroup.Action(job.Args[1]), func(actor string) string {
+			return chat.ActorName(ctx, s.users, actor)
+		}, chat.Date)
+	}, true)
+}
+
+// update refreshes the message of the alert group with status below it, and
+// also replies to the message with status when reply is set.
+func (s *Service) update(ctx context.Context, alertGroupID string, status func(g *alertgroup.AlertGroup) string, reply bool) {
+	if !s.Enabled() {
+		return
+	}
+
+	p, err := s.store.GetPost(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load Telegram message of alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	if p == nil {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	text := status(g)
+
+	if err := s.notifier.client.EditMessage(ctx, p.ChatID, p.MessageID, alertGroupText(g, text), alertGroupKeyboard(g)); err != nil {
+		fmt.Printf("Failed to update Telegram message of alert group %s: %s\n", g.ID, err)
+	}
+
+	if !reply || text == "" {
+		return
+	}
+
+	if _, err := s.notifier.client.SendMessage(ctx, p.ChatID, html.EscapeString(text), nil, p.MessageID); err != nil {
+		fmt.Printf("Failed to reply to Telegram message of alert group %s: %s\n", g.ID, err)
+	}
+}
+
+// CreateLinkCode creates a one-time code the user sends to the bot to link
+// their Telegram account, replacing any earlier code.
+func (s *Service) CreateLinkCode(ctx context.Context, userID string) (*LinkCode, error) {
+	if _, err := s.users.Get(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	code, err := newLinkCode()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &LinkCode{
+		Code:      code,
+		UserID:    userID,
+		ExpiresAt: s.now().Add(LinkCodeTTL),
+	}
+
+	if err := s.store.SaveLinkCode(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+type chatInfo struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type from struct {
+	ID int64 `json:"id"`
+}
+
+type update struct {
+	Message *struct {
+		MessageID int64    `json:"message_id"`
+		From      *from    `json:"from"`
+		Chat      chatInfo `json:"chat"`
+		Text      string   `json:"text"`
+	} `json:"message"`
+	CallbackQuery *struct {
+		ID      string `json:"id"`
+		From    from   `json:"from"`
+		Message *struct {
+			MessageID int64    `json:"message_id"`
+			Chat      chatInfo `json:"chat"`
+		} `json:"message"`
+		Data string `json:"data"`
+	} `json:"callback_query"`
+}
+
+// HandleUpdate handles an update sent to the webhook of the bot: the buttons
+// pressed on alert group messages, and the commands sent to the bot in
+// private chats.
+func (s *Service) HandleUpdate(ctx context.Context, body []byte) error {
+	var u update
+	if err := json.Unmarshal(body, &u); err != nil {
+		return fmt.Errorf("invalid telegram update: %w", err)
+	}
+
+	switch {
+	case u.CallbackQuery != nil:
+		q := u.CallbackQuery
+
+		g, err := s.callback(ctx, q.From.ID, q.Data)
+
+		text := "Done"
+		if err != nil {
+			text = err.Error()
+		}
+
+		if err := s.notifier.client.AnswerCallback(ctx, q.ID, text, err != nil); err != nil {
+			fmt.Printf("Failed to answer Telegram callback query %s: %s\n", q.ID, err)
+		}
+
+		// The message in the alert group chat is updated when the state
+		// change is handled, others such as direct messages are updated
+		// here.
+		if g != nil && q.Message != nil {
+			chatID := strconv.FormatInt(q.Message.Chat.ID, 10)
+
+			p, _ := s.store.GetPost(ctx, g.ID)
+			if p == nil || p.MessageID != q.Message.MessageID {
+				if err := s.notifier.client.EditMessage(ctx, chatID, q.Message.MessageID, alertGroupText(g, ""), alertGroupKeyboard(g)); err != nil {
+					fmt.Printf("Failed to update Telegram message of alert group %s: %s\n", g.ID, err)
+				}
+			}
+		}
+	case u.Message != nil && u.Message.Chat.Type == "private" && u.Message.From != nil:
+		m := u.Message
+		chatID := strconv.FormatInt(m.Chat.ID, 10)
+
+		reply := s.command(ctx, m.From.ID, m.Text)
+		if _, err := s.notifier.client.SendMessage(ctx, chatID, reply, nil, m.MessageID); err != nil {
+			fmt.Printf("Failed to reply to Telegram chat %s: %s\n", chatID, err)
+		}
+	}
+
+	return nil
+}
+
+// callback applies the action of a pressed button as the user linked to the
+// Telegram account, returning the updated alert group.
+func (s *Service) callback(ctx context.Context, telegramID int64, data string) (*alertgroup.AlertGroup, error) {
+	action, alertGroupID, arg, ok := parseCallback(data)
+	if !ok {
+		return nil, fmt.Errorf("unknown action %q", data)
+	}
+
+	u, err := s.users.GetByContactMethod(ctx, Type, strconv.FormatInt(telegramID, 10))
+	if errors.Is(err, user.ErrUserNotFound) {
+		return nil, ErrNotLinked
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var silence time.Duration
+	if action == chat.ActionSilence {
+		seconds, err := strconv.Atoi(arg)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid silence duration %q", arg)
+		}
+
+		silence = time.Duration(seconds) * time.Second
+	}
+
+	if err := chat.Apply(ctx, s.alertGroups, s.escalations, alertGroupID, action, silence, u.ID); err != nil {
+		return nil, err
+	}
+
+	return s.alertGroups.Get(ctx, alertGroupID)
+}
+
+// command runs a command sent to the bot in a private chat and returns the
+// reply. /start CODE and /link CODE link the account that sent it.
+func (s *Service) command(ctx context.Context, telegramID int64, text string) string {
+	name, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	name, _, _ = strings.Cut(name, "@")
+	arg = strings.ToUpper(strings.TrimSpace(arg))
+
+	switch name {
+	case "/start", "/link":
+		if arg == "" {
+			return "Create a link code in OnCall, then send <code>/link CODE</code> to receive your notifications here."
+		}
+
+		u, err := s.link(ctx, arg, telegramID)
+		if err != nil {
+			return html.EscapeString(err.Error())
+		}
+
+		return fmt.Sprintf("Linked to OnCall user <b>%s</b>, your notifications will be sent here.", html.EscapeString(u.Username))
+	default:
+		return "Unknown command, send <code>/link CODE</code> with a link code from OnCall to link your account."
+	}
+}
+
+// link spends the link code, linking the Telegram account to its user.
+func (s *Service) link(ctx context.Context, code string, telegramID int64) (*user.User, error) {
+	c, err := s.store.TakeLinkCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil || !s.now().Before(c.ExpiresAt) {
+		return nil, ErrInvalidLinkCode
+	}
+
+	return s.users.VerifyContactMethod(ctx, c.UserID, Type, strconv.FormatInt(telegramID, 10))
+}
+
+func newLinkCode() (string, error) {
+	b := make([]byte, linkCodeLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(linkCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate link code: %w", err)
+		}
+
+		b[i] = linkCodeAlphabet[n.Int64()]
+	}
+
+	return string(b), nil
+}
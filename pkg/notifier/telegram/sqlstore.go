@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps posts and link codes in the SQL database, which the server
+// and workers share.
+type SQLStore struct {
+	db    *sqlstore.DB
+	posts *sqlstore.Table[Post]
+	codes *sqlstore.Table[LinkCode]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	posts, err := sqlstore.NewTable(db, "telegram_posts", func(p *Post) string { return p.AlertGroupID })
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := sqlstore.NewTable(db, "telegram_link_codes", func(c *LinkCode) string { return c.Code },
+		sqlstore.Column[LinkCode]{Name: "user_id", Value: func(c *LinkCode) string { return c.UserID }})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, posts: posts, codes: codes}, nil
+}
+
+func (st *SQLStore) GetPost(ctx context.Context, alertGroupID string) (*Post, error) {
+	p, err := st.posts.Get(ctx, alertGroupID)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return p, err
+}
+
+func (st *SQLStore) SavePost(ctx context.Context, p *Post) error {
+	return st.posts.Save(ctx, p)
+}
+
+func (st *SQLStore) SaveLinkCode(ctx context.Context, c *LinkCode) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := st.codes.DeleteWhere(ctx, sqlstore.Where{"user_id": c.UserID}); err != nil {
+			return err
+		}
+
+		return st.codes.Save(ctx, c)
+	})
+}
+
+func (st *SQLStore) TakeLinkCode(ctx context.Context, code string) (*LinkCode, error) {
+	c, err := st.codes.Take(ctx, code)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return c, err
+}
@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	"context"
+	"time"
+)
+
+// Post is the message an alert group was posted as, which is edited and
+// replied to as the alert group changes.
+type Post struct {
+	AlertGroupID string
+	ChatID       string
+	MessageID    int64
+}
+
+// LinkCode is a one-time code a user sends to the bot to link their
+// Telegram account.
+type LinkCode struct {
+	Code      string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+type Store interface {
+	// GetPost returns the post of the alert group, or nil if it was not
+	// posted to Telegram.
+	GetPost(ctx context.Context, alertGroupID string) (*Post, error)
+	SavePost(ctx context.Context, p *Post) error
+
+	// SaveLinkCode replaces any earlier code of the user.
+	SaveLinkCode(ctx context.Context, c *LinkCode) error
+	// TakeLinkCode returns and deletes the code, or returns nil if there is
+	// no such code.
+	TakeLinkCode(ctx context.Context, code string) (*LinkCode, error)
+}
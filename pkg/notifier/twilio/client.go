@@ -0,0 +1,91 @@
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls a Twilio compatible REST API.
+type Client struct {
+	url        string
+	accountSID string
+	authToken  string
+	http       *http.Client
+}
+
+type resource struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+type errorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// CreateMessage sends an SMS and returns its SID. Twilio posts the status of
+// the message to statusCallback unless it is empty.
+func (c *Client) CreateMessage(ctx context.Context, from, to, body, statusCallback string) (string, error) {
+	form := url.Values{
+		"From": {from},
+		"To":   {to},
+		"Body": {body},
+	}
+	if statusCallback != "" {
+		form.Set("StatusCallback", statusCallback)
+	}
+
+	return c.create(ctx, "Messages.json", form)
+}
+
+// CreateCall places a call following the TwiML instructions and returns its
+// SID. Twilio posts the status of the call to statusCallback unless it is
+// empty.
+func (c *Client) CreateCall(ctx context.Context, from, to, twiml, statusCallback string) (string, error) {
+	form := url.Values{
+		"From":  {from},
+		"To":    {to},
+		"Twiml": {twiml},
+	}
+	if statusCallback != "" {
+		form.Set("StatusCallback", statusCallback)
+	}
+
+	return c.create(ctx, "Calls.json", form)
+}
+
+func (c *Client) create(ctx context.Context, resourceName string, form url.Values) (string, error) {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/%s", strings.TrimSuffix(c.url, "/"), url.PathEscape(c.accountSID), resourceName)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth(c.accountSID, c.authToken)
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		return "", fmt.Errorf("twilio: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.NewDecoder(res.Body).Decode(&e); err == nil && e.Message != "" {
+			return "", fmt.Errorf("twilio: %s (%d)", e.Message, e.Code)
+		}
+		return "", fmt.Errorf("twilio: unexpected response with status %s", res.Status)
+	}
+
+	var created resource
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("twilio: invalid response: %w", err)
+	}
+
+	return created.SID, nil
+}
@@ -0,0 +1,172 @@
+package twilio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notifier"
+)
+
+// Types of the SMS and phone call notifiers, which are configured by the
+// [notifier.sms] and [notifier.phone_call] sections.
+const (
+	SMSType  = "sms"
+	CallType = "phone_call"
+)
+
+const DefaultAPIURL = "https://api.twilio.com"
+
+// Paths of the callbacks Twilio posts to, relative to the root URL.
+const (
+	SMSStatusPath  = "twilio/sms/status"
+	CallStatusPath = "twilio/call/status"
+	CallGatherPath = "twilio/call/gather"
+)
+
+// maxSMSLength is the longest body Twilio accepts.
+const maxSMSLength = 1600
+
+// account holds the settings shared by the SMS and phone call notifiers.
+type account struct {
+	client     *Client
+	apiURL     string
+	accountSID string
+	authToken  string
+	from       string
+	// rootURL is where Twilio reaches the callbacks.
+	rootURL string
+}
+
+func newAccount(settings map[string]string, rootURL string) *account {
+	apiURL := settings["api_url"]
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	return &account{
+		client: &Client{
+			url:        apiURL,
+			accountSID: settings["account_sid"],
+			authToken:  settings["auth_token"],
+			http:       &http.Client{Timeout: 10 * time.Second},
+		},
+		apiURL:     apiURL,
+		accountSID: settings["account_sid"],
+		authToken:  settings["auth_token"],
+		from:       settings["from_number"],
+		rootURL:    rootURL,
+	}
+}
+
+func (a *account) ValidateConfig() error {
+	if a.accountSID == "" || a.authToken == "" {
+		return fmt.Errorf("%w: account_sid and auth_token are required", notifier.ErrInvalidConfig)
+	}
+
+	if !strings.HasPrefix(a.from, "+") {
+		return fmt.Errorf("%w: from_number must be a phone number in E.164 format", notifier.ErrInvalidConfig)
+	}
+
+	u, err := url.Parse(a.apiURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid api_url %q", notifier.ErrInvalidConfig, a.apiURL)
+	}
+
+	return nil
+}
+
+// Verify checks the signature of a callback Twilio posted to the path,
+// including its query, relative to the root URL.
+func (a *account) Verify(path string, form url.Values, signature string) error {
+	return Verify(a.authToken, a.rootURL+strings.TrimPrefix(path, "/"), form, signature)
+}
+
+// SMS sends text messages.
+type SMS struct {
+	*account
+}
+
+var _ notifier.Notifier = &SMS{}
+
+// NewSMS returns the factory of SMS notifiers, whose status callbacks are
+// reached at the root URL.
+func NewSMS(rootURL string) notifier.Factory {
+	return func(settings map[string]string) notifier.Notifier {
+		return &SMS{account: newAccount(settings, rootURL)}
+	}
+}
+
+func (n *SMS) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address:   notifier.AddressPhone,
+		MaxLength: maxSMSLength,
+	}
+}
+
+func (n *SMS) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	body := m.Title
+	if m.Text != "" {
+		body += "\n" + m.Text
+	}
+	if m.URL != "" {
+		body += "\n" + m.URL
+	}
+
+	sid, err := n.client.CreateMessage(ctx, n.from, address, body, n.rootURL+SMSStatusPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: sid}, nil
+}
+
+// SendSMS sends a text message without recording a delivery, such as a
+// phone verification code.
+func (n *SMS) SendSMS(ctx context.Context, to, body string) error {
+	_, err := n.client.CreateMessage(ctx, n.from, to, body, "")
+	return err
+}
+
+// Call places phone calls which read the alert title, and let the user
+// acknowledge or resolve the alert group by pressing a digit.
+type Call struct {
+	*account
+}
+
+var _ notifier.Notifier = &Call{}
+
+// NewCall returns the factory of phone call notifiers, whose callbacks are
+// reached at the root URL.
+func NewCall(rootURL string) notifier.Factory {
+	return func(settings map[string]string) notifier.Notifier {
+		return &Call{account: newAccount(settings, rootURL)}
+	}
+}
+
+func (n *Call) Capabilities() notifier.Capabilities {
+	return notifier.Capabilities{
+		Address: notifier.AddressPhone,
+		Actions: true,
+	}
+}
+
+func (n *Call) Send(ctx context.Context, address string, m *notifier.Message) (*notifier.Result, error) {
+	var action string
+	if m.AlertGroupID != "" && m.UserID != "" {
+		action = n.rootURL + CallGatherPath + "?" + url.Values{
+			"alert_group_id": {m.AlertGroupID},
+			"user_id":        {m.UserID},
+		}.Encode()
+	}
+
+	sid, err := n.client.CreateCall(ctx, n.from, address, callTwiML(m.Title, action), n.rootURL+CallStatusPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.Result{ExternalID: sid}, nil
+}
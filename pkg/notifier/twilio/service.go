@@ -0,0 +1,134 @@
+package twilio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+)
+
+// smsStatuses maps the final statuses of messages to delivery statuses.
+var smsStatuses = map[string]notifier.Status{
+	"delivered":   notifier.StatusDelivered,
+	"read":        notifier.StatusDelivered,
+	"undelivered": notifier.StatusFailed,
+	"failed":      notifier.StatusFailed,
+}
+
+// callStatuses maps the final statuses of calls to delivery statuses.
+var callStatuses = map[string]notifier.Status{
+	"completed": notifier.StatusDelivered,
+	"busy":      notifier.StatusFailed,
+	"no-answer": notifier.StatusFailed,
+	"failed":    notifier.StatusFailed,
+	"canceled":  notifier.StatusFailed,
+}
+
+// Service handles the callbacks Twilio posts about messages and calls sent
+// by the SMS and phone call notifiers.
+type Service struct {
+	sms         *SMS
+	call        *Call
+	notifiers   *notifier.Service
+	alertGroups *alertgroup.Service
+}
+
+// NewService creates the Twilio callback service. Callbacks of notifiers
+// that are not enabled in the registry are rejected.
+func NewService(registry *notifier.Registry, notifiers *notifier.Service, alertGroups *alertgroup.Service) *Service {
+	s := &Service{
+		notifiers:   notifiers,
+		alertGroups: alertGroups,
+	}
+
+	if n, err := registry.Get(SMSType); err == nil {
+		s.sms, _ = n.(*SMS)
+	}
+
+	if n, err := registry.Get(CallType); err == nil {
+		s.call, _ = n.(*Call)
+	}
+
+	return s
+}
+
+func (s *Service) SMSEnabled() bool {
+	return s.sms != nil
+}
+
+func (s *Service) CallEnabled() bool {
+	return s.call != nil
+}
+
+// VerifySMS checks that a callback about a message was sent by Twilio.
+func (s *Service) VerifySMS(path string, form url.Values, signature string) error {
+	return s.sms.Verify(path, form, signature)
+}
+
+// VerifyCall checks that a callback about a call was sent by Twilio.
+func (s *Service) VerifyCall(path string, form url.Values, signature string) error {
+	return s.call.Verify(path, form, signature)
+}
+
+// SMSStatus records the final status of a message on its delivery.
+func (s *Service) SMSStatus(ctx context.Context, form url.Values) error {
+	return s.updateDelivery(ctx, SMSType, form.Get("MessageSid"), form.Get("MessageStatus"), form.Get("ErrorCode"), smsStatuses)
+}
+
+// CallStatus records the final status of a call on its delivery.
+func (s *Service) CallStatus(ctx context.Context, form url.Values) error {
+	return s.updateDelivery(ctx, CallType, form.Get("CallSid"), form.Get("CallStatus"), form.Get("ErrorCode"), callStatuses)
+}
+
+func (s *Service) updateDelivery(ctx context.Context, t, sid, status, errorCode string, statuses map[string]notifier.Status) error {
+	st, ok := statuses[status]
+	if !ok {
+		return nil
+	}
+
+	var reason string
+	if st == notifier.StatusFailed {
+		reason = status
+		if errorCode != "" {
+			reason += fmt.Sprintf(" (error %s)", errorCode)
+		}
+	}
+
+	err := s.notifiers.UpdateDelivery(ctx, t, sid, st, reason)
+	if errors.Is(err, notifier.ErrDeliveryNotFound) {
+		// Messages such as verification codes are not recorded.
+		return nil
+	}
+
+	return err
+}
+
+// Gather applies the digit the user pressed during a call about the alert
+// group, and returns the TwiML telling them how it went.
+func (s *Service) Gather(ctx context.Context, alertGroupID, userID, digits string) string {
+	var (
+		done string
+		err  error
+	)
+
+	switch digits {
+	case DigitAcknowledge:
+		done = "acknowledged"
+		_, err = s.alertGroups.Acknowledge(ctx, alertGroupID, userID)
+	case DigitResolve:
+		done = "resolved"
+		_, err = s.alertGroups.Resolve(ctx, alertGroupID, userID)
+	default:
+		return SayTwiML("Unknown option. Goodbye.")
+	}
+
+	if err != nil {
+		fmt.Printf("Failed to apply call input to alert group %s: %s\n", alertGroupID, err)
+		return SayTwiML("The alert group could not be " + done + ". Goodbye.")
+	}
+
+	return SayTwiML("The alert group was " + done + ". Goodbye.")
+}
@@ -0,0 +1,59 @@
+package twilio
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// Digits pressed during calls about alert groups.
+const (
+	DigitAcknowledge = "1"
+	DigitResolve     = "2"
+)
+
+type twimlResponse struct {
+	XMLName xml.Name `xml:"Response"`
+	Gather  *gather  `xml:"Gather,omitempty"`
+	Say     []string `xml:"Say"`
+}
+
+type gather struct {
+	NumDigits int    `xml:"numDigits,attr"`
+	Action    string `xml:"action,attr"`
+	Method    string `xml:"method,attr"`
+	Timeout   int    `xml:"timeout,attr"`
+	Say       string `xml:"Say"`
+}
+
+// callTwiML reads the alert title and, when action is set, gathers a digit
+// to acknowledge or resolve the alert group, which Twilio posts to action.
+func callTwiML(title, action string) string {
+	r := &twimlResponse{}
+
+	text := fmt.Sprintf("Alert from OnCall. %s.", title)
+	if action == "" {
+		r.Say = []string{text}
+		return render(r)
+	}
+
+	r.Gather = &gather{
+		NumDigits: 1,
+		Action:    action,
+		Method:    "POST",
+		Timeout:   10,
+		Say:       fmt.Sprintf("%s Press %s to acknowledge, or %s to resolve.", text, DigitAcknowledge, DigitResolve),
+	}
+	r.Say = []string{"No input received. Goodbye."}
+
+	return render(r)
+}
+
+// SayTwiML reads the text and hangs up.
+func SayTwiML(text string) string {
+	return render(&twimlResponse{Say: []string{text}})
+}
+
+func render(r *twimlResponse) string {
+	b, _ := xml.Marshal(r)
+	return xml.Header + string(b)
+}
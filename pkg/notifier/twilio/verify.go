@@ -0,0 +1,43 @@
+package twilio
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidSignature = errors.New("invalid Twilio signature")
+
+// Verify checks the X-Twilio-Signature of a callback, which signs the URL
+// Twilio requested followed by the sorted form parameters with the auth
+// token.
+func Verify(authToken, requestURL string, form url.Values, signature string) error {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(requestURL)
+	for _, k := range keys {
+		for _, v := range form[k] {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+
+	expected, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package twilio
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	requestURL := "https://oncall.example.com/api/v1/twilio/gather?alert_group_id=g1"
+	form := url.Values{"Digits": {"1"}, "CallSid": {"CA123"}, "From": {"+15550100"}}
+
+	// Twilio signs the URL followed by the form parameters sorted by name.
+	mac := hmac.New(sha1.New, []byte("token"))
+	mac.Write([]byte(requestURL + "CallSidCA123Digits1From+15550100"))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	tampered := url.Values{"Digits": {"2"}, "CallSid": {"CA123"}, "From": {"+15550100"}}
+
+	tests := []struct {
+		name      string
+		authToken string
+		url       string
+		form      url.Values
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid signature", authToken: "token", url: requestURL, form: form, signature: signature},
+		{name: "tampered body", authToken: "token", url: requestURL, form: tampered, signature: signature, wantErr: true},
+		{name: "other URL", authToken: "token", url: requestURL + "&x=1", form: form, signature: signature, wantErr: true},
+		{name: "wrong auth token", authToken: "other", url: requestURL, form: form, signature: signature, wantErr: true},
+		{name: "no signature", authToken: "token", url: requestURL, form: form, wantErr: true},
+		{name: "malformed signature", authToken: "token", url: requestURL, form: form, signature: "%%%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.authToken, tt.url, tt.form, tt.signature)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
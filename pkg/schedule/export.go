@@ -0,0 +1,114 @@
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CreateExportToken issues a token for the calendar feed of the schedule or,
+// when scheduleID is empty, of the user. Any previous token for the same
+// feed stops working.
+func (s *Service) CreateExportToken(ctx context.Context, scheduleID, userID string) (*ExportToken, error) {
+	if scheduleID != "" {
+		if _, err := s.store.Get(ctx, scheduleID); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := newExportToken()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ExportToken{
+		Hash:       hashExportToken(token),
+		ScheduleID: scheduleID,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.store.SaveExportToken(ctx, t); err != nil {
+		return nil, err
+	}
+
+	t.Token = token
+
+	return t, nil
+}
+
+func (s *Service) RevokeExportToken(ctx context.Context, scheduleID, userID string) error {
+	return s.store.DeleteExportToken(ctx, scheduleID, userID)
+}
+
+// AuthenticateExport checks that the token grants access to the feed of the
+// schedule or, when scheduleID is empty, of the user.
+func (s *Service) AuthenticateExport(ctx context.Context, token, scheduleID, userID string) error {
+	if token == "" {
+		return ErrExportTokenNotFound
+	}
+
+	t, err := s.store.GetExportToken(ctx, hashExportToken(token))
+	if err != nil {
+		return err
+	}
+
+	if t.ScheduleID != scheduleID || t.UserID != userID {
+		return ErrExportTokenNotFound
+	}
+
+	return nil
+}
+
+// UserShifts returns the final shifts of every schedule in which the user is
+// on call.
+func (s *Service) UserShifts(ctx context.Context, userID string, from, to time.Time) ([]*Shift, error) {
+	schedules, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Shift
+	for _, sched := range schedules {
+		shifts, err := sched.FinalShifts(from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, shift := range shifts {
+			if slices.Contains(shift.UserIDs, userID) {
+				result = append(result, shift)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+
+	return result, nil
+}
+
+func newExportToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate export token: %w", err)
+	}
+
+	return strings.ToLower(base32.StdEncoding.EncodeToString(b)), nil
+}
+
+// hashExportToken returns the hash under which the token is stored. Tokens are
+// random, so a plain SHA-256 is enough to keep a copy of the database from
+// granting access to the feeds.
+func hashExportToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
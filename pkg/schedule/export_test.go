@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+func TestExportToken(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewSQLStore(sqlstore.InitTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+	s := NewService(store, nil, nil)
+
+	first, err := s.CreateExportToken(ctx, "", "alice")
+	if err != nil {
+		t.Fatalf("CreateExportToken() error = %v", err)
+	}
+
+	stored, err := store.GetExportToken(ctx, hashExportToken(first.Token))
+	if err != nil {
+		t.Fatalf("GetExportToken() error = %v", err)
+	}
+	if stored.Token != "" {
+		t.Errorf("stored token %q, want only its hash", stored.Token)
+	}
+
+	second, err := s.CreateExportToken(ctx, "", "alice")
+	if err != nil {
+		t.Fatalf("CreateExportToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		userID  string
+		wantErr bool
+	}{
+		{name: "current token", token: second.Token, userID: "alice"},
+		{name: "replaced token", token: first.Token, userID: "alice", wantErr: true},
+		{name: "hash of the token", token: hashExportToken(second.Token), userID: "alice", wantErr: true},
+		{name: "other user", token: second.Token, userID: "bob", wantErr: true},
+		{name: "no token", userID: "alice", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.AuthenticateExport(ctx, tt.token, "", tt.userID)
+			if tt.wantErr {
+				if !errors.Is(err, ErrExportTokenNotFound) {
+					t.Errorf("AuthenticateExport() error = %v, want %v", err, ErrExportTokenNotFound)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("AuthenticateExport() error = %v", err)
+			}
+		})
+	}
+
+	if err := s.RevokeExportToken(ctx, "", "alice"); err != nil {
+		t.Fatalf("RevokeExportToken() error = %v", err)
+	}
+	if err := s.AuthenticateExport(ctx, second.Token, "", "alice"); !errors.Is(err, ErrExportTokenNotFound) {
+		t.Errorf("AuthenticateExport() of a revoked token error = %v, want %v", err, ErrExportTokenNotFound)
+	}
+}
@@ -0,0 +1,274 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/ical"
+)
+
+// maxICalSize limits the size of fetched and uploaded calendars.
+const maxICalSize = 10 << 20
+
+var summaryPriority = regexp.MustCompile(`^\s*\[L(\d+)\]`)
+
+// parseSummary reads the layer priority and the names of the users on call
+// in an event summary such as "[L2] alice, bob@example.com".
+func parseSummary(summary string) (int, []string) {
+	priority := 0
+	if m := summaryPriority.FindStringSubmatch(summary); m != nil {
+		priority, _ = strconv.Atoi(m[1])
+		summary = summary[len(m[0]):]
+	}
+
+	var names []string
+	for _, f := range strings.FieldsFunc(summary, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		if !slices.Contains(names, f) {
+			names = append(names, f)
+		}
+	}
+
+	return priority, names
+}
+
+// eventUsers returns the layer priority of the event and the IDs of the
+// users its summary names.
+func (s *Schedule) eventUsers(e *ical.Event) (int, []string) {
+	priority, names := parseSummary(e.Summary)
+
+	var userIDs []string
+	for _, name := range names {
+		if id, ok := s.ICalUsers[strings.ToLower(name)]; ok && !slices.Contains(userIDs, id) {
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	return priority, userIDs
+}
+
+// resolveICalUsers looks up the users named by the events by username or
+// email address, and returns the IDs of those found by lower case name along
+// with the names of no user.
+func (s *Service) resolveICalUsers(ctx context.Context, events []*ical.Event) (map[string]string, []string, error) {
+	users, err := s.users.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := make(map[string]string)
+	for _, u := range users {
+		known[strings.ToLower(u.Username)] = u.ID
+		if u.Email != "" {
+			if _, ok := known[strings.ToLower(u.Email)]; !ok {
+				known[strings.ToLower(u.Email)] = u.ID
+			}
+		}
+	}
+
+	resolved := make(map[string]string)
+	var unknown []string
+	for _, e := range events {
+		_, names := parseSummary(e.Summary)
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if id, ok := known[key]; ok {
+				resolved[key] = id
+			} else if !slices.Contains(unknown, name) {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+
+	return resolved, unknown, nil
+}
+
+// ImportICal replaces the events of an iCal schedule with those read from
+// data. Events which cannot be read are skipped and the problem is recorded
+// on the schedule. When nothing could be read the previous events are kept.
+func (s *Service) ImportICal(ctx context.Context, id string, data io.Reader) (*Schedule, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sched, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sched.Type != TypeICal {
+		return nil, fmt.Errorf("%w: only iCal schedules can import calendars", ErrInvalidSchedule)
+	}
+
+	return sched, s.importICal(ctx, sched, data)
+}
+
+// SyncICal fetches the calendar of an iCal schedule from its URL.
+func (s *Service) SyncICal(ctx context.Context, id string) (*Schedule, error) {
+	sched, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sched.Type != TypeICal || sched.ICalURL == "" {
+		return nil, fmt.Errorf("%w: schedule has no calendar URL", ErrInvalidSchedule)
+	}
+
+	data, fetchErr := s.fetchICal(ctx, sched.ICalURL)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Read the schedule again as it may have changed during the fetch.
+	sched, err = s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if fetchErr != nil {
+		return sched, s.recordICal(ctx, sched, sched.ICalEvents, fetchErr)
+	}
+
+	return sched, s.importICal(ctx, sched, bytes.NewReader(data))
+}
+
+// SyncICalSchedules fetches the calendars of all iCal schedules with a URL.
+func (s *Service) SyncICalSchedules(ctx context.Context) error {
+	schedules, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, sched := range schedules {
+		if sched.Type != TypeICal || sched.ICalURL == "" {
+			continue
+		}
+
+		if _, err := s.SyncICal(ctx, sched.ID); err != nil && !errors.Is(err, ErrInvalidSchedule) {
+			fmt.Printf("Failed to sync calendar of schedule %s: %s\n", sched.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) importICal(ctx context.Context, sched *Schedule, data io.Reader) error {
+	loc, err := time.LoadLocation(sched.TimeZone)
+	if err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, sched.TimeZone)
+	}
+
+	events, parseErr := ical.Parse(io.LimitReader(data, maxICalSize), loc)
+
+	// Nothing could be read, keep the events of the last calendar.
+	failed := parseErr != nil && events == nil
+	if failed {
+		events = sched.ICalEvents
+	}
+
+	if err := s.recordICal(ctx, sched, events, parseErr); err != nil {
+		return err
+	}
+
+	if failed {
+		return fmt.Errorf("%w: %s", ErrInvalidSchedule, parseErr)
+	}
+
+	return nil
+}
+
+func (s *Service) recordICal(ctx context.Context, sched *Schedule, events []*ical.Event, syncErr error) error {
+	now := time.Now()
+
+	users, unknown, err := s.resolveICalUsers(ctx, events)
+	if err != nil {
+		return err
+	}
+
+	sched.ICalEvents = events
+	sched.ICalUsers = users
+	sched.ICalSyncedAt = &now
+
+	var problems []string
+	if syncErr != nil {
+		problems = append(problems, syncErr.Error())
+	}
+	if len(unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("unknown users %s", strings.Join(unknown, ", ")))
+	}
+	sched.ICalError = strings.Join(problems, "; ")
+
+	return s.store.Update(ctx, sched)
+}
+
+func (s *Service) fetchICal(ctx context.Context, rawURL string) ([]byte, error) {
+	// webcal is the conventional scheme for subscribing to calendars over
+	// HTTPS.
+	if rest, ok := strings.CutPrefix(rawURL, "webcal://"); ok {
+		rawURL = "https://" + rest
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch calendar: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxICalSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+
+	return data, nil
+}
+
+// ICalSyncer periodically fetches the calendars of iCal schedules.
+type ICalSyncer struct {
+	schedules *Service
+	interval  time.Duration
+}
+
+func NewICalSyncer(schedules *Service, interval time.Duration) *ICalSyncer {
+	return &ICalSyncer{
+		schedules: schedules,
+		interval:  interval,
+	}
+}
+
+func (c *ICalSyncer) Run(ctx context.Context) error {
+	if err := c.schedules.SyncICalSchedules(ctx); err != nil {
+		fmt.Printf("Failed to sync schedule calendars: %s\n", err)
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.schedules.SyncICalSchedules(ctx); err != nil {
+				fmt.Printf("Failed to sync schedule calendars: %s\n", err)
+			}
+		}
+	}
+}
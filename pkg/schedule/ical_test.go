@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/user"
+)
+
+func TestImportICalResolvesUsers(t *testing.T) {
+	ctx := context.Background()
+	db := sqlstore.InitTestDB(t)
+
+	userStore, err := user.NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("user.NewSQLStore() error = %v", err)
+	}
+	users := user.NewService(userStore, nil, nil)
+
+	alice := &user.User{Username: "alice", TimeZone: "UTC", Role: user.RoleEditor}
+	bob := &user.User{Username: "bob", Email: "Bob@example.com", TimeZone: "UTC", Role: user.RoleEditor}
+	for _, u := range []*user.User{alice, bob} {
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	store, err := NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+	s := NewService(store, users, nil)
+
+	sched := &Schedule{Name: "ical", Type: TypeICal, TimeZone: "UTC"}
+	if err := s.Create(ctx, sched); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:e1",
+		"SUMMARY:Alice, bob@example.com, mallory",
+		"DTSTART:20250106T090000Z",
+		"DTEND:20250106T170000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	sched, err = s.ImportICal(ctx, sched.ID, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ImportICal() error = %v", err)
+	}
+
+	if !strings.Contains(sched.ICalError, "mallory") {
+		t.Errorf("ICalError = %q, want the unknown user mallory", sched.ICalError)
+	}
+
+	// Renaming a user keeps them on call.
+	alice.Username = "alicia"
+	if err := users.Update(ctx, alice); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	sched, err = s.Get(ctx, sched.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	got, err := sched.OnCallAt(utc(6, 12))
+	if err != nil {
+		t.Fatalf("OnCallAt() error = %v", err)
+	}
+
+	want := []string{alice.ID, bob.ID}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("OnCallAt() = %q, want %q", got, want)
+	}
+}
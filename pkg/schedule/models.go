@@ -0,0 +1,220 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/ical"
+)
+
+type Type string
+
+const (
+	// TypeWeb schedules are made up of rotations managed through the API.
+	TypeWeb Type = "web"
+	// TypeICal schedules take their shifts from iCalendar events, either
+	// fetched from ICalURL or uploaded.
+	TypeICal Type = "ical"
+)
+
+type Frequency string
+
+const (
+	FrequencyHourly  Frequency = "hourly"
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrOverrideNotFound = errors.New("schedule override not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+	// ErrExportTokenNotFound is returned for unknown calendar export tokens.
+	ErrExportTokenNotFound = errors.New("export token not found")
+)
+
+type Schedule struct {
+	ID     string
+	Name   string
+	TeamID string
+	Type   Type
+	// TimeZone is the IANA time zone used by rotations which do not set
+	// their own.
+	TimeZone  string
+	Layers    []*Layer
+	Overrides []*Override
+	// ChatChannel is told whenever the users on call change.
+	ChatChannel string
+	// ICalURL is fetched periodically for iCal schedules. Without it the
+	// calendar has to be uploaded.
+	ICalURL string
+	// ICalEvents are the events of the last calendar read successfully. The
+	// summary of each event names the users on call by username or email
+	// address, separated by commas or spaces, optionally prefixed with a
+	// layer priority such as [L2].
+	ICalEvents []*ical.Event
+	// ICalUsers maps the names in the summaries of ICalEvents, usernames or
+	// email addresses, to the IDs of the users they named when the calendar
+	// was read. Names of no user are left out.
+	ICalUsers map[string]string
+	// ICalError describes why the last calendar could not be read in full.
+	ICalError    string
+	ICalSyncedAt *time.Time
+	// ReportedGaps are the upcoming gaps the GapChecker already reported,
+	// starting when they were first found.
+	ReportedGaps []*Period
+}
+
+// Layer groups rotations. Where the shifts of several layers overlap only the
+// layer with the highest priority is on call.
+type Layer struct {
+	Priority  int
+	Rotations []*Rotation
+}
+
+// Rotation hands off between its participants in turn, the first shift
+// starting at Start and a new one every Interval periods of Frequency.
+type Rotation struct {
+	ID        string
+	Name      string
+	Frequency Frequency
+	Interval  int
+	Start     time.Time
+	// Duration is the length of each shift, zero meaning the whole period
+	// until the next handoff.
+	Duration time.Duration
+	// Until ends the rotation, no shifts start after it.
+	Until        *time.Time
+	TimeZone     string
+	Participants []string
+	// ByDay restricts the rotation to the given days of the week. For daily
+	// rotations days not listed are skipped entirely, otherwise shifts are
+	// cut down to the listed days.
+	ByDay []time.Weekday
+}
+
+// Override puts users on call for a period ahead of any layer.
+type Override struct {
+	ID      string
+	UserIDs []string
+	Start   time.Time
+	End     time.Time
+}
+
+// ExportToken grants access to the calendar feed of either a schedule or a
+// user, for calendar apps which cannot send other credentials. Only the hash
+// of the token is stored, so Token is only set when the token is created.
+type ExportToken struct {
+	Token      string
+	Hash       string
+	ScheduleID string
+	UserID     string
+	CreatedAt  time.Time
+}
+
+// Shift is a period during which users are on call.
+type Shift struct {
+	ScheduleID string
+	UserIDs    []string
+	Start      time.Time
+	End        time.Time
+	// RotationID, OverrideID or EventID identify where the shift came from.
+	RotationID string
+	OverrideID string
+	EventID    string
+	// Priority is the priority of the layer of the shift, overrides have no
+	// priority.
+	Priority int
+}
+
+func (s *Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSchedule)
+	}
+
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, s.TimeZone)
+	}
+
+	switch s.Type {
+	case TypeWeb:
+		if s.ICalURL != "" {
+			return fmt.Errorf("%w: only iCal schedules have a calendar URL", ErrInvalidSchedule)
+		}
+	case TypeICal:
+		if s.ICalURL != "" {
+			u, err := url.Parse(s.ICalURL)
+			if err != nil || !slices.Contains([]string{"http", "https", "webcal"}, u.Scheme) || u.Host == "" {
+				return fmt.Errorf("%w: invalid calendar URL %q", ErrInvalidSchedule, s.ICalURL)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown schedule type %q", ErrInvalidSchedule, s.Type)
+	}
+
+	for _, layer := range s.Layers {
+		for _, r := range layer.Rotations {
+			if err := r.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, o := range s.Overrides {
+		if err := o.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Rotation) Validate() error {
+	if !slices.Contains([]Frequency{FrequencyHourly, FrequencyDaily, FrequencyWeekly, FrequencyMonthly}, r.Frequency) {
+		return fmt.Errorf("%w: rotation %q has unknown frequency %q", ErrInvalidSchedule, r.Name, r.Frequency)
+	}
+
+	if r.Interval < 1 {
+		return fmt.Errorf("%w: rotation %q interval must be at least 1", ErrInvalidSchedule, r.Name)
+	}
+
+	if r.Start.IsZero() {
+		return fmt.Errorf("%w: rotation %q start is required", ErrInvalidSchedule, r.Name)
+	}
+
+	if r.Duration < 0 {
+		return fmt.Errorf("%w: rotation %q shift duration must not be negative", ErrInvalidSchedule, r.Name)
+	}
+
+	if r.Until != nil && !r.Until.After(r.Start) {
+		return fmt.Errorf("%w: rotation %q must end after it starts", ErrInvalidSchedule, r.Name)
+	}
+
+	if len(r.Participants) == 0 {
+		return fmt.Errorf("%w: rotation %q needs at least one participant", ErrInvalidSchedule, r.Name)
+	}
+
+	if r.TimeZone != "" {
+		if _, err := time.LoadLocation(r.TimeZone); err != nil {
+			return fmt.Errorf("%w: rotation %q has unknown time zone %q", ErrInvalidSchedule, r.Name, r.TimeZone)
+		}
+	}
+
+	return nil
+}
+
+func (o *Override) Validate() error {
+	if len(o.UserIDs) == 0 {
+		return fmt.Errorf("%w: override needs at least one user", ErrInvalidSchedule)
+	}
+
+	if !o.End.After(o.Start) {
+		return fmt.Errorf("%w: override must end after it starts", ErrInvalidSchedule)
+	}
+
+	return nil
+}
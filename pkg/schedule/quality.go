@@ -0,0 +1,258 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// JobGapsDetected is published when new gaps are found in the upcoming
+// shifts of a schedule of a team, with the schedule ID and the start and end
+// of each new gap as arguments, so the team channel can be told about them.
+const JobGapsDetected worker.JobType = "schedule_gaps_detected"
+
+// Period is a span of time, such as a gap with nobody on call.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlap is a period during which the shifts of several rotations or
+// events of the winning layer coincide.
+type Overlap struct {
+	Start   time.Time
+	End     time.Time
+	UserIDs []string
+}
+
+// UserLoad is the time a user is on call.
+type UserLoad struct {
+	UserID string
+	OnCall time.Duration
+}
+
+// Quality reports on the shifts of a schedule over a range.
+type Quality struct {
+	From     time.Time
+	To       time.Time
+	Gaps     []*Period
+	Overlaps []*Overlap
+	// Load lists every participant with the time they are on call, most
+	// loaded first.
+	Load []*UserLoad
+	// Coverage is the fraction of the range during which someone is on
+	// call.
+	Coverage float64
+	// Balance is the ratio of the least to the most time on call among the
+	// users in Load, 1 when the load is shared evenly.
+	Balance float64
+	// Score rates the schedule from 0 to 100 by its coverage and balance.
+	Score int
+}
+
+// Quality looks for gaps, overlaps and an uneven load in the shifts of the
+// schedule over the range.
+func (s *Schedule) Quality(from, to time.Time) (*Quality, error) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, s.TimeZone)
+	}
+
+	q := &Quality{From: from.In(loc), To: to.In(loc)}
+
+	candidates := s.candidateShifts(from, to, loc)
+	boundaries := shiftBoundaries(candidates, from, to)
+
+	load := make(map[string]time.Duration)
+	for _, userID := range s.Participants() {
+		load[userID] = 0
+	}
+
+	var covered time.Duration
+	for i := 0; i+1 < len(boundaries); i++ {
+		start, end := boundaries[i].In(loc), boundaries[i+1].In(loc)
+		if !end.After(start) {
+			continue
+		}
+
+		winners := winningCandidates(candidates, start)
+		if len(winners) == 0 {
+			if n := len(q.Gaps); n > 0 && q.Gaps[n-1].End.Equal(start) {
+				q.Gaps[n-1].End = end
+			} else {
+				q.Gaps = append(q.Gaps, &Period{Start: start, End: end})
+			}
+			continue
+		}
+
+		covered += end.Sub(start)
+
+		shift := winningShift(candidates, start)
+		for _, userID := range shift.UserIDs {
+			load[userID] += end.Sub(start)
+		}
+
+		// Several overrides at once are deliberate, only rotations and
+		// events clashing are reported.
+		if len(winners) > 1 && winners[0].OverrideID == "" {
+			if n := len(q.Overlaps); n > 0 && q.Overlaps[n-1].End.Equal(start) && slices.Equal(q.Overlaps[n-1].UserIDs, shift.UserIDs) {
+				q.Overlaps[n-1].End = end
+			} else {
+				q.Overlaps = append(q.Overlaps, &Overlap{Start: start, End: end, UserIDs: shift.UserIDs})
+			}
+		}
+	}
+
+	for userID, d := range load {
+		q.Load = append(q.Load, &UserLoad{UserID: userID, OnCall: d})
+	}
+
+	sort.Slice(q.Load, func(i, j int) bool {
+		if q.Load[i].OnCall != q.Load[j].OnCall {
+			return q.Load[i].OnCall > q.Load[j].OnCall
+		}
+		return q.Load[i].UserID < q.Load[j].UserID
+	})
+
+	q.Coverage = float64(covered) / float64(to.Sub(from))
+
+	q.Balance = 1
+	if n := len(q.Load); n > 0 && q.Load[0].OnCall > 0 {
+		q.Balance = float64(q.Load[n-1].OnCall) / float64(q.Load[0].OnCall)
+	}
+
+	q.Score = int(math.Round(100 * (q.Coverage + q.Balance) / 2))
+
+	return q, nil
+}
+
+func (s *Service) Quality(ctx context.Context, id string, from, to time.Time) (*Quality, error) {
+	sched, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return sched.Quality(from, to)
+}
+
+// GapChecker periodically looks for gaps in the upcoming shifts of every
+// schedule and publishes JobGapsDetected for gaps it has not reported yet,
+// which it records in the ReportedGaps of the schedule.
+type GapChecker struct {
+	schedules *Service
+	worker    worker.Worker
+	interval  time.Duration
+	ahead     time.Duration
+	now       func() time.Time
+}
+
+func NewGapChecker(schedules *Service, w worker.Worker, interval, ahead time.Duration) *GapChecker {
+	return &GapChecker{
+		schedules: schedules,
+		worker:    w,
+		interval:  interval,
+		ahead:     ahead,
+		now:       time.Now,
+	}
+}
+
+// recordReportedGaps stores the gaps reported for the schedule.
+func (s *Service) recordReportedGaps(ctx context.Context, id string, gaps []*Period) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sched, err := s.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	sched.ReportedGaps = gaps
+
+	return s.store.Update(ctx, sched)
+}
+
+func (c *GapChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.check(ctx); err != nil {
+			fmt.Printf("Failed to check schedules for gaps: %s\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *GapChecker) check(ctx context.Context) error {
+	schedules, err := c.schedules.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+
+	for _, sched := range schedules {
+		// Nobody can be told about gaps in schedules without a team.
+		if sched.TeamID == "" {
+			continue
+		}
+
+		q, err := sched.Quality(now, now.Add(c.ahead))
+		if err != nil {
+			fmt.Printf("Failed to check schedule %s for gaps: %s\n", sched.ID, err)
+			continue
+		}
+
+		previous := sched.ReportedGaps
+		current := make([]*Period, 0, len(q.Gaps))
+		args := []string{sched.ID}
+		for _, gap := range q.Gaps {
+			// Gaps are told apart by their start. The checked range moves on
+			// with every check and cuts a gap under way at its start, so such
+			// a gap keeps the start of the reported gap it continues.
+			start := gap.Start
+			if gap.Start.Equal(q.From) {
+				for _, p := range previous {
+					if !p.Start.After(q.From) && p.End.After(q.From) {
+						start = p.Start
+						break
+					}
+				}
+			}
+
+			current = append(current, &Period{Start: start, End: gap.End})
+			if !slices.ContainsFunc(previous, func(p *Period) bool { return p.Start.Equal(start) }) {
+				args = append(args, gap.Start.UTC().Format(time.RFC3339), gap.End.UTC().Format(time.RFC3339))
+			}
+		}
+
+		if len(args) > 1 {
+			// Gaps which could not be published are reported again by the
+			// next check.
+			if err := c.worker.Enqueue(ctx, JobGapsDetected, args...); err != nil {
+				fmt.Printf("Failed to publish gaps of schedule %s: %s\n", sched.ID, err)
+				continue
+			}
+		}
+
+		if slices.EqualFunc(previous, current, func(a, b *Period) bool { return a.Start.Equal(b.Start) && a.End.Equal(b.End) }) {
+			continue
+		}
+
+		if err := c.schedules.recordReportedGaps(ctx, sched.ID, current); err != nil {
+			fmt.Printf("Failed to record the gaps of schedule %s: %s\n", sched.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// gapWorker keeps the arguments of the JobGapsDetected published.
+type gapWorker struct {
+	gaps [][]string
+}
+
+func (w *gapWorker) Enqueue(ctx context.Context, t worker.JobType, args ...string) error {
+	if t == JobGapsDetected {
+		w.gaps = append(w.gaps, args[1:])
+	}
+	return nil
+}
+
+func (w *gapWorker) EnqueueIn(ctx context.Context, delay time.Duration, t worker.JobType, args ...string) error {
+	return w.Enqueue(ctx, t, args...)
+}
+
+func (w *gapWorker) RegisterHandler(worker.JobType, worker.JobHandler, any) {}
+
+func (w *gapWorker) Stop(ctx context.Context) {}
+
+func TestGapCheckerRemembersReportedGaps(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewSQLStore(sqlstore.InitTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+	s := NewService(store, nil, nil)
+
+	sched := &Schedule{Name: "primary", TeamID: "t1", Type: TypeWeb, TimeZone: "UTC"}
+	if err := s.Create(ctx, sched); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	start := time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+	format := func(t time.Time) string { return t.Format(time.RFC3339) }
+
+	w := &gapWorker{}
+
+	// Every check is made by a new checker, as after a restart.
+	check := func(now time.Time) [][]string {
+		t.Helper()
+
+		w.gaps = nil
+		c := NewGapChecker(s, w, time.Minute, 24*time.Hour)
+		c.now = func() time.Time { return now }
+		if err := c.check(ctx); err != nil {
+			t.Fatalf("check() error = %v", err)
+		}
+
+		return w.gaps
+	}
+
+	if got, want := check(start), [][]string{{format(start), format(start.Add(24 * time.Hour))}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("first check reported %q, want %q", got, want)
+	}
+
+	// The gap under way continues the reported gap.
+	if got := check(start.Add(time.Hour)); len(got) > 0 {
+		t.Errorf("second check reported %q, want nothing", got)
+	}
+
+	// An override splits the gap, the part after it being a new gap.
+	overrideEnd := start.Add(4 * time.Hour)
+	if err := s.AddOverride(ctx, sched.ID, &Override{UserIDs: []string{"alice"}, Start: start.Add(3 * time.Hour), End: overrideEnd}); err != nil {
+		t.Fatalf("AddOverride() error = %v", err)
+	}
+
+	now := start.Add(2 * time.Hour)
+	if got, want := check(now), [][]string{{format(overrideEnd), format(now.Add(24 * time.Hour))}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("check after the override reported %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,231 @@
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// FinalShifts returns who is on call over the range once layers and
+// overrides have been combined. Overrides take precedence over all layers,
+// and where layers overlap only the shifts of the layer with the highest
+// priority count. Shifts are cut down to the range, and periods where nobody
+// is on call are left out.
+func (s *Schedule) FinalShifts(from, to time.Time) ([]*Shift, error) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, s.TimeZone)
+	}
+
+	candidates := s.candidateShifts(from, to, loc)
+	boundaries := shiftBoundaries(candidates, from, to)
+
+	var final []*Shift
+	for i := 0; i+1 < len(boundaries); i++ {
+		start, end := boundaries[i], boundaries[i+1]
+		if !end.After(start) {
+			continue
+		}
+
+		shift := winningShift(candidates, start)
+		if shift == nil {
+			continue
+		}
+
+		shift.ScheduleID = s.ID
+		shift.Start = start.In(loc)
+		shift.End = end.In(loc)
+
+		if n := len(final); n > 0 && sameShift(final[n-1], shift) && final[n-1].End.Equal(start) {
+			final[n-1].End = end.In(loc)
+			continue
+		}
+
+		final = append(final, shift)
+	}
+
+	return final, nil
+}
+
+// OnCallAt returns the users on call at the instant.
+func (s *Schedule) OnCallAt(at time.Time) ([]string, error) {
+	shifts, err := s.FinalShifts(at, at.Add(time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shifts) == 0 {
+		return []string{}, nil
+	}
+
+	return shifts[0].UserIDs, nil
+}
+
+// Participants returns everyone taking part in the rotations or calendar
+// events of the schedule, sorted.
+func (s *Schedule) Participants() []string {
+	var users []string
+	add := func(userIDs []string) {
+		for _, userID := range userIDs {
+			if !slices.Contains(users, userID) {
+				users = append(users, userID)
+			}
+		}
+	}
+
+	for _, layer := range s.Layers {
+		for _, r := range layer.Rotations {
+			add(r.Participants)
+		}
+	}
+
+	for _, e := range s.ICalEvents {
+		_, userIDs := s.eventUsers(e)
+		add(userIDs)
+	}
+
+	sort.Strings(users)
+
+	return users
+}
+
+// candidateShifts returns the shifts of every layer and override which
+// overlap the range.
+func (s *Schedule) candidateShifts(from, to time.Time, loc *time.Location) []*Shift {
+	var candidates []*Shift
+
+	for _, layer := range s.Layers {
+		for _, r := range layer.Rotations {
+			for _, shift := range r.Shifts(from, to, loc) {
+				shift.Priority = layer.Priority
+				candidates = append(candidates, shift)
+			}
+		}
+	}
+
+	for _, e := range s.ICalEvents {
+		priority, userIDs := s.eventUsers(e)
+		if len(userIDs) == 0 {
+			continue
+		}
+
+		for _, occ := range e.Occurrences(from, to) {
+			candidates = append(candidates, &Shift{
+				UserIDs:  slices.Clone(userIDs),
+				Start:    occ.Start,
+				End:      occ.End,
+				EventID:  e.UID,
+				Priority: priority,
+			})
+		}
+	}
+
+	for _, o := range s.Overrides {
+		if o.End.After(from) && o.Start.Before(to) {
+			candidates = append(candidates, &Shift{
+				UserIDs:    slices.Clone(o.UserIDs),
+				Start:      o.Start,
+				End:        o.End,
+				OverrideID: o.ID,
+			})
+		}
+	}
+
+	return candidates
+}
+
+// shiftBoundaries returns the instants within the range at which any of the
+// candidates start or end, along with the ends of the range, in order.
+func shiftBoundaries(candidates []*Shift, from, to time.Time) []time.Time {
+	boundaries := []time.Time{from, to}
+	for _, c := range candidates {
+		if c.Start.After(from) && c.Start.Before(to) {
+			boundaries = append(boundaries, c.Start)
+		}
+		if c.End.After(from) && c.End.Before(to) {
+			boundaries = append(boundaries, c.End)
+		}
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Before(boundaries[j])
+	})
+
+	return boundaries
+}
+
+// winningShift combines the candidates covering the instant into the shift
+// that is on call then, or nil when nobody is.
+func winningShift(candidates []*Shift, at time.Time) *Shift {
+	winners := winningCandidates(candidates, at)
+	if len(winners) == 0 {
+		return nil
+	}
+
+	shift := &Shift{Priority: winners[0].Priority}
+	for _, w := range winners {
+		for _, userID := range w.UserIDs {
+			if !slices.Contains(shift.UserIDs, userID) {
+				shift.UserIDs = append(shift.UserIDs, userID)
+			}
+		}
+	}
+	sort.Strings(shift.UserIDs)
+
+	// Only attribute the shift to a source when there is a single one.
+	if len(winners) == 1 {
+		shift.RotationID = winners[0].RotationID
+		shift.OverrideID = winners[0].OverrideID
+		shift.EventID = winners[0].EventID
+	}
+
+	return shift
+}
+
+// winningCandidates returns the candidates covering the instant which take
+// precedence, the overrides if there are any, otherwise those of the layer
+// with the highest priority.
+func winningCandidates(candidates []*Shift, at time.Time) []*Shift {
+	var covering []*Shift
+	for _, c := range candidates {
+		if !c.Start.After(at) && c.End.After(at) {
+			covering = append(covering, c)
+		}
+	}
+
+	if len(covering) == 0 {
+		return nil
+	}
+
+	var overrides []*Shift
+	for _, c := range covering {
+		if c.OverrideID != "" {
+			overrides = append(overrides, c)
+		}
+	}
+
+	winners := overrides
+	if len(winners) == 0 {
+		top := covering[0].Priority
+		for _, c := range covering {
+			top = max(top, c.Priority)
+		}
+
+		for _, c := range covering {
+			if c.Priority == top {
+				winners = append(winners, c)
+			}
+		}
+	}
+
+	return winners
+}
+
+func sameShift(a, b *Shift) bool {
+	return slices.Equal(a.UserIDs, b.UserIDs) &&
+		a.RotationID == b.RotationID &&
+		a.OverrideID == b.OverrideID &&
+		a.EventID == b.EventID &&
+		a.Priority == b.Priority
+}
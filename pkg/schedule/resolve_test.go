@@ -0,0 +1,184 @@
+package schedule
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/ical"
+)
+
+func TestFinalShifts(t *testing.T) {
+	allDay := &Rotation{ID: "r1", Frequency: FrequencyDaily, Interval: 1, Start: utc(6, 0), Participants: []string{"a"}}
+	dayTime := &Rotation{ID: "r2", Frequency: FrequencyDaily, Interval: 1, Start: utc(6, 9), Duration: 8 * time.Hour, Participants: []string{"b"}}
+	lunch := &Rotation{ID: "r3", Frequency: FrequencyDaily, Interval: 1, Start: utc(6, 12), Duration: 2 * time.Hour, Participants: []string{"a"}}
+
+	events, err := ical.Parse(strings.NewReader(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:e1",
+		"SUMMARY:[L2] c",
+		"DTSTART:20250106T090000Z",
+		"DTEND:20250106T170000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     []string
+	}{
+		{
+			name: "higher layer wins and lower layer fills the gaps",
+			schedule: Schedule{
+				Layers: []*Layer{
+					{Priority: 1, Rotations: []*Rotation{allDay}},
+					{Priority: 2, Rotations: []*Rotation{dayTime}},
+				},
+			},
+			want: []string{
+				"a 2025-01-06 00:00 UTC/2025-01-06 09:00 UTC r1",
+				"b 2025-01-06 09:00 UTC/2025-01-06 17:00 UTC r2",
+				"a 2025-01-06 17:00 UTC/2025-01-07 09:00 UTC r1",
+				"b 2025-01-07 09:00 UTC/2025-01-07 17:00 UTC r2",
+				"a 2025-01-07 17:00 UTC/2025-01-08 00:00 UTC r1",
+			},
+		},
+		{
+			name: "override takes precedence over layers",
+			schedule: Schedule{
+				Layers: []*Layer{
+					{Priority: 2, Rotations: []*Rotation{dayTime}},
+				},
+				Overrides: []*Override{
+					{ID: "o1", UserIDs: []string{"c"}, Start: utc(7, 12), End: utc(7, 14)},
+				},
+			},
+			want: []string{
+				"b 2025-01-06 09:00 UTC/2025-01-06 17:00 UTC r2",
+				"b 2025-01-07 09:00 UTC/2025-01-07 12:00 UTC r2",
+				"c 2025-01-07 12:00 UTC/2025-01-07 14:00 UTC o1",
+				"b 2025-01-07 14:00 UTC/2025-01-07 17:00 UTC r2",
+			},
+		},
+		{
+			name: "overlapping overrides share the shift",
+			schedule: Schedule{
+				Overrides: []*Override{
+					{ID: "o1", UserIDs: []string{"c"}, Start: utc(6, 10), End: utc(6, 14)},
+					{ID: "o2", UserIDs: []string{"a"}, Start: utc(6, 12), End: utc(6, 16)},
+				},
+			},
+			want: []string{
+				"c 2025-01-06 10:00 UTC/2025-01-06 12:00 UTC o1",
+				"a,c 2025-01-06 12:00 UTC/2025-01-06 14:00 UTC ",
+				"a 2025-01-06 14:00 UTC/2025-01-06 16:00 UTC o2",
+			},
+		},
+		{
+			name: "rotations of the same layer share the shift",
+			schedule: Schedule{
+				Layers: []*Layer{
+					{Priority: 1, Rotations: []*Rotation{dayTime, lunch}},
+				},
+			},
+			want: []string{
+				"b 2025-01-06 09:00 UTC/2025-01-06 12:00 UTC r2",
+				"a,b 2025-01-06 12:00 UTC/2025-01-06 14:00 UTC ",
+				"b 2025-01-06 14:00 UTC/2025-01-06 17:00 UTC r2",
+				"b 2025-01-07 09:00 UTC/2025-01-07 12:00 UTC r2",
+				"a,b 2025-01-07 12:00 UTC/2025-01-07 14:00 UTC ",
+				"b 2025-01-07 14:00 UTC/2025-01-07 17:00 UTC r2",
+			},
+		},
+		{
+			name: "calendar event on a higher layer",
+			schedule: Schedule{
+				Layers: []*Layer{
+					{Priority: 1, Rotations: []*Rotation{allDay}},
+				},
+				ICalEvents: events,
+				ICalUsers:  map[string]string{"c": "c"},
+			},
+			want: []string{
+				"a 2025-01-06 00:00 UTC/2025-01-06 09:00 UTC r1",
+				"c 2025-01-06 09:00 UTC/2025-01-06 17:00 UTC e1",
+				"a 2025-01-06 17:00 UTC/2025-01-08 00:00 UTC r1",
+			},
+		},
+		{
+			name:     "nobody on call",
+			schedule: Schedule{},
+			want:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.schedule
+			s.TimeZone = "UTC"
+
+			shifts, err := s.FinalShifts(utc(6, 0), utc(8, 0))
+			if err != nil {
+				t.Fatalf("FinalShifts() error = %v", err)
+			}
+
+			if got := describe(shifts); !slices.Equal(got, tt.want) {
+				t.Errorf("FinalShifts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalShiftsUnknownTimeZone(t *testing.T) {
+	s := &Schedule{TimeZone: "Nowhere/Special"}
+
+	if _, err := s.FinalShifts(utc(6, 0), utc(8, 0)); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("FinalShifts() error = %v, want %v", err, ErrInvalidSchedule)
+	}
+}
+
+func TestOnCallAt(t *testing.T) {
+	s := &Schedule{
+		TimeZone: "UTC",
+		Layers: []*Layer{
+			{Priority: 1, Rotations: []*Rotation{
+				{ID: "r1", Frequency: FrequencyDaily, Interval: 1, Start: utc(6, 9), Duration: 8 * time.Hour, Participants: []string{"a", "b"}},
+			}},
+		},
+		Overrides: []*Override{
+			{ID: "o1", UserIDs: []string{"c"}, Start: utc(7, 12), End: utc(7, 14)},
+		},
+	}
+
+	tests := []struct {
+		at   time.Time
+		want []string
+	}{
+		{at: utc(6, 8), want: []string{}},
+		{at: utc(6, 9), want: []string{"a"}},
+		{at: utc(6, 17), want: []string{}},
+		{at: utc(7, 9), want: []string{"b"}},
+		{at: utc(7, 12), want: []string{"c"}},
+		{at: utc(7, 14), want: []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.at.Format(time.RFC3339), func(t *testing.T) {
+			got, err := s.OnCallAt(tt.at)
+			if err != nil {
+				t.Fatalf("OnCallAt() error = %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("OnCallAt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
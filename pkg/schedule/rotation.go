@@ -0,0 +1,170 @@
+package schedule
+
+import (
+	"slices"
+	"time"
+)
+
+// Shifts returns the shifts of the rotation which overlap the range, in
+// order. Shifts are not cut down to the range.
+func (r *Rotation) Shifts(from, to time.Time, fallback *time.Location) []*Shift {
+	if !to.After(from) || len(r.Participants) == 0 {
+		return nil
+	}
+
+	loc := fallback
+	if r.TimeZone != "" {
+		if l, err := time.LoadLocation(r.TimeZone); err == nil {
+			loc = l
+		}
+	}
+
+	start := r.Start.In(loc)
+	skipDays := r.Frequency == FrequencyDaily && len(r.ByDay) > 0
+
+	// Skip most of the periods before the range without generating them,
+	// leaving some slack as periods are not all of the same length.
+	k := 0
+	if from.After(start) {
+		k = max(0, int(from.Sub(start)/(r.longestPeriod()*time.Duration(r.Interval)))-2)
+	}
+
+	var shifts []*Shift
+	for ; ; k++ {
+		periodStart := r.periodStart(start, k)
+		if !periodStart.Before(to) || (r.Until != nil && !periodStart.Before(*r.Until)) {
+			break
+		}
+
+		end := r.periodStart(start, k+1)
+		if r.Duration > 0 {
+			end = periodStart.Add(r.Duration)
+		}
+
+		if r.Until != nil && end.After(*r.Until) {
+			end = *r.Until
+		}
+
+		if !end.After(from) {
+			continue
+		}
+
+		turn := k
+		if skipDays {
+			if !slices.Contains(r.ByDay, periodStart.Weekday()) {
+				continue
+			}
+			turn = r.occurrencesBefore(start, k)
+		}
+
+		userID := r.Participants[turn%len(r.Participants)]
+
+		segments := [][2]time.Time{{periodStart, end}}
+		if len(r.ByDay) > 0 && !skipDays {
+			segments = clipToDays(periodStart, end, loc, r.ByDay)
+		}
+
+		for _, seg := range segments {
+			if !seg[1].After(from) || !seg[0].Before(to) {
+				continue
+			}
+
+			shifts = append(shifts, &Shift{
+				UserIDs:    []string{userID},
+				Start:      seg[0],
+				End:        seg[1],
+				RotationID: r.ID,
+			})
+		}
+	}
+
+	return shifts
+}
+
+func (r *Rotation) periodStart(start time.Time, k int) time.Time {
+	n := k * r.Interval
+
+	switch r.Frequency {
+	case FrequencyHourly:
+		return start.Add(time.Duration(n) * time.Hour)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		return start.AddDate(0, n, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// longestPeriod is the longest a single period of the frequency can last.
+func (r *Rotation) longestPeriod() time.Duration {
+	switch r.Frequency {
+	case FrequencyHourly:
+		return time.Hour
+	case FrequencyWeekly:
+		return 7*24*time.Hour + time.Hour
+	case FrequencyMonthly:
+		return 31*24*time.Hour + time.Hour
+	default:
+		return 25 * time.Hour
+	}
+}
+
+// occurrencesBefore counts the periods before the k-th which start on one of
+// the days of a daily rotation restricted by ByDay. The weekday of the
+// periods repeats every seven periods, whatever the interval.
+func (r *Rotation) occurrencesBefore(start time.Time, k int) int {
+	matches := make([]bool, 7)
+	perCycle := 0
+	for j := 0; j < 7; j++ {
+		day := time.Weekday((int(start.Weekday()) + j*r.Interval) % 7)
+		matches[j] = slices.Contains(r.ByDay, day)
+		if matches[j] {
+			perCycle++
+		}
+	}
+
+	count := (k / 7) * perCycle
+	for j := 0; j < k%7; j++ {
+		if matches[j] {
+			count++
+		}
+	}
+
+	return count
+}
+
+// clipToDays cuts the range down to the parts which fall on the given days of
+// the week, merging parts on consecutive days.
+func clipToDays(start, end time.Time, loc *time.Location, days []time.Weekday) [][2]time.Time {
+	var segments [][2]time.Time
+
+	y, m, d := start.In(loc).Date()
+	dayStart := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	for dayStart.Before(end) {
+		next := dayStart.AddDate(0, 0, 1)
+
+		if slices.Contains(days, dayStart.Weekday()) {
+			segStart := dayStart
+			if start.After(segStart) {
+				segStart = start
+			}
+
+			segEnd := next
+			if end.Before(segEnd) {
+				segEnd = end
+			}
+
+			if n := len(segments); n > 0 && segments[n-1][1].Equal(segStart) {
+				segments[n-1][1] = segEnd
+			} else {
+				segments = append(segments, [2]time.Time{segStart, segEnd})
+			}
+		}
+
+		dayStart = next
+	}
+
+	return segments
+}
@@ -0,0 +1,214 @@
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// describe formats shifts as the users on call, the period and where the
+// shift came from, in the zone of each shift.
+func describe(shifts []*Shift) []string {
+	result := []string{}
+	for _, s := range shifts {
+		result = append(result, fmt.Sprintf("%s %s/%s %s",
+			strings.Join(s.UserIDs, ","),
+			s.Start.Format("2006-01-02 15:04 MST"),
+			s.End.Format("2006-01-02 15:04 MST"),
+			s.RotationID+s.OverrideID+s.EventID))
+	}
+
+	return result
+}
+
+func utc(d, h int) time.Time {
+	return time.Date(2025, time.January, d, h, 0, 0, 0, time.UTC)
+}
+
+func TestRotationShifts(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	until := utc(8, 12)
+
+	tests := []struct {
+		name     string
+		rotation Rotation
+		from, to time.Time
+		want     []string
+	}{
+		{
+			name: "daily handoff keeps the local time across DST",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        time.Date(2025, time.March, 28, 9, 0, 0, 0, berlin),
+				TimeZone:     "Europe/Berlin",
+				Participants: []string{"a", "b", "c"},
+			},
+			from: time.Date(2025, time.March, 28, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+			want: []string{
+				"a 2025-03-28 09:00 CET/2025-03-29 09:00 CET r",
+				"b 2025-03-29 09:00 CET/2025-03-30 09:00 CEST r",
+				"c 2025-03-30 09:00 CEST/2025-03-31 09:00 CEST r",
+			},
+		},
+		{
+			name: "hourly handoff counts elapsed hours across DST",
+			rotation: Rotation{
+				Frequency:    FrequencyHourly,
+				Start:        time.Date(2025, time.March, 30, 1, 0, 0, 0, berlin),
+				TimeZone:     "Europe/Berlin",
+				Participants: []string{"a", "b"},
+			},
+			from: time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, time.March, 30, 2, 0, 0, 0, time.UTC),
+			want: []string{
+				"a 2025-03-30 01:00 CET/2025-03-30 03:00 CEST r",
+				"b 2025-03-30 03:00 CEST/2025-03-30 04:00 CEST r",
+			},
+		},
+		{
+			name: "interval keeps the turns of periods before the range",
+			rotation: Rotation{
+				Frequency:    FrequencyWeekly,
+				Interval:     2,
+				Start:        utc(6, 9),
+				Participants: []string{"a", "b"},
+			},
+			from: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC),
+			want: []string{
+				"a 2025-05-26 09:00 UTC/2025-06-09 09:00 UTC r",
+				"b 2025-06-09 09:00 UTC/2025-06-23 09:00 UTC r",
+			},
+		},
+		{
+			name: "duration shorter than the period",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        utc(6, 9),
+				Duration:     8 * time.Hour,
+				Participants: []string{"a", "b"},
+			},
+			from: utc(6, 0),
+			to:   utc(8, 0),
+			want: []string{
+				"a 2025-01-06 09:00 UTC/2025-01-06 17:00 UTC r",
+				"b 2025-01-07 09:00 UTC/2025-01-07 17:00 UTC r",
+			},
+		},
+		{
+			name: "until cuts the last shift short",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        utc(6, 9),
+				Until:        &until,
+				Participants: []string{"a", "b"},
+			},
+			from: utc(1, 0),
+			to:   utc(31, 0),
+			want: []string{
+				"a 2025-01-06 09:00 UTC/2025-01-07 09:00 UTC r",
+				"b 2025-01-07 09:00 UTC/2025-01-08 09:00 UTC r",
+				"a 2025-01-08 09:00 UTC/2025-01-08 12:00 UTC r",
+			},
+		},
+		{
+			name: "range after until",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        utc(6, 9),
+				Until:        &until,
+				Participants: []string{"a", "b"},
+			},
+			from: utc(9, 0),
+			to:   utc(31, 0),
+			want: []string{},
+		},
+		{
+			name: "daily skips days not listed without losing turns",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        utc(10, 9),
+				Participants: []string{"a", "b", "c"},
+				ByDay:        []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			},
+			from: utc(10, 0),
+			to:   utc(15, 0),
+			want: []string{
+				"a 2025-01-10 09:00 UTC/2025-01-11 09:00 UTC r",
+				"b 2025-01-13 09:00 UTC/2025-01-14 09:00 UTC r",
+				"c 2025-01-14 09:00 UTC/2025-01-15 09:00 UTC r",
+			},
+		},
+		{
+			name: "daily turns counted over weeks skipped",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        utc(10, 9),
+				Participants: []string{"a", "b", "c"},
+				ByDay:        []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			},
+			from: utc(20, 0),
+			to:   utc(21, 0),
+			want: []string{
+				"a 2025-01-20 09:00 UTC/2025-01-21 09:00 UTC r",
+			},
+		},
+		{
+			name: "weekly cut down to the days listed",
+			rotation: Rotation{
+				Frequency:    FrequencyWeekly,
+				Start:        utc(6, 9),
+				Participants: []string{"a", "b"},
+				ByDay:        []time.Weekday{time.Saturday, time.Sunday},
+			},
+			from: utc(6, 0),
+			to:   utc(13, 0),
+			want: []string{
+				"a 2025-01-11 00:00 UTC/2025-01-13 00:00 UTC r",
+			},
+		},
+		{
+			name: "no participants",
+			rotation: Rotation{
+				Frequency: FrequencyDaily,
+				Start:     utc(6, 9),
+			},
+			from: utc(6, 0),
+			to:   utc(8, 0),
+			want: []string{},
+		},
+		{
+			name: "empty range",
+			rotation: Rotation{
+				Frequency:    FrequencyDaily,
+				Start:        utc(6, 9),
+				Participants: []string{"a"},
+			},
+			from: utc(8, 0),
+			to:   utc(8, 0),
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.rotation
+			r.ID = "r"
+			if r.Interval == 0 {
+				r.Interval = 1
+			}
+
+			got := describe(r.Shifts(tt.from, tt.to, time.UTC))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Shifts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/httpclient"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	mtx    sync.Mutex
+	store  Store
+	users  *user.Service
+	client *http.Client
+}
+
+// NewService returns the schedule service. Calendars may still be fetched
+// from the internal networks in allowed.
+func NewService(store Store, users *user.Service, allowed []netip.Prefix) *Service {
+	return &Service{
+		store:  store,
+		users:  users,
+		client: httpclient.New(30*time.Second, allowed),
+	}
+}
+
+func (s *Service) Create(ctx context.Context, sched *Schedule) error {
+	if err := sched.Validate(); err != nil {
+		return err
+	}
+
+	sched.ID = uuid.NewString()
+	assignIDs(sched)
+
+	if err := s.store.Create(ctx, sched); err != nil {
+		return err
+	}
+
+	if sched.Type == TypeICal && sched.ICalURL != "" {
+		synced, err := s.SyncICal(ctx, sched.ID)
+		if err != nil && !errors.Is(err, ErrInvalidSchedule) {
+			return err
+		}
+		if synced != nil {
+			*sched = *synced
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*Schedule, error) {
+	return s.store.Get(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]*Schedule, error) {
+	return s.store.List(ctx)
+}
+
+// Update replaces the layers of a schedule, keeping its overrides and any
+// imported calendar.
+func (s *Service) Update(ctx context.Context, sched *Schedule) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	existing, err := s.store.Get(ctx, sched.ID)
+	if err != nil {
+		return err
+	}
+
+	sched.Overrides = existing.Overrides
+	sched.ReportedGaps = existing.ReportedGaps
+	if sched.Type == TypeICal {
+		sched.ICalEvents = existing.ICalEvents
+		sched.ICalUsers = existing.ICalUsers
+		sched.ICalError = existing.ICalError
+		sched.ICalSyncedAt = existing.ICalSyncedAt
+	}
+
+	if err := sched.Validate(); err != nil {
+		return err
+	}
+
+	assignIDs(sched)
+
+	return s.store.Update(ctx, sched)
+}
+
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
+
+func (s *Service) AddOverride(ctx context.Context, scheduleID string, o *Override) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sched, err := s.store.Get(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	o.ID = uuid.NewString()
+	sched.Overrides = append(sched.Overrides, o)
+
+	return s.store.Update(ctx, sched)
+}
+
+func (s *Service) DeleteOverride(ctx context.Context, scheduleID, overrideID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sched, err := s.store.Get(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	for i, o := range sched.Overrides {
+		if o.ID == overrideID {
+			sched.Overrides = append(sched.Overrides[:i], sched.Overrides[i+1:]...)
+			return s.store.Update(ctx, sched)
+		}
+	}
+
+	return ErrOverrideNotFound
+}
+
+func (s *Service) FinalShifts(ctx context.Context, id string, from, to time.Time) ([]*Shift, error) {
+	sched, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return sched.FinalShifts(from, to)
+}
+
+// OnCallUserIDs returns the users on call in the schedule at the instant.
+func (s *Service) OnCallUserIDs(ctx context.Context, scheduleID string, at time.Time) ([]string, error) {
+	sched, err := s.store.Get(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sched.OnCallAt(at)
+}
+
+func assignIDs(sched *Schedule) {
+	for _, layer := range sched.Layers {
+		for _, r := range layer.Rotations {
+			if r.ID == "" {
+				r.ID = uuid.NewString()
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps schedules in the SQL database, which the server and workers
+// share.
+type SQLStore struct {
+	db        *sqlstore.DB
+	schedules *sqlstore.Table[Schedule]
+	tokens    *sqlstore.Table[ExportToken]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	schedules, err := sqlstore.NewTable(db, "schedules", func(s *Schedule) string { return s.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := sqlstore.NewTable(db, "schedule_export_tokens", func(t *ExportToken) string { return t.Hash },
+		sqlstore.Column[ExportToken]{Name: "schedule_id", Value: func(t *ExportToken) string { return t.ScheduleID }},
+		sqlstore.Column[ExportToken]{Name: "user_id", Value: func(t *ExportToken) string { return t.UserID }},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, schedules: schedules, tokens: tokens}, nil
+}
+
+func (st *SQLStore) Create(ctx context.Context, s *Schedule) error {
+	return st.schedules.Insert(ctx, s)
+}
+
+func (st *SQLStore) Get(ctx context.Context, id string) (*Schedule, error) {
+	s, err := st.schedules.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrScheduleNotFound
+	}
+
+	return s, err
+}
+
+func (st *SQLStore) List(ctx context.Context) ([]*Schedule, error) {
+	schedules, err := st.schedules.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Name < schedules[j].Name
+	})
+
+	return schedules, nil
+}
+
+func (st *SQLStore) Update(ctx context.Context, s *Schedule) error {
+	err := st.schedules.Update(ctx, s)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrScheduleNotFound
+	}
+
+	return err
+}
+
+func (st *SQLStore) Delete(ctx context.Context, id string) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		err := st.schedules.Delete(ctx, id)
+		if errors.Is(err, sqlstore.ErrNotFound) {
+			return ErrScheduleNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = st.tokens.DeleteWhere(ctx, sqlstore.Where{"schedule_id": id})
+		return err
+	})
+}
+
+// SaveExportToken stores the token, replacing any other token for the same
+// schedule or user.
+func (st *SQLStore) SaveExportToken(ctx context.Context, t *ExportToken) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := st.tokens.DeleteWhere(ctx, sqlstore.Where{"schedule_id": t.ScheduleID, "user_id": t.UserID}); err != nil {
+			return err
+		}
+
+		return st.tokens.Insert(ctx, t)
+	})
+}
+
+func (st *SQLStore) GetExportToken(ctx context.Context, hash string) (*ExportToken, error) {
+	t, err := st.tokens.Get(ctx, hash)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrExportTokenNotFound
+	}
+
+	return t, err
+}
+
+func (st *SQLStore) DeleteExportToken(ctx context.Context, scheduleID, userID string) error {
+	n, err := st.tokens.DeleteWhere(ctx, sqlstore.Where{"schedule_id": scheduleID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrExportTokenNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package schedule
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, s *Schedule) error
+	Get(ctx context.Context, id string) (*Schedule, error)
+	List(ctx context.Context) ([]*Schedule, error)
+	Update(ctx context.Context, s *Schedule) error
+	Delete(ctx context.Context, id string) error
+
+	// SaveExportToken stores the token, which only has its hash set.
+	SaveExportToken(ctx context.Context, t *ExportToken) error
+	GetExportToken(ctx context.Context, hash string) (*ExportToken, error)
+	DeleteExportToken(ctx context.Context, scheduleID, userID string) error
+}
@@ -33,8 +33,8 @@ type Server struct {
 	HTTPServer *api.HTTPServer
 }
 
-func New(cfg *setting.Cfg, api *api.HTTPServer, worker *worker.RabbitWorker) (*Server, error) {
-	s, err := newServer(cfg, api, worker)
+func New(cfg *setting.Cfg, api *api.HTTPServer, worker *worker.RabbitWorker, backgroundServices ...services.BackgroundService) (*Server, error) {
+	s, err := newServer(cfg, api, worker, backgroundServices...)
 	if err != nil {
 		return nil, err
 	}
@@ -81,12 +81,12 @@ func (s *Server) Run() error {
 			err := svc.Run(s.context)
 
 			if err != nil && !errors.Is(err, context.Canceled) {
-				fmt.Printf("Stopped background service %s\r\nReason: %w\r\n", serviceName, err)
+				fmt.Printf("Stopped background service %s\r\nReason: %s\r\n", serviceName, err)
 
 				return fmt.Errorf("%s run error: %w", serviceName, err)
 			}
 
-			fmt.Printf("Stopped background service %s\r\nReason: %w\r\n", serviceName, err)
+			fmt.Printf("Stopped background service %s\r\nReason: %s\r\n", serviceName, err)
 
 			return nil
 		})
@@ -115,7 +115,7 @@ func (s *Server) Shutdown(ctx context.Context, reason string) error {
 	return err
 }
 
-func newServer(cfg *setting.Cfg, api *api.HTTPServer, worker *worker.RabbitWorker) (*Server, error) {
+func newServer(cfg *setting.Cfg, api *api.HTTPServer, worker *worker.RabbitWorker, backgroundServices ...services.BackgroundService) (*Server, error) {
 	rootCtx, shutdownFn := context.WithCancel(context.Background())
 	childRoutines, childCtx := errgroup.WithContext(rootCtx)
 
@@ -126,10 +126,10 @@ func newServer(cfg *setting.Cfg, api *api.HTTPServer, worker *worker.RabbitWorke
 		cfg:              cfg,
 		shutdownFn:       shutdownFn,
 		shutdownFinished: make(chan struct{}),
-		backgroundServices: []services.BackgroundService{
+		backgroundServices: append([]services.BackgroundService{
 			api,
 			worker,
-		},
+		}, backgroundServices...),
 	}
 
 	return s, nil
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"fmt"
+	"net/netip"
 	"net/url"
 	"os"
 	"path"
@@ -76,11 +77,49 @@ type Cfg struct {
 	RabbitMqQueueName    string
 	RabbitMqVhost        string
 
+	// DatabaseType is the SQL database the services keep their data in,
+	// either sqlite3 or postgres.
+	DatabaseType string
+	// DatabasePath is the file of the sqlite3 database.
+	DatabasePath string
+	// DatabaseURL is the connection string of the postgres database.
+	DatabaseURL string
+
+	// AuthProxySecret is the secret the Grafana plugin proxy sends with the
+	// requests it forwards to the API.
+	AuthProxySecret string
+
+	// OutgoingAllowedNetworks are the internal networks requests to URLs set
+	// by users, such as webhooks, may still be sent to.
+	OutgoingAllowedNetworks []netip.Prefix
+
+	HeartbeatCheckInterval time.Duration
+
+	ICalSyncInterval          time.Duration
+	ScheduleGapCheckInterval  time.Duration
+	ScheduleGapCheckWeeks     int
+	ShiftNotificationInterval time.Duration
+	// ScheduleChatNotifier is the type of the notifier that posts to the
+	// chat channels of schedules and teams, such as on-call changes and gaps
+	// in schedules.
+	ScheduleChatNotifier string
+
+	// Notifiers holds the [notifier.<type>] sections by notifier type.
+	Notifiers map[string]*NotifierSettings
+
 	configFiles                  []string
 	appliedCommandLineProperties []string
 	appliedEnvOverrides          []string
 }
 
+// NotifierSettings are the keys of a [notifier.<type>] section.
+type NotifierSettings struct {
+	Enabled bool
+	// Settings holds the other keys of the section, which are specific to
+	// the notifier.
+	Settings map[string]string
+}
+
 type CommandLineArgs struct {
 	Config   string
 	HomePath string
@@ -148,10 +187,14 @@ func RedactedValue(key, value string) string {
 		"ID_FORWARDING_TOKEN$",
 		"AUTHENTICATION_TOKEN$",
 		"AUTH_TOKEN$",
+		"BOT_TOKEN$",
 		"RENDERER_TOKEN$",
 		"API_TOKEN$",
 		"WEBHOOK_TOKEN$",
 		"INSTALL_TOKEN$",
+		"INBOUND_TOKEN$",
+		"WEBHOOK_URL$",
+		"ACCESS_TOKEN$",
 	} {
 		if match, err := regexp.MatchString(pattern, uppercased); match && err == nil {
 			return RedactedPassword
@@ -264,7 +307,7 @@ func (cfg *Cfg) getCommandLineProperties(args []string) map[string]string {
 		trimmed := strings.TrimPrefix(arg, "cfg:")
 		parts := strings.Split(trimmed, "=")
 		if len(parts) != 2 {
-			fmt.Errorf("Invalid command line argument: %s\n", arg)
+			fmt.Printf("Invalid command line argument: %s\n", arg)
 			os.Exit(1)
 		}
 
@@ -300,7 +343,7 @@ func (cfg *Cfg) loadConfiguration(args CommandLineArgs) (*ini.File, error) {
 	// load specified config file
 	err = cfg.loadSpecifiedConfigFile(args.Config, parsedFile)
 	if err != nil {
-		fmt.Errorf("%s\n", err.Error())
+		fmt.Printf("%s\n", err.Error())
 		os.Exit(1)
 	}
 
@@ -390,6 +433,26 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 		return err
 	}
 
+	if err := cfg.readDatabaseSettings(iniFile); err != nil {
+		return err
+	}
+
+	cfg.readAuthSettings(iniFile)
+
+	if err := cfg.readOutgoingRequestSettings(iniFile); err != nil {
+		return err
+	}
+
+	if err := cfg.readHeartbeatSettings(iniFile); err != nil {
+		return err
+	}
+
+	if err := cfg.readScheduleSettings(iniFile); err != nil {
+		return err
+	}
+
+	cfg.readNotifierSettings(iniFile)
+
 	return nil
 }
 
@@ -403,7 +466,7 @@ func (cfg *Cfg) parseAppUrlAndSubUrl(section *ini.Section) (string, string, erro
 	// Check if has app suburl.
 	url, err := url.Parse(appUrl)
 	if err != nil {
-		fmt.Errorf("Invalid root_url %s: %w\n", appUrl, err)
+		fmt.Printf("Invalid root_url %s: %s\n", appUrl, err)
 		os.Exit(1)
 	}
 
@@ -422,6 +485,111 @@ func pathExists(path string) bool {
 	return false
 }
 
+func (cfg *Cfg) readDatabaseSettings(iniFile *ini.File) error {
+	database := iniFile.Section("database")
+
+	cfg.DatabaseType = database.Key("type").MustString("sqlite3")
+	cfg.DatabasePath = makeAbsolute(database.Key("path").MustString("oncall.db"), cfg.DataPath)
+	cfg.DatabaseURL = database.Key("url").String()
+
+	switch cfg.DatabaseType {
+	case "sqlite3":
+	case "postgres":
+		if cfg.DatabaseURL == "" {
+			return fmt.Errorf("database url is required for postgres")
+		}
+	default:
+		return fmt.Errorf("unknown database type %q", cfg.DatabaseType)
+	}
+
+	return nil
+}
+
+func (cfg *Cfg) readAuthSettings(iniFile *ini.File) {
+	auth := iniFile.Section("auth")
+
+	cfg.AuthProxySecret = auth.Key("proxy_secret").String()
+}
+
+func (cfg *Cfg) readOutgoingRequestSettings(iniFile *ini.File) error {
+	outgoing := iniFile.Section("outgoing_requests")
+
+	cfg.OutgoingAllowedNetworks = nil
+	for _, network := range util.SplitString(outgoing.Key("allowed_networks").String()) {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return fmt.Errorf("outgoing_requests allowed_networks: %w", err)
+		}
+
+		cfg.OutgoingAllowedNetworks = append(cfg.OutgoingAllowedNetworks, prefix)
+	}
+
+	return nil
+}
+
+func (cfg *Cfg) readHeartbeatSettings(iniFile *ini.File) error {
+	heartbeat := iniFile.Section("heartbeat")
+
+	cfg.HeartbeatCheckInterval = heartbeat.Key("check_interval").MustDuration(time.Minute)
+	if cfg.HeartbeatCheckInterval <= 0 {
+		return fmt.Errorf("heartbeat check_interval must be positive")
+	}
+
+	return nil
+}
+
+func (cfg *Cfg) readScheduleSettings(iniFile *ini.File) error {
+	schedules := iniFile.Section("schedules")
+
+	cfg.ICalSyncInterval = schedules.Key("ical_sync_interval").MustDuration(10 * time.Minute)
+	if cfg.ICalSyncInterval <= 0 {
+		return fmt.Errorf("schedules ical_sync_interval must be positive")
+	}
+
+	cfg.ScheduleGapCheckInterval = schedules.Key("gap_check_interval").MustDuration(time.Hour)
+	if cfg.ScheduleGapCheckInterval <= 0 {
+		return fmt.Errorf("schedules gap_check_interval must be positive")
+	}
+
+	cfg.ScheduleGapCheckWeeks = schedules.Key("gap_check_weeks").MustInt(2)
+	if cfg.ScheduleGapCheckWeeks < 1 {
+		return fmt.Errorf("schedules gap_check_weeks must be at least 1")
+	}
+
+	cfg.ShiftNotificationInterval = schedules.Key("shift_notification_interval").MustDuration(time.Minute)
+	if cfg.ShiftNotificationInterval <= 0 {
+		return fmt.Errorf("schedules shift_notification_interval must be positive")
+	}
+
+	cfg.ScheduleChatNotifier = schedules.Key("chat_notifier").MustString("slack")
+
+	return nil
+}
+
+func (cfg *Cfg) readNotifierSettings(iniFile *ini.File) {
+	cfg.Notifiers = make(map[string]*NotifierSettings)
+
+	for _, section := range iniFile.Sections() {
+		t, ok := strings.CutPrefix(section.Name(), "notifier.")
+		if !ok {
+			continue
+		}
+
+		ns := &NotifierSettings{
+			Enabled:  section.Key("enabled").MustBool(false),
+			Settings: make(map[string]string),
+		}
+
+		for _, key := range section.Keys() {
+			if key.Name() != "enabled" {
+				ns.Settings[key.Name()] = key.Value()
+			}
+		}
+
+		cfg.Notifiers[t] = ns
+	}
+}
+
 func (cfg *Cfg) readRabbitMqSettings(iniFile *ini.File) error {
 	rabbit := iniFile.Section("rabbit")
 	var err error
@@ -530,7 +698,7 @@ func (cfg *Cfg) validateStaticRootPath() error {
 	}
 
 	if _, err := os.Stat(path.Join(cfg.StaticRootPath, "build")); err != nil {
-		fmt.Errorf("Failed to detect generated javascript files in public/build\n")
+		fmt.Printf("Failed to detect generated javascript files in public/build\n")
 	}
 
 	return nil
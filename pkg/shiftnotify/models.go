@@ -0,0 +1,51 @@
+package shiftnotify
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxNotifyBefore is the longest users can ask to be told ahead of a shift.
+const MaxNotifyBefore = 7 * 24 * time.Hour
+
+// MaxCatchUp is how far back notifications are published after the server
+// was down, so that a long outage does not flood users with stale notices.
+const MaxCatchUp = 24 * time.Hour
+
+var ErrInvalidPreferences = errors.New("invalid shift notification preferences")
+
+// Preferences control the shift notifications of a user.
+type Preferences struct {
+	UserID string
+	// NotifyBefore is how long before a shift starts the user is told
+	// about it, zero turning the notification off.
+	NotifyBefore time.Duration
+	// NotifyOnEnd tells the user when their shift is over.
+	NotifyOnEnd bool
+}
+
+// DefaultPreferences apply to users who have not set their own.
+func DefaultPreferences(userID string) *Preferences {
+	return &Preferences{
+		UserID:       userID,
+		NotifyBefore: time.Hour,
+		NotifyOnEnd:  true,
+	}
+}
+
+func (p *Preferences) Validate() error {
+	if p.NotifyBefore < 0 || p.NotifyBefore > MaxNotifyBefore {
+		return fmt.Errorf("%w: notify before must be between 0 and %s", ErrInvalidPreferences, MaxNotifyBefore)
+	}
+
+	return nil
+}
+
+// Progress records up to when shift notifications were published, so that a
+// restarted server, or another server sharing the database, carries on from
+// there.
+type Progress struct {
+	ID            string
+	NotifiedUntil time.Time
+}
@@ -0,0 +1,457 @@
+package shiftnotify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/team"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+const (
+	// JobNotifyUser is published to tell a user about one of their shifts,
+	// with the user ID, schedule ID, kind, and the time the shift starts or
+	// ended as arguments.
+	JobNotifyUser worker.JobType = "shift_notify_user"
+
+	// JobOnCallChanged is published when the users on call in a schedule
+	// with a chat channel change, with the schedule ID, the time of the
+	// change and the users now on call as arguments.
+	JobOnCallChanged worker.JobType = "schedule_on_call_changed"
+)
+
+const (
+	KindUpcoming = "upcoming"
+	KindEnded    = "ended"
+)
+
+// progressID names the progress of the Notifier, which also locks it.
+const progressID = "shift_notifications"
+
+// timeFormat is how the times of shifts are shown to users.
+const timeFormat = "Mon Jan 2 15:04 MST"
+
+type Service struct {
+	store     Store
+	schedules *schedule.Service
+	users     *user.Service
+	teams     *team.Service
+	policies  *notificationpolicy.Service
+	notifiers *notifier.Service
+	// chatNotifier is the type of the notifier that posts to the chat
+	// channels of schedules and teams.
+	chatNotifier string
+	worker       worker.Worker
+}
+
+func NewService(store Store, schedules *schedule.Service, users *user.Service, teams *team.Service, policies *notificationpolicy.Service, notifiers *notifier.Service, chatNotifier string, w worker.Worker) *Service {
+	return &Service{
+		store:        store,
+		schedules:    schedules,
+		users:        users,
+		teams:        teams,
+		policies:     policies,
+		notifiers:    notifiers,
+		chatNotifier: chatNotifier,
+		worker:       w,
+	}
+}
+
+// Preferences returns the preferences of the user, or the defaults when the
+// user has not set any.
+func (s *Service) Preferences(ctx context.Context, userID string) (*Preferences, error) {
+	p, err := s.store.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil {
+		return DefaultPreferences(userID), nil
+	}
+
+	return p, nil
+}
+
+func (s *Service) SavePreferences(ctx context.Context, p *Preferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.SavePreferences(ctx, p)
+}
+
+// Notify publishes the notifications due between after, exclusive, and
+// until, inclusive: users whose shift starts within their notice period,
+// users whose shift ended, and changes of who is on call in schedules with a
+// chat channel.
+func (s *Service) Notify(ctx context.Context, after, until time.Time) error {
+	stored, err := s.store.ListPreferences(ctx)
+	if err != nil {
+		return err
+	}
+
+	prefs := make(map[string]*Preferences, len(stored))
+	lead := DefaultPreferences("").NotifyBefore
+	for _, p := range stored {
+		prefs[p.UserID] = p
+		lead = max(lead, p.NotifyBefore)
+	}
+
+	preferences := func(userID string) *Preferences {
+		if p, ok := prefs[userID]; ok {
+			return p
+		}
+		return DefaultPreferences(userID)
+	}
+
+	schedules, err := s.schedules.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, sched := range schedules {
+		// Look a little past the furthest notice so that shifts running on
+		// beyond it are not mistaken for shifts ending there.
+		shifts, err := sched.FinalShifts(after, until.Add(lead+time.Minute))
+		if err != nil {
+			fmt.Printf("Failed to get shifts of schedule %s: %s\n", sched.ID, err)
+			continue
+		}
+
+		for _, p := range userPeriods(shifts) {
+			prefs := preferences(p.userID)
+
+			if prefs.NotifyBefore > 0 && p.start.After(after) {
+				at := p.start.Add(-prefs.NotifyBefore)
+				if at.After(after) && !at.After(until) {
+					s.notifyUser(ctx, p.userID, sched.ID, KindUpcoming, p.start)
+				}
+			}
+
+			if prefs.NotifyOnEnd && p.end.After(after) && !p.end.After(until) {
+				s.notifyUser(ctx, p.userID, sched.ID, KindEnded, p.end)
+			}
+		}
+
+		if sched.ChatChannel != "" {
+			s.notifyChanges(ctx, sched.ID, shifts, after, until)
+		}
+	}
+
+	return nil
+}
+
+// NotifyUntil publishes the notifications due since the last call, by this
+// or any other process, up to now, going back at most MaxCatchUp. The first
+// call only records the time.
+func (s *Service) NotifyUntil(ctx context.Context, now time.Time) error {
+	return s.store.InTransaction(ctx, progressID, func(ctx context.Context) error {
+		p, err := s.store.GetProgress(ctx, progressID)
+		if err != nil {
+			return err
+		}
+
+		if p == nil {
+			return s.store.SaveProgress(ctx, &Progress{ID: progressID, NotifiedUntil: now})
+		}
+
+		if !now.After(p.NotifiedUntil) {
+			return nil
+		}
+
+		after := p.NotifiedUntil
+		if earliest := now.Add(-MaxCatchUp); after.Before(earliest) {
+			after = earliest
+		}
+
+		if err := s.Notify(ctx, after, now); err != nil {
+			return err
+		}
+
+		p.NotifiedUntil = now
+
+		return s.store.SaveProgress(ctx, p)
+	})
+}
+
+func (s *Service) notifyUser(ctx context.Context, userID, scheduleID, kind string, at time.Time) {
+	if err := s.worker.Enqueue(ctx, JobNotifyUser, userID, scheduleID, kind, at.UTC().Format(time.RFC3339)); err != nil {
+		fmt.Printf("Failed to publish shift notification for %s: %s\n", userID, err)
+	}
+}
+
+// notifyChanges publishes the changes of who is on call within the range.
+func (s *Service) notifyChanges(ctx context.Context, scheduleID string, shifts []*schedule.Shift, after, until time.Time) {
+	publish := func(at time.Time, userIDs []string) {
+		args := append([]string{scheduleID, at.UTC().Format(time.RFC3339)}, userIDs...)
+		if err := s.worker.Enqueue(ctx, JobOnCallChanged, args...); err != nil {
+			fmt.Printf("Failed to publish on-call change of schedule %s: %s\n", scheduleID, err)
+		}
+	}
+
+	within := func(t time.Time) bool {
+		return t.After(after) && !t.After(until)
+	}
+
+	var (
+		previous []string
+		end      time.Time
+	)
+
+	for _, shift := range shifts {
+		if shift.Start.After(until) {
+			break
+		}
+
+		// Nobody was on call between the previous shift and this one.
+		if !end.IsZero() && shift.Start.After(end) {
+			if within(end) {
+				publish(end, nil)
+			}
+			previous = nil
+		}
+
+		if within(shift.Start) && !slices.Equal(previous, shift.UserIDs) {
+			publish(shift.Start, shift.UserIDs)
+		}
+
+		previous = shift.UserIDs
+		end = shift.End
+	}
+
+	if within(end) {
+		publish(end, nil)
+	}
+}
+
+// HandleNotifyUser tells a user about one of their shifts by their preferred
+// notification method.
+func (s *Service) HandleNotifyUser(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 4 {
+		fmt.Printf("Invalid %s job %s, expected 4 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	userID, scheduleID, kind := job.Args[0], job.Args[1], job.Args[2]
+	at, err := time.Parse(time.RFC3339, job.Args[3])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s: %s\n", job.Type, job.ID, err)
+		return
+	}
+
+	sched, err := s.schedules.Get(ctx, scheduleID)
+	if err != nil {
+		fmt.Printf("Failed to load schedule %s: %s\n", scheduleID, err)
+		return
+	}
+
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		fmt.Printf("Failed to load user %s: %s\n", userID, err)
+		return
+	}
+
+	if loc, err := time.LoadLocation(u.TimeZone); err == nil {
+		at = at.In(loc)
+	}
+
+	m := &notifier.Message{UserID: userID}
+	switch kind {
+	case KindUpcoming:
+		m.Title = fmt.Sprintf("Your on-call shift on %s starts soon", sched.Name)
+		m.Text = fmt.Sprintf("Your on-call shift on %s starts at %s.", sched.Name, at.Format(timeFormat))
+	case KindEnded:
+		m.Title = fmt.Sprintf("Your on-call shift on %s has ended", sched.Name)
+		m.Text = fmt.Sprintf("Your on-call shift on %s ended at %s.", sched.Name, at.Format(timeFormat))
+	default:
+		fmt.Printf("Invalid %s job %s, unknown kind %q\n", job.Type, job.ID, kind)
+		return
+	}
+
+	method, err := s.policies.PreferredMethod(ctx, userID)
+	if err != nil {
+		fmt.Printf("Failed to load notification method of %s: %s\n", userID, err)
+		return
+	}
+
+	if err := s.notifiers.Notify(ctx, method, m); err != nil {
+		fmt.Printf("Failed to publish shift notification of %s: %s\n", userID, err)
+	}
+}
+
+// HandleOnCallChanged tells the chat channel of a schedule who is now on
+// call.
+func (s *Service) HandleOnCallChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected at least 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	sched, err := s.schedules.Get(ctx, job.Args[0])
+	if err != nil {
+		fmt.Printf("Failed to load schedule %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	if sched.ChatChannel == "" {
+		return
+	}
+
+	m := &notifier.Message{
+		Channel: sched.ChatChannel,
+		Title:   fmt.Sprintf("On call on %s", sched.Name),
+		Text:    "Nobody is on call now.",
+	}
+
+	if userIDs := job.Args[2:]; len(userIDs) > 0 {
+		names := make([]string, 0, len(userIDs))
+		for _, userID := range userIDs {
+			names = append(names, s.userName(ctx, userID))
+		}
+
+		m.Text = "Now on call: " + strings.Join(names, ", ")
+	}
+
+	if err := s.notifiers.Notify(ctx, s.chatNotifier, m); err != nil {
+		fmt.Printf("Failed to publish on-call change of schedule %s: %s\n", sched.ID, err)
+	}
+}
+
+// HandleGapsDetected tells the chat channel of the team of a schedule about
+// new gaps in its upcoming shifts.
+func (s *Service) HandleGapsDetected(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 3 || len(job.Args)%2 != 1 {
+		fmt.Printf("Invalid %s job %s, expected a schedule ID and pairs of times\n", job.Type, job.ID)
+		return
+	}
+
+	sched, err := s.schedules.Get(ctx, job.Args[0])
+	if err != nil {
+		fmt.Printf("Failed to load schedule %s: %s\n", job.Args[0], err)
+		return
+	}
+
+	if sched.TeamID == "" {
+		return
+	}
+
+	t, err := s.teams.Get(ctx, sched.TeamID)
+	if err != nil {
+		fmt.Printf("Failed to load team %s of schedule %s: %s\n", sched.TeamID, sched.ID, err)
+		return
+	}
+
+	if t.ChatChannel == "" {
+		return
+	}
+
+	loc, err := time.LoadLocation(sched.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	var b strings.Builder
+	b.WriteString("Nobody is on call:")
+	for i := 1; i < len(job.Args); i += 2 {
+		start, err := time.Parse(time.RFC3339, job.Args[i])
+		if err != nil {
+			fmt.Printf("Invalid %s job %s: %s\n", job.Type, job.ID, err)
+			return
+		}
+
+		end, err := time.Parse(time.RFC3339, job.Args[i+1])
+		if err != nil {
+			fmt.Printf("Invalid %s job %s: %s\n", job.Type, job.ID, err)
+			return
+		}
+
+		fmt.Fprintf(&b, "\n- %s to %s", start.In(loc).Format(timeFormat), end.In(loc).Format(timeFormat))
+	}
+
+	m := &notifier.Message{
+		Channel: t.ChatChannel,
+		Title:   fmt.Sprintf("Gaps in %s", sched.Name),
+		Text:    b.String(),
+	}
+
+	if err := s.notifiers.Notify(ctx, s.chatNotifier, m); err != nil {
+		fmt.Printf("Failed to publish gaps of schedule %s: %s\n", sched.ID, err)
+	}
+}
+
+// userName returns the username of the user, or the ID of users that no
+// longer exist.
+func (s *Service) userName(ctx context.Context, userID string) string {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return userID
+	}
+
+	return u.Username
+}
+
+// userPeriod is a stretch of time during which a user is on call without a
+// break.
+type userPeriod struct {
+	userID string
+	start  time.Time
+	end    time.Time
+}
+
+func userPeriods(shifts []*schedule.Shift) []*userPeriod {
+	var (
+		periods []*userPeriod
+		open    = make(map[string]*userPeriod)
+	)
+
+	for _, shift := range shifts {
+		for _, userID := range shift.UserIDs {
+			if p, ok := open[userID]; ok && p.end.Equal(shift.Start) {
+				p.end = shift.End
+				continue
+			}
+
+			p := &userPeriod{userID: userID, start: shift.Start, end: shift.End}
+			open[userID] = p
+			periods = append(periods, p)
+		}
+	}
+
+	return periods
+}
+
+// Notifier periodically publishes shift notifications.
+type Notifier struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewNotifier(service *Service, interval time.Duration) *Notifier {
+	return &Notifier{
+		service:  service,
+		interval: interval,
+	}
+}
+
+func (n *Notifier) Run(ctx context.Context) error {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := n.service.NotifyUntil(ctx, now); err != nil {
+				fmt.Printf("Failed to send shift notifications: %s\n", err)
+			}
+		}
+	}
+}
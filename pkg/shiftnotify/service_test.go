@@ -0,0 +1,94 @@
+package shiftnotify
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// kindWorker keeps the kinds of the shift notifications published.
+type kindWorker struct {
+	kinds []string
+}
+
+func (w *kindWorker) Enqueue(ctx context.Context, t worker.JobType, args ...string) error {
+	if t == JobNotifyUser {
+		w.kinds = append(w.kinds, args[2])
+	}
+	return nil
+}
+
+func (w *kindWorker) EnqueueIn(ctx context.Context, delay time.Duration, t worker.JobType, args ...string) error {
+	return w.Enqueue(ctx, t, args...)
+}
+
+func (w *kindWorker) RegisterHandler(worker.JobType, worker.JobHandler, any) {}
+
+func (w *kindWorker) Stop(ctx context.Context) {}
+
+func TestNotifyUntil(t *testing.T) {
+	at := func(day, h, m int) time.Time {
+		return time.Date(2025, time.January, day, h, m, 0, 0, time.UTC)
+	}
+
+	// Alice is on call from 10:00 to 12:00 on the 6th, so she is told at
+	// 09:00 and 12:00 by default.
+	tests := []struct {
+		name  string
+		calls []time.Time
+		want  []string
+	}{
+		{name: "first call only records the time", calls: []time.Time{at(6, 9, 30)}},
+		{name: "carries on from the last call", calls: []time.Time{at(6, 8, 0), at(6, 9, 30)}, want: []string{KindUpcoming}},
+		{name: "catches up after a restart", calls: []time.Time{at(6, 8, 0), at(6, 12, 30)}, want: []string{KindUpcoming, KindEnded}},
+		{name: "repeated time", calls: []time.Time{at(6, 8, 0), at(6, 9, 30), at(6, 9, 30), at(6, 9, 0)}, want: []string{KindUpcoming}},
+		// Only the end of the shift is within a day of the last call.
+		{name: "catches up at most a day", calls: []time.Time{at(5, 8, 0), at(7, 11, 0)}, want: []string{KindEnded}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := sqlstore.InitTestDB(t)
+
+			scheduleStore, err := schedule.NewSQLStore(db)
+			if err != nil {
+				t.Fatalf("schedule.NewSQLStore() error = %v", err)
+			}
+			schedules := schedule.NewService(scheduleStore, nil, nil)
+
+			sched := &schedule.Schedule{
+				Name:      "primary",
+				Type:      schedule.TypeWeb,
+				TimeZone:  "UTC",
+				Overrides: []*schedule.Override{{UserIDs: []string{"alice"}, Start: at(6, 10, 0), End: at(6, 12, 0)}},
+			}
+			if err := schedules.Create(ctx, sched); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			store, err := NewSQLStore(db)
+			if err != nil {
+				t.Fatalf("NewSQLStore() error = %v", err)
+			}
+
+			w := &kindWorker{}
+			for _, now := range tt.calls {
+				// Every call is made by a new service, as after a restart.
+				s := NewService(store, schedules, nil, nil, nil, nil, "", w)
+				if err := s.NotifyUntil(ctx, now); err != nil {
+					t.Fatalf("NotifyUntil() error = %v", err)
+				}
+			}
+
+			if !slices.Equal(w.kinds, tt.want) {
+				t.Errorf("notified %q, want %q", w.kinds, tt.want)
+			}
+		})
+	}
+}
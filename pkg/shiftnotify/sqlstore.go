@@ -0,0 +1,72 @@
+package shiftnotify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps preferences and progress in the SQL database, which the
+// server and workers share.
+type SQLStore struct {
+	db          *sqlstore.DB
+	preferences *sqlstore.Table[Preferences]
+	progress    *sqlstore.Table[Progress]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	preferences, err := sqlstore.NewTable(db, "shift_notification_preferences", func(p *Preferences) string { return p.UserID })
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := sqlstore.NewTable(db, "shift_notification_progress", func(p *Progress) string { return p.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, preferences: preferences, progress: progress}, nil
+}
+
+func (st *SQLStore) GetPreferences(ctx context.Context, userID string) (*Preferences, error) {
+	p, err := st.preferences.Get(ctx, userID)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return p, err
+}
+
+func (st *SQLStore) SavePreferences(ctx context.Context, p *Preferences) error {
+	return st.preferences.Save(ctx, p)
+}
+
+func (st *SQLStore) ListPreferences(ctx context.Context) ([]*Preferences, error) {
+	return st.preferences.Find(ctx, nil)
+}
+
+func (st *SQLStore) InTransaction(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := st.db.Lock(ctx, key); err != nil {
+			return err
+		}
+
+		return fn(ctx)
+	})
+}
+
+func (st *SQLStore) GetProgress(ctx context.Context, id string) (*Progress, error) {
+	p, err := st.progress.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return p, err
+}
+
+func (st *SQLStore) SaveProgress(ctx context.Context, p *Progress) error {
+	return st.progress.Save(ctx, p)
+}
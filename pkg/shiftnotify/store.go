@@ -0,0 +1,19 @@
+package shiftnotify
+
+import "context"
+
+type Store interface {
+	// GetPreferences returns the preferences of the user, or nil if they
+	// have not set any.
+	GetPreferences(ctx context.Context, userID string) (*Preferences, error)
+	SavePreferences(ctx context.Context, p *Preferences) error
+	ListPreferences(ctx context.Context) ([]*Preferences, error)
+
+	// InTransaction runs fn in a transaction holding the lock named by key,
+	// serializing it with other processes.
+	InTransaction(ctx context.Context, key string, fn func(ctx context.Context) error) error
+	// GetProgress returns the progress with the ID, or nil if there is none
+	// yet.
+	GetProgress(ctx context.Context, id string) (*Progress, error)
+	SaveProgress(ctx context.Context, p *Progress) error
+}
@@ -0,0 +1,84 @@
+package shiftswap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusOpen    Status = "open"
+	StatusTaken   Status = "taken"
+	StatusDeleted Status = "deleted"
+	// StatusPastDue swap requests were not taken before they started.
+	StatusPastDue Status = "past_due"
+)
+
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionTaken   Action = "taken"
+	ActionDeleted Action = "deleted"
+)
+
+var (
+	ErrShiftSwapNotFound     = errors.New("shift swap not found")
+	ErrInvalidShiftSwap      = errors.New("invalid shift swap")
+	ErrShiftSwapNotOpen      = errors.New("shift swap is no longer open")
+	ErrNotBeneficiary        = errors.New("only the requester can change a shift swap")
+	ErrBeneficiaryCannotTake = errors.New("shift swap cannot be taken by its requester")
+	ErrNotParticipant        = errors.New("only users taking part in the schedule can request or take shift swaps")
+)
+
+// ShiftSwap is a request by the beneficiary for someone to take over their
+// shifts on a schedule between SwapStart and SwapEnd.
+type ShiftSwap struct {
+	ID          string
+	ScheduleID  string
+	Beneficiary string
+	// Benefactor took over the shifts, once the swap was taken.
+	Benefactor  string
+	SwapStart   time.Time
+	SwapEnd     time.Time
+	Description string
+	Status      Status
+	// OverrideIDs are the schedule overrides which put the benefactor on
+	// call in place of the beneficiary.
+	OverrideIDs []string
+	TakenAt     *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// HistoryEntry records a change to a shift swap.
+type HistoryEntry struct {
+	ID          string
+	ShiftSwapID string
+	Action      Action
+	Actor       string
+	CreatedAt   time.Time
+}
+
+func (s *ShiftSwap) Validate(now time.Time) error {
+	if s.ScheduleID == "" {
+		return fmt.Errorf("%w: schedule is required", ErrInvalidShiftSwap)
+	}
+
+	if s.Beneficiary == "" {
+		return fmt.Errorf("%w: beneficiary is required", ErrInvalidShiftSwap)
+	}
+
+	if !s.SwapStart.After(now) {
+		return fmt.Errorf("%w: swap must start in the future", ErrInvalidShiftSwap)
+	}
+
+	if !s.SwapEnd.After(s.SwapStart) {
+		return fmt.Errorf("%w: swap must end after it starts", ErrInvalidShiftSwap)
+	}
+
+	return nil
+}
@@ -0,0 +1,465 @@
+package shiftswap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/notificationpolicy"
+	"github.com/InariTheFox/oncall/pkg/notifier"
+	"github.com/InariTheFox/oncall/pkg/schedule"
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+// JobNotify is published for each user to tell about a shift swap, with the
+// shift swap ID, the user ID and the action as arguments. Teammates are told
+// about new requests and the beneficiary when their request is taken.
+const JobNotify worker.JobType = "shift_swap_notify"
+
+// timeFormat is how the ranges of shift swaps are shown to users.
+const timeFormat = "Mon Jan 2 15:04 MST"
+
+type Service struct {
+	mtx       sync.Mutex
+	store     Store
+	schedules *schedule.Service
+	users     *user.Service
+	policies  *notificationpolicy.Service
+	notifiers *notifier.Service
+	worker    worker.Worker
+	now       func() time.Time
+}
+
+func NewService(store Store, schedules *schedule.Service, users *user.Service, policies *notificationpolicy.Service, notifiers *notifier.Service, w worker.Worker) *Service {
+	return &Service{
+		store:     store,
+		schedules: schedules,
+		users:     users,
+		policies:  policies,
+		notifiers: notifiers,
+		worker:    w,
+		now:       time.Now,
+	}
+}
+
+// Create opens a swap request for the shifts of the beneficiary within the
+// range and tells the other participants of the schedule about it.
+func (s *Service) Create(ctx context.Context, swap *ShiftSwap) error {
+	now := s.now()
+
+	if err := swap.Validate(now); err != nil {
+		return err
+	}
+
+	sched, err := s.schedules.Get(ctx, swap.ScheduleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.participant(ctx, sched, swap.Beneficiary); err != nil {
+		return err
+	}
+
+	shifts, err := s.beneficiaryShifts(ctx, swap)
+	if err != nil {
+		return err
+	}
+
+	if len(shifts) == 0 {
+		return fmt.Errorf("%w: %s has no shifts to swap in the range", ErrInvalidShiftSwap, swap.Beneficiary)
+	}
+
+	swap.ID = uuid.NewString()
+	swap.Status = StatusOpen
+	swap.Benefactor = ""
+	swap.OverrideIDs = nil
+	swap.TakenAt = nil
+	swap.CreatedAt = now
+	swap.UpdatedAt = now
+
+	if err := s.store.Create(ctx, swap); err != nil {
+		return err
+	}
+
+	if err := s.record(ctx, swap, ActionCreated, swap.Beneficiary); err != nil {
+		return err
+	}
+
+	for _, userID := range sched.Participants() {
+		if userID != swap.Beneficiary {
+			s.notify(ctx, swap, userID, ActionCreated)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*ShiftSwap, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	swap, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.expire(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// List returns the shift swaps of the schedule, or of all schedules when
+// scheduleID is empty, optionally only those with the status.
+func (s *Service) List(ctx context.Context, scheduleID string, status Status) ([]*ShiftSwap, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	swaps, err := s.store.List(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ShiftSwap, 0, len(swaps))
+	for _, swap := range swaps {
+		if err := s.expire(ctx, swap); err != nil {
+			return nil, err
+		}
+
+		if status == "" || swap.Status == status {
+			result = append(result, swap)
+		}
+	}
+
+	return result, nil
+}
+
+// Update changes the range and description of an open swap request. Only
+// the beneficiary can change it.
+func (s *Service) Update(ctx context.Context, id, actor string, start, end time.Time, description string) (*ShiftSwap, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	swap, err := s.open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.Beneficiary != actor {
+		return nil, ErrNotBeneficiary
+	}
+
+	swap.SwapStart = start
+	swap.SwapEnd = end
+	swap.Description = description
+
+	if err := swap.Validate(s.now()); err != nil {
+		return nil, err
+	}
+
+	shifts, err := s.beneficiaryShifts(ctx, swap)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shifts) == 0 {
+		return nil, fmt.Errorf("%w: %s has no shifts to swap in the range", ErrInvalidShiftSwap, swap.Beneficiary)
+	}
+
+	swap.UpdatedAt = s.now()
+
+	if err := s.store.Update(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	return swap, s.record(ctx, swap, ActionUpdated, actor)
+}
+
+// Delete withdraws an open swap request. Only the beneficiary can withdraw
+// it.
+func (s *Service) Delete(ctx context.Context, id, actor string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	swap, err := s.open(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if swap.Beneficiary != actor {
+		return ErrNotBeneficiary
+	}
+
+	swap.Status = StatusDeleted
+	swap.UpdatedAt = s.now()
+
+	if err := s.store.Update(ctx, swap); err != nil {
+		return err
+	}
+
+	return s.record(ctx, swap, ActionDeleted, actor)
+}
+
+// Take accepts an open swap request on behalf of the benefactor. Each shift
+// of the beneficiary within the range is overridden with the benefactor in
+// their place, keeping anyone else on call alongside them.
+func (s *Service) Take(ctx context.Context, id, benefactor string) (*ShiftSwap, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	swap, err := s.open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if swap.Beneficiary == benefactor {
+		return nil, ErrBeneficiaryCannotTake
+	}
+
+	sched, err := s.schedules.Get(ctx, swap.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.participant(ctx, sched, benefactor); err != nil {
+		return nil, err
+	}
+
+	shifts, err := s.beneficiaryShifts(ctx, swap)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shifts) == 0 {
+		return nil, fmt.Errorf("%w: %s no longer has shifts in the range", ErrInvalidShiftSwap, swap.Beneficiary)
+	}
+
+	var overrideIDs []string
+	for _, shift := range shifts {
+		o := &schedule.Override{
+			UserIDs: swapUsers(shift.UserIDs, swap.Beneficiary, benefactor),
+			Start:   shift.Start,
+			End:     shift.End,
+		}
+
+		if err := s.schedules.AddOverride(ctx, swap.ScheduleID, o); err != nil {
+			s.removeOverrides(ctx, swap.ScheduleID, overrideIDs)
+			return nil, err
+		}
+
+		overrideIDs = append(overrideIDs, o.ID)
+	}
+
+	now := s.now()
+	swap.Status = StatusTaken
+	swap.Benefactor = benefactor
+	swap.OverrideIDs = overrideIDs
+	swap.TakenAt = &now
+	swap.UpdatedAt = now
+
+	if err := s.store.Update(ctx, swap); err != nil {
+		s.removeOverrides(ctx, swap.ScheduleID, overrideIDs)
+		return nil, err
+	}
+
+	if err := s.record(ctx, swap, ActionTaken, benefactor); err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, swap, swap.Beneficiary, ActionTaken)
+
+	return swap, nil
+}
+
+// History returns the changes made to a shift swap, oldest first.
+func (s *Service) History(ctx context.Context, id string) ([]*HistoryEntry, error) {
+	if _, err := s.store.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListHistory(ctx, id)
+}
+
+// HandleNotify tells a user about a shift swap by their preferred
+// notification method.
+func (s *Service) HandleNotify(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 3 {
+		fmt.Printf("Invalid %s job %s, expected 3 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	swapID, userID, action := job.Args[0], job.Args[1], Action(job.Args[2])
+
+	swap, err := s.store.Get(ctx, swapID)
+	if err != nil {
+		fmt.Printf("Failed to load shift swap %s: %s\n", swapID, err)
+		return
+	}
+
+	sched, err := s.schedules.Get(ctx, swap.ScheduleID)
+	if err != nil {
+		fmt.Printf("Failed to load schedule %s: %s\n", swap.ScheduleID, err)
+		return
+	}
+
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		fmt.Printf("Failed to load user %s: %s\n", userID, err)
+		return
+	}
+
+	loc, err := time.LoadLocation(u.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	period := fmt.Sprintf("from %s to %s", swap.SwapStart.In(loc).Format(timeFormat), swap.SwapEnd.In(loc).Format(timeFormat))
+
+	m := &notifier.Message{UserID: userID}
+	switch action {
+	case ActionCreated:
+		m.Title = fmt.Sprintf("%s is looking for someone to take their shifts on %s", s.userName(ctx, swap.Beneficiary), sched.Name)
+		m.Text = fmt.Sprintf("%s asks for someone to take over their shifts on %s %s.", s.userName(ctx, swap.Beneficiary), sched.Name, period)
+		if swap.Description != "" {
+			m.Text += "\n\n" + swap.Description
+		}
+	case ActionTaken:
+		m.Title = fmt.Sprintf("%s took your shift swap on %s", s.userName(ctx, swap.Benefactor), sched.Name)
+		m.Text = fmt.Sprintf("%s took over your shifts on %s %s.", s.userName(ctx, swap.Benefactor), sched.Name, period)
+	default:
+		fmt.Printf("Invalid %s job %s, unknown action %q\n", job.Type, job.ID, action)
+		return
+	}
+
+	method, err := s.policies.PreferredMethod(ctx, userID)
+	if err != nil {
+		fmt.Printf("Failed to load notification method of %s: %s\n", userID, err)
+		return
+	}
+
+	if err := s.notifiers.Notify(ctx, method, m); err != nil {
+		fmt.Printf("Failed to publish notification of shift swap %s to %s: %s\n", swap.ID, userID, err)
+	}
+}
+
+// participant checks that the actor is an existing user who takes part in
+// the schedule.
+func (s *Service) participant(ctx context.Context, sched *schedule.Schedule, actor string) error {
+	if _, err := s.users.Get(ctx, actor); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return ErrNotParticipant
+		}
+		return err
+	}
+
+	if !slices.Contains(sched.Participants(), actor) {
+		return ErrNotParticipant
+	}
+
+	return nil
+}
+
+// userName returns the username of the user, or the ID of users that no
+// longer exist.
+func (s *Service) userName(ctx context.Context, userID string) string {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return userID
+	}
+
+	return u.Username
+}
+
+// open returns the swap if it can still be changed or taken.
+func (s *Service) open(ctx context.Context, id string) (*ShiftSwap, error) {
+	swap, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.expire(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	if swap.Status != StatusOpen {
+		return nil, ErrShiftSwapNotOpen
+	}
+
+	return swap, nil
+}
+
+// expire marks open swaps which have started without being taken as past
+// due.
+func (s *Service) expire(ctx context.Context, swap *ShiftSwap) error {
+	if swap.Status != StatusOpen || swap.SwapStart.After(s.now()) {
+		return nil
+	}
+
+	swap.Status = StatusPastDue
+	swap.UpdatedAt = s.now()
+
+	return s.store.Update(ctx, swap)
+}
+
+// beneficiaryShifts returns the final shifts within the swap range in which
+// the beneficiary is on call.
+func (s *Service) beneficiaryShifts(ctx context.Context, swap *ShiftSwap) ([]*schedule.Shift, error) {
+	shifts, err := s.schedules.FinalShifts(ctx, swap.ScheduleID, swap.SwapStart, swap.SwapEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*schedule.Shift
+	for _, shift := range shifts {
+		if slices.Contains(shift.UserIDs, swap.Beneficiary) {
+			result = append(result, shift)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Service) removeOverrides(ctx context.Context, scheduleID string, overrideIDs []string) {
+	for _, id := range overrideIDs {
+		if err := s.schedules.DeleteOverride(ctx, scheduleID, id); err != nil {
+			fmt.Printf("Failed to remove override %s of schedule %s: %s\n", id, scheduleID, err)
+		}
+	}
+}
+
+func (s *Service) record(ctx context.Context, swap *ShiftSwap, action Action, actor string) error {
+	return s.store.AddHistory(ctx, &HistoryEntry{
+		ID:          uuid.NewString(),
+		ShiftSwapID: swap.ID,
+		Action:      action,
+		Actor:       actor,
+		CreatedAt:   swap.UpdatedAt,
+	})
+}
+
+func (s *Service) notify(ctx context.Context, swap *ShiftSwap, userID string, action Action) {
+	if err := s.worker.Enqueue(ctx, JobNotify, swap.ID, userID, string(action)); err != nil {
+		fmt.Printf("Failed to publish notification of shift swap %s to %s: %s\n", swap.ID, userID, err)
+	}
+}
+
+// swapUsers replaces the beneficiary with the benefactor.
+func swapUsers(userIDs []string, beneficiary, benefactor string) []string {
+	result := []string{benefactor}
+	for _, userID := range userIDs {
+		if userID != beneficiary && userID != benefactor {
+			result = append(result, userID)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,94 @@
+package shiftswap
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps shift swaps in the SQL database, which the server and
+// workers share.
+type SQLStore struct {
+	swaps   *sqlstore.Table[ShiftSwap]
+	history *sqlstore.Table[HistoryEntry]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	swaps, err := sqlstore.NewTable(db, "shift_swaps", func(s *ShiftSwap) string { return s.ID },
+		sqlstore.Column[ShiftSwap]{Name: "schedule_id", Value: func(s *ShiftSwap) string { return s.ScheduleID }})
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := sqlstore.NewTable(db, "shift_swap_history", func(e *HistoryEntry) string { return e.ID },
+		sqlstore.Column[HistoryEntry]{Name: "shift_swap_id", Value: func(e *HistoryEntry) string { return e.ShiftSwapID }})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{swaps: swaps, history: history}, nil
+}
+
+func (st *SQLStore) Create(ctx context.Context, s *ShiftSwap) error {
+	return st.swaps.Insert(ctx, s)
+}
+
+func (st *SQLStore) Get(ctx context.Context, id string) (*ShiftSwap, error) {
+	s, err := st.swaps.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrShiftSwapNotFound
+	}
+
+	return s, err
+}
+
+func (st *SQLStore) Update(ctx context.Context, s *ShiftSwap) error {
+	err := st.swaps.Update(ctx, s)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrShiftSwapNotFound
+	}
+
+	return err
+}
+
+func (st *SQLStore) List(ctx context.Context, scheduleID string) ([]*ShiftSwap, error) {
+	var where sqlstore.Where
+	if scheduleID != "" {
+		where = sqlstore.Where{"schedule_id": scheduleID}
+	}
+
+	swaps, err := st.swaps.Find(ctx, where)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(swaps, func(i, j int) bool {
+		if !swaps[i].SwapStart.Equal(swaps[j].SwapStart) {
+			return swaps[i].SwapStart.Before(swaps[j].SwapStart)
+		}
+		return swaps[i].ID < swaps[j].ID
+	})
+
+	return swaps, nil
+}
+
+func (st *SQLStore) AddHistory(ctx context.Context, e *HistoryEntry) error {
+	return st.history.Insert(ctx, e)
+}
+
+func (st *SQLStore) ListHistory(ctx context.Context, shiftSwapID string) ([]*HistoryEntry, error) {
+	history, err := st.history.Find(ctx, sqlstore.Where{"shift_swap_id": shiftSwapID})
+	if err != nil {
+		return nil, err
+	}
+
+	if history == nil {
+		history = []*HistoryEntry{}
+	}
+
+	return history, nil
+}
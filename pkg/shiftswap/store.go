@@ -0,0 +1,16 @@
+package shiftswap
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, s *ShiftSwap) error
+	Get(ctx context.Context, id string) (*ShiftSwap, error)
+	Update(ctx context.Context, s *ShiftSwap) error
+	// List returns the shift swaps of the schedule, or of all schedules when
+	// scheduleID is empty, ordered by the start of the swap.
+	List(ctx context.Context, scheduleID string) ([]*ShiftSwap, error)
+
+	AddHistory(ctx context.Context, e *HistoryEntry) error
+	// ListHistory returns the history of a shift swap, oldest first.
+	ListHistory(ctx context.Context, shiftSwapID string) ([]*HistoryEntry, error)
+}
@@ -0,0 +1,123 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// timeLayout formats times with a fixed width, so that they sort as text in
+// the order they happened.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Time returns the value of a column holding t, which sorts as text in time
+// order.
+func Time(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// Cond is a condition on the columns of the records a table selects.
+type Cond struct {
+	sql  string
+	args []any
+}
+
+// Eq matches the records whose column has the value.
+func Eq(column, value string) Cond {
+	return Cond{sql: column + " = ?", args: []any{value}}
+}
+
+// NotEq matches the records whose column does not have the value.
+func NotEq(column, value string) Cond {
+	return Cond{sql: column + " <> ?", args: []any{value}}
+}
+
+// In matches the records whose column has any of the values, none when there
+// are no values.
+func In(column string, values []string) Cond {
+	if len(values) == 0 {
+		return Cond{sql: "1 = 0"}
+	}
+
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+
+	return Cond{sql: column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", args: args}
+}
+
+// AtLeast matches the records whose column sorts at or after the value.
+func AtLeast(column, value string) Cond {
+	return Cond{sql: column + " >= ?", args: []any{value}}
+}
+
+// Before matches the records whose column sorts before the value.
+func Before(column, value string) Cond {
+	return Cond{sql: column + " < ?", args: []any{value}}
+}
+
+// Expr is a condition written in SQL, with ? placeholders for the args, such
+// as a subquery on another table.
+func Expr(sql string, args ...any) Cond {
+	return Cond{sql: sql, args: args}
+}
+
+// Order sorts the records a table selects by the column, the ID breaking ties
+// between equal values.
+type Order struct {
+	Column     string
+	Descending bool
+}
+
+// After matches the records which sort after the one whose column has the
+// value and whose ID is id, so pages continue from where the last one ended.
+func (o Order) After(value, id string) Cond {
+	op := ">"
+	if o.Descending {
+		op = "<"
+	}
+
+	return Cond{
+		sql:  "(" + o.Column + " " + op + " ? OR (" + o.Column + " = ? AND id " + op + " ?))",
+		args: []any{value, value, id},
+	}
+}
+
+func (o Order) sql() string {
+	if o.Descending {
+		return " ORDER BY " + o.Column + " DESC, id DESC"
+	}
+
+	return " ORDER BY " + o.Column + ", id"
+}
+
+// Select returns up to limit records matching all of the conditions, in the
+// order. A limit of 0 returns them all.
+func (t *Table[T]) Select(ctx context.Context, conds []Cond, order Order, limit int) ([]*T, error) {
+	query := "SELECT data FROM " + t.name
+
+	var args []any
+	if len(conds) > 0 {
+		sqls := make([]string, len(conds))
+		for i, c := range conds {
+			sqls[i] = c.sql
+			args = append(args, c.args...)
+		}
+
+		query += " WHERE " + strings.Join(sqls, " AND ")
+	}
+
+	query += order.sql()
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := t.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.scan(rows)
+}
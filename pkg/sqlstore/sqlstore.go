@@ -0,0 +1,236 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/setting"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned when a table has no record with the ID.
+var ErrNotFound = errors.New("record not found")
+
+type dialect string
+
+const (
+	dialectSQLite   dialect = "sqlite3"
+	dialectPostgres dialect = "postgres"
+)
+
+// DB is the SQL database the stores of the services keep their data in, so
+// it is shared by the server and any standalone workers.
+type DB struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+type txKey struct{}
+
+// Open connects to the database of the configuration and applies the
+// migrations of the tables shared by the stores.
+func Open(cfg *setting.Cfg) (*DB, error) {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	d := dialect(cfg.DatabaseType)
+	switch d {
+	case dialectSQLite:
+		if err := os.MkdirAll(filepath.Dir(cfg.DatabasePath), 0o750); err != nil {
+			return nil, err
+		}
+
+		// Transactions take the write lock when they begin, and wait for it
+		// while another process such as a worker holds it.
+		db, err = sql.Open("sqlite", "file:"+cfg.DatabasePath+"?"+url.Values{
+			"_pragma": {"busy_timeout(10000)", "journal_mode(WAL)"},
+			"_txlock": {"immediate"},
+		}.Encode())
+	case dialectPostgres:
+		db, err = sql.Open("postgres", cfg.DatabaseURL)
+	default:
+		return nil, fmt.Errorf("unknown database type %q", cfg.DatabaseType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &DB{db: db, dialect: d}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to the %s database: %w", d, err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS migrations (id TEXT PRIMARY KEY, applied_at TEXT NOT NULL)`); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	err = s.migrate(context.Background(), "create table sequences", func(ctx context.Context) error {
+		_, err := s.exec(ctx, `CREATE TABLE sequences (name TEXT PRIMARY KEY, value BIGINT NOT NULL)`)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *DB) Close() error {
+	return s.db.Close()
+}
+
+// InTransaction runs fn in a transaction, which the tables use for queries
+// with the context passed to fn. Nested calls join the outer transaction.
+func (s *DB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// NextValue increments the sequence with the name and returns its new value,
+// which starts at 1.
+func (s *DB) NextValue(ctx context.Context, name string) (int, error) {
+	var value int
+	err := s.queryRow(ctx, `INSERT INTO sequences (name, value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET value = sequences.value + 1
+		RETURNING value`, name).Scan(&value)
+
+	return value, err
+}
+
+// Lock takes a lock on the key which is held until the transaction of the
+// context ends, so that transactions of any process taking the same lock run
+// one after the other.
+func (s *DB) Lock(ctx context.Context, key string) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); !ok {
+		return fmt.Errorf("lock %q taken outside of a transaction", key)
+	}
+
+	// Transactions on sqlite3 take the lock of the whole database when they
+	// begin, so they already run one after the other.
+	if s.dialect != dialectPostgres {
+		return nil
+	}
+
+	_, err := s.exec(ctx, "SELECT pg_advisory_xact_lock(hashtext(?))", key)
+
+	return err
+}
+
+// migrate runs fn in a transaction unless the migration with the ID has
+// already been applied, then records it as applied. Migrations are never
+// changed once released, new ones are added instead.
+func (s *DB) migrate(ctx context.Context, id string, fn func(ctx context.Context) error) error {
+	return s.InTransaction(ctx, func(ctx context.Context) error {
+		// Processes starting together must not both apply the migration.
+		if err := s.Lock(ctx, "migrations"); err != nil {
+			return err
+		}
+
+		var n int
+		if err := s.queryRow(ctx, "SELECT COUNT(*) FROM migrations WHERE id = ?", id).Scan(&n); err != nil {
+			return err
+		}
+
+		if n > 0 {
+			return nil
+		}
+
+		if err := fn(ctx); err != nil {
+			return fmt.Errorf("migration %q failed: %w", id, err)
+		}
+
+		return s.applied(ctx, id)
+	})
+}
+
+// applied records the migration with the ID as applied.
+func (s *DB) applied(ctx context.Context, id string) error {
+	_, err := s.exec(ctx, "INSERT INTO migrations (id, applied_at) VALUES (?, ?)", id, time.Now().UTC().Format(time.RFC3339))
+
+	return err
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *DB) querier(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return s.db
+}
+
+func (s *DB) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.querier(ctx).ExecContext(ctx, s.rebind(query), args...)
+}
+
+func (s *DB) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return s.querier(ctx).QueryContext(ctx, s.rebind(query), args...)
+}
+
+func (s *DB) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return s.querier(ctx).QueryRowContext(ctx, s.rebind(query), args...)
+}
+
+// rebind replaces the ? placeholders of the query with the numbered ones of
+// postgres.
+func (s *DB) rebind(query string) string {
+	if s.dialect != dialectPostgres {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			sb.WriteRune(r)
+			continue
+		}
+
+		n++
+		sb.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return sb.String()
+}
+
+// autoIncrement is the column type of the sequence numbers of table rows.
+func (s *DB) autoIncrement() string {
+	if s.dialect == dialectPostgres {
+		return "BIGSERIAL PRIMARY KEY"
+	}
+
+	return "INTEGER PRIMARY KEY AUTOINCREMENT"
+}
@@ -0,0 +1,339 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Table keeps records as JSON documents, keyed by their ID and indexed by the
+// columns they are looked up by. Rows are returned in the order they were
+// first saved.
+type Table[T any] struct {
+	db      *DB
+	name    string
+	id      func(*T) string
+	columns []Column[T]
+}
+
+// Column is a value of the records kept next to them, so they can be looked
+// up by it.
+type Column[T any] struct {
+	Name   string
+	Value  func(*T) string
+	Unique bool
+}
+
+// Where matches the records whose columns have all of the values.
+type Where map[string]string
+
+// NewTable applies the migrations of the table with the name. It is created
+// with the columns the first time, and columns added later are filled in from
+// the records already stored.
+func NewTable[T any](db *DB, name string, id func(*T) string, columns ...Column[T]) (*Table[T], error) {
+	t := &Table[T]{db: db, name: name, id: id, columns: columns}
+	ctx := context.Background()
+
+	err := db.migrate(ctx, "create table "+name, func(ctx context.Context) error {
+		defs := []string{"seq " + db.autoIncrement(), "id TEXT NOT NULL UNIQUE"}
+		for _, c := range columns {
+			defs = append(defs, c.Name+" TEXT NOT NULL DEFAULT ''")
+		}
+		defs = append(defs, "data TEXT NOT NULL")
+
+		if _, err := db.exec(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", name, strings.Join(defs, ", "))); err != nil {
+			return err
+		}
+
+		// The columns are part of the new table, so they need not be added
+		// by their own migrations.
+		for _, c := range columns {
+			if err := t.createIndex(ctx, c); err != nil {
+				return err
+			}
+
+			if err := db.applied(ctx, t.addColumnMigration(c)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range columns {
+		err := db.migrate(ctx, t.addColumnMigration(c), func(ctx context.Context) error {
+			if _, err := db.exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT NOT NULL DEFAULT ''", name, c.Name)); err != nil {
+				return err
+			}
+
+			if err := t.fillColumn(ctx, c); err != nil {
+				return err
+			}
+
+			return t.createIndex(ctx, c)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *Table[T]) addColumnMigration(c Column[T]) string {
+	return "add column " + t.name + "." + c.Name
+}
+
+func (t *Table[T]) createIndex(ctx context.Context, c Column[T]) error {
+	unique := ""
+	if c.Unique {
+		unique = "UNIQUE "
+	}
+
+	_, err := t.db.exec(ctx, fmt.Sprintf("CREATE %sINDEX %s_%s ON %s (%s)", unique, t.name, c.Name, t.name, c.Name))
+
+	return err
+}
+
+// fillColumn sets the column of the records already stored.
+func (t *Table[T]) fillColumn(ctx context.Context, c Column[T]) error {
+	records, err := t.Find(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range records {
+		if _, err := t.db.exec(ctx, fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", t.name, c.Name), c.Value(v), t.id(v)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Table[T]) Get(ctx context.Context, id string) (*T, error) {
+	return t.FindOne(ctx, Where{"id": id})
+}
+
+// Find returns the records matching where, or all records when it is empty.
+func (t *Table[T]) Find(ctx context.Context, where Where) ([]*T, error) {
+	cond, args := where.sql()
+
+	rows, err := t.db.query(ctx, "SELECT data FROM "+t.name+cond+" ORDER BY seq", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.scan(rows)
+}
+
+// FindOne returns the first record matching where, or ErrNotFound.
+func (t *Table[T]) FindOne(ctx context.Context, where Where) (*T, error) {
+	cond, args := where.sql()
+
+	var data string
+	err := t.db.queryRow(ctx, "SELECT data FROM "+t.name+cond+" ORDER BY seq LIMIT 1", args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t.decode(data)
+}
+
+func (t *Table[T]) Insert(ctx context.Context, v *T) error {
+	names, values, err := t.row(v)
+	if err != nil {
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	_, err = t.db.exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", t.name, strings.Join(names, ", "), placeholders), values...)
+
+	return err
+}
+
+// Update replaces the record with the ID of v, or returns ErrNotFound.
+func (t *Table[T]) Update(ctx context.Context, v *T) error {
+	names, values, err := t.row(v)
+	if err != nil {
+		return err
+	}
+
+	set := make([]string, 0, len(names)-1)
+	for _, name := range names[1:] {
+		set = append(set, name+" = ?")
+	}
+
+	res, err := t.db.exec(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", t.name, strings.Join(set, ", ")), append(values[1:], values[0])...)
+	if err != nil {
+		return err
+	}
+
+	return notFoundIfNone(res)
+}
+
+// Save inserts v, or replaces the record with its ID.
+func (t *Table[T]) Save(ctx context.Context, v *T) error {
+	names, values, err := t.row(v)
+	if err != nil {
+		return err
+	}
+
+	set := make([]string, 0, len(names)-1)
+	for _, name := range names[1:] {
+		set = append(set, name+" = excluded."+name)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	_, err = t.db.exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (id) DO UPDATE SET %s",
+		t.name, strings.Join(names, ", "), placeholders, strings.Join(set, ", ")), values...)
+
+	return err
+}
+
+// Delete deletes the record with the ID, or returns ErrNotFound.
+func (t *Table[T]) Delete(ctx context.Context, id string) error {
+	n, err := t.DeleteWhere(ctx, Where{"id": id})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteWhere deletes the records matching where and returns how many there
+// were.
+func (t *Table[T]) DeleteWhere(ctx context.Context, where Where) (int64, error) {
+	cond, args := where.sql()
+
+	res, err := t.db.exec(ctx, "DELETE FROM "+t.name+cond, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Take deletes the record with the ID and returns it, or returns
+// ErrNotFound.
+func (t *Table[T]) Take(ctx context.Context, id string) (*T, error) {
+	var data string
+	err := t.db.queryRow(ctx, "DELETE FROM "+t.name+" WHERE id = ? RETURNING data", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t.decode(data)
+}
+
+// Trim deletes the records matching where except for the latest keep. The
+// where must not be empty.
+func (t *Table[T]) Trim(ctx context.Context, where Where, keep int) error {
+	cond, args := where.sql()
+
+	_, err := t.db.exec(ctx, fmt.Sprintf("DELETE FROM %s%s AND seq NOT IN (SELECT seq FROM %s%s ORDER BY seq DESC LIMIT %d)",
+		t.name, cond, t.name, cond, keep), append(args, args...)...)
+
+	return err
+}
+
+// row returns the column names and values of the record, the ID first.
+func (t *Table[T]) row(v *T) ([]string, []any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := []string{"id"}
+	values := []any{t.id(v)}
+	for _, c := range t.columns {
+		names = append(names, c.Name)
+		values = append(values, c.Value(v))
+	}
+
+	return append(names, "data"), append(values, string(data)), nil
+}
+
+// scan decodes the records of the rows, which select their data, and closes
+// them.
+func (t *Table[T]) scan(rows *sql.Rows) ([]*T, error) {
+	defer rows.Close()
+
+	var records []*T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		v, err := t.decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, v)
+	}
+
+	return records, rows.Err()
+}
+
+func (t *Table[T]) decode(data string) (*T, error) {
+	v := new(T)
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return nil, fmt.Errorf("failed to decode %s record: %w", t.name, err)
+	}
+
+	return v, nil
+}
+
+// sql returns the WHERE clause matching the values, and its arguments.
+func (w Where) sql() (string, []any) {
+	if len(w) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(w))
+	for name := range w {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	conds := make([]string, 0, len(names))
+	args := make([]any, 0, len(names))
+	for _, name := range names {
+		conds = append(conds, name+" = ?")
+		args = append(args, w[name])
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func notFoundIfNone(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/setting"
+)
+
+type record struct {
+	ID    string
+	Name  string
+	Group string
+}
+
+func newRecordTable(t *testing.T, db *DB, columns ...Column[record]) *Table[record] {
+	t.Helper()
+
+	table, err := NewTable(db, "records", func(r *record) string { return r.ID }, columns...)
+	if err != nil {
+		t.Fatalf("NewTable() error = %v", err)
+	}
+
+	return table
+}
+
+var (
+	nameColumn  = Column[record]{Name: "name", Value: func(r *record) string { return r.Name }, Unique: true}
+	groupColumn = Column[record]{Name: "grp", Value: func(r *record) string { return r.Group }}
+)
+
+func names(records []*record) []string {
+	result := []string{}
+	for _, r := range records {
+		result = append(result, r.Name)
+	}
+
+	return result
+}
+
+func TestTable(t *testing.T) {
+	ctx := context.Background()
+	table := newRecordTable(t, InitTestDB(t), nameColumn, groupColumn)
+
+	for _, r := range []*record{
+		{ID: "1", Name: "alice", Group: "a"},
+		{ID: "2", Name: "bob", Group: "b"},
+		{ID: "3", Name: "carol", Group: "a"},
+	} {
+		if err := table.Insert(ctx, r); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "duplicate unique column",
+			run:     func() error { return table.Insert(ctx, &record{ID: "4", Name: "alice"}) },
+			want:    []string{"alice", "bob", "carol"},
+			wantErr: true,
+		},
+		{
+			name: "update",
+			run:  func() error { return table.Update(ctx, &record{ID: "2", Name: "bobby", Group: "a"}) },
+			want: []string{"alice", "bobby", "carol"},
+		},
+		{
+			name: "save inserts",
+			run:  func() error { return table.Save(ctx, &record{ID: "4", Name: "dave", Group: "b"}) },
+			want: []string{"alice", "bobby", "carol", "dave"},
+		},
+		{
+			name: "save replaces in place",
+			run:  func() error { return table.Save(ctx, &record{ID: "1", Name: "alicia", Group: "b"}) },
+			want: []string{"alicia", "bobby", "carol", "dave"},
+		},
+		{
+			name: "delete",
+			run:  func() error { return table.Delete(ctx, "3") },
+			want: []string{"alicia", "bobby", "dave"},
+		},
+		{
+			name: "trim keeps the latest",
+			run:  func() error { return table.Trim(ctx, Where{"grp": "b"}, 1) },
+			want: []string{"bobby", "dave"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+
+			records, err := table.Find(ctx, nil)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+
+			if got := names(records); !slices.Equal(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableNotFound(t *testing.T) {
+	ctx := context.Background()
+	table := newRecordTable(t, InitTestDB(t), groupColumn)
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{name: "get", run: func() error { _, err := table.Get(ctx, "missing"); return err }},
+		{name: "find one", run: func() error { _, err := table.FindOne(ctx, Where{"grp": "a"}); return err }},
+		{name: "update", run: func() error { return table.Update(ctx, &record{ID: "missing"}) }},
+		{name: "delete", run: func() error { return table.Delete(ctx, "missing") }},
+		{name: "take", run: func() error { _, err := table.Take(ctx, "missing"); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, ErrNotFound) {
+				t.Errorf("error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestTableTake(t *testing.T) {
+	ctx := context.Background()
+	table := newRecordTable(t, InitTestDB(t))
+
+	if err := table.Insert(ctx, &record{ID: "1", Name: "alice"}); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	r, err := table.Take(ctx, "1")
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+
+	if r.Name != "alice" {
+		t.Errorf("Take() = %+v, want alice", r)
+	}
+
+	if _, err := table.Take(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Take() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestInTransactionRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := InitTestDB(t)
+	table := newRecordTable(t, db)
+
+	failed := errors.New("failed")
+	err := db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := table.Insert(ctx, &record{ID: "1"}); err != nil {
+			return err
+		}
+
+		// Nested transactions join the outer one.
+		return db.InTransaction(ctx, func(ctx context.Context) error {
+			if err := table.Insert(ctx, &record{ID: "2"}); err != nil {
+				return err
+			}
+			return failed
+		})
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("InTransaction() error = %v, want %v", err, failed)
+	}
+
+	records, err := table.Find(ctx, nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	if len(records) != 0 {
+		t.Errorf("%d records kept after rolling back", len(records))
+	}
+}
+
+func TestNextValueConcurrently(t *testing.T) {
+	ctx := context.Background()
+	db := InitTestDB(t)
+
+	var (
+		mtx    sync.Mutex
+		values []int
+		wg     sync.WaitGroup
+	)
+
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, err := db.NextValue(ctx, "numbers")
+			if err != nil {
+				t.Errorf("NextValue() error = %v", err)
+				return
+			}
+
+			mtx.Lock()
+			values = append(values, v)
+			mtx.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	slices.Sort(values)
+	for i, v := range values {
+		if v != i+1 {
+			t.Fatalf("values = %v, want 1 to 20", values)
+		}
+	}
+}
+
+func TestLockOutsideTransaction(t *testing.T) {
+	if err := InitTestDB(t).Lock(context.Background(), "key"); err == nil {
+		t.Error("Lock() outside of a transaction succeeded")
+	}
+}
+
+func TestMigrationsAddColumns(t *testing.T) {
+	ctx := context.Background()
+	cfg := &setting.Cfg{
+		DatabaseType: string(dialectSQLite),
+		DatabasePath: filepath.Join(t.TempDir(), "oncall.db"),
+	}
+
+	open := func() *DB {
+		db, err := Open(cfg)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		return db
+	}
+
+	table := newRecordTable(t, open())
+	for _, r := range []*record{{ID: "1", Name: "alice", Group: "a"}, {ID: "2", Name: "bob", Group: "b"}} {
+		if err := table.Insert(ctx, r); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	// A later release looks the records up by a column they were not
+	// stored with.
+	table = newRecordTable(t, open(), groupColumn)
+
+	records, err := table.Find(ctx, Where{"grp": "b"})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	if got := names(records); !slices.Equal(got, []string{"bob"}) {
+		t.Errorf("records of group b = %q, want [bob]", got)
+	}
+
+	// Migrations which have been applied are not applied again.
+	newRecordTable(t, open(), groupColumn)
+}
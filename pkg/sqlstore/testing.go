@@ -0,0 +1,40 @@
+package sqlstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/setting"
+)
+
+// InitTestDB opens a sqlite3 database in a temporary directory, which is
+// removed when the test ends.
+func InitTestDB(t testing.TB) *DB {
+	t.Helper()
+
+	return InitTestDBs(t, 1)[0]
+}
+
+// InitTestDBs opens n connections to the same sqlite3 database in a
+// temporary directory, as the server and standalone workers would.
+func InitTestDBs(t testing.TB, n int) []*DB {
+	t.Helper()
+
+	cfg := &setting.Cfg{
+		DatabaseType: string(dialectSQLite),
+		DatabasePath: filepath.Join(t.TempDir(), "oncall.db"),
+	}
+
+	dbs := make([]*DB, n)
+	for i := range dbs {
+		db, err := Open(cfg)
+		if err != nil {
+			t.Fatalf("failed to open test database: %s", err)
+		}
+
+		t.Cleanup(func() { db.Close() })
+		dbs[i] = db
+	}
+
+	return dbs
+}
@@ -0,0 +1,37 @@
+package team
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrTeamNotFound  = errors.New("team not found")
+	ErrInvalidTeam   = errors.New("invalid team")
+	ErrTeamNameTaken = errors.New("team name is already taken")
+)
+
+type Team struct {
+	ID    string
+	Name  string
+	Email string
+	// ChatChannel receives the notifications of the team, such as gaps in
+	// its schedules.
+	ChatChannel string
+	MemberIDs   []string
+	CreatedAt   time.Time
+}
+
+func (t *Team) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTeam)
+	}
+
+	return nil
+}
+
+func (t *Team) HasMember(userID string) bool {
+	return slices.Contains(t.MemberIDs, userID)
+}
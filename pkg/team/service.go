@@ -0,0 +1,176 @@
+package team
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/user"
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	mtx   sync.Mutex
+	store Store
+	users *user.Service
+}
+
+func NewService(store Store, users *user.Service) *Service {
+	return &Service{
+		store: store,
+		users: users,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, t *Team) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.checkMembers(ctx, t.MemberIDs); err != nil {
+		return err
+	}
+
+	t.ID = uuid.NewString()
+	t.MemberIDs = normalizeMembers(t.MemberIDs)
+	t.CreatedAt = time.Now()
+
+	return s.store.Create(ctx, t)
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*Team, error) {
+	return s.store.Get(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]*Team, error) {
+	return s.store.List(ctx)
+}
+
+// Update replaces the details of a team, keeping its members.
+func (s *Service) Update(ctx context.Context, t *Team) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	existing, err := s.store.Get(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	t.MemberIDs = existing.MemberIDs
+	t.CreatedAt = existing.CreatedAt
+
+	return s.store.Update(ctx, t)
+}
+
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
+
+func (s *Service) AddMember(ctx context.Context, teamID, userID string) (*Team, error) {
+	if _, err := s.users.Get(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, err := s.store.Get(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.MemberIDs = normalizeMembers(append(t.MemberIDs, userID))
+
+	return t, s.store.Update(ctx, t)
+}
+
+func (s *Service) RemoveMember(ctx context.Context, teamID, userID string) (*Team, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, err := s.store.Get(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.HasMember(userID) {
+		return nil, user.ErrUserNotFound
+	}
+
+	t.MemberIDs = slices.DeleteFunc(t.MemberIDs, func(id string) bool { return id == userID })
+
+	return t, s.store.Update(ctx, t)
+}
+
+// RemoveUser takes a deleted user out of every team.
+func (s *Service) RemoveUser(ctx context.Context, userID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	teams, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range teams {
+		if !t.HasMember(userID) {
+			continue
+		}
+
+		t.MemberIDs = slices.DeleteFunc(t.MemberIDs, func(id string) bool { return id == userID })
+		if err := s.store.Update(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UserGroupMemberIDs returns the members of the team, for escalation steps
+// notifying a user group.
+func (s *Service) UserGroupMemberIDs(ctx context.Context, groupID string) ([]string, error) {
+	t, err := s.store.Get(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.MemberIDs, nil
+}
+
+// TeamsOf returns the teams the user is a member of.
+func (s *Service) TeamsOf(ctx context.Context, userID string) ([]*Team, error) {
+	teams, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Team
+	for _, t := range teams {
+		if slices.Contains(t.MemberIDs, userID) {
+			result = append(result, t)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Service) checkMembers(ctx context.Context, userIDs []string) error {
+	for _, id := range userIDs {
+		if _, err := s.users.Get(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeMembers(userIDs []string) []string {
+	result := slices.Clone(userIDs)
+	sort.Strings(result)
+	return slices.Compact(result)
+}
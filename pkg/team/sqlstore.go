@@ -0,0 +1,108 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps teams in the SQL database, which the server and workers
+// share.
+type SQLStore struct {
+	db    *sqlstore.DB
+	teams *sqlstore.Table[Team]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	teams, err := sqlstore.NewTable(db, "teams", func(t *Team) string { return t.ID },
+		sqlstore.Column[Team]{Name: "name", Value: nameKey, Unique: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, teams: teams}, nil
+}
+
+func (st *SQLStore) Create(ctx context.Context, t *Team) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := st.checkName(ctx, t); err != nil {
+			return err
+		}
+
+		return st.teams.Insert(ctx, t)
+	})
+}
+
+func (st *SQLStore) Get(ctx context.Context, id string) (*Team, error) {
+	t, err := st.teams.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrTeamNotFound
+	}
+
+	return t, err
+}
+
+func (st *SQLStore) List(ctx context.Context) ([]*Team, error) {
+	teams, err := st.teams.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+
+	return teams, nil
+}
+
+func (st *SQLStore) Update(ctx context.Context, t *Team) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := st.checkName(ctx, t); err != nil {
+			return err
+		}
+
+		err := st.teams.Update(ctx, t)
+		if errors.Is(err, sqlstore.ErrNotFound) {
+			return ErrTeamNotFound
+		}
+
+		return err
+	})
+}
+
+func (st *SQLStore) Delete(ctx context.Context, id string) error {
+	err := st.teams.Delete(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrTeamNotFound
+	}
+
+	return err
+}
+
+// checkName returns ErrTeamNameTaken when another team has the name of the
+// team.
+func (st *SQLStore) checkName(ctx context.Context, t *Team) error {
+	existing, err := st.teams.FindOne(ctx, sqlstore.Where{"name": nameKey(t)})
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != t.ID {
+		return ErrTeamNameTaken
+	}
+
+	return nil
+}
+
+// nameKey is the name of the team as looked up, ignoring case.
+func nameKey(t *Team) string {
+	return strings.ToLower(t.Name)
+}
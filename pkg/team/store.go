@@ -0,0 +1,12 @@
+package team
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, t *Team) error
+	Get(ctx context.Context, id string) (*Team, error)
+	// List returns all teams ordered by name.
+	List(ctx context.Context) ([]*Team, error)
+	Update(ctx context.Context, t *Team) error
+	Delete(ctx context.Context, id string) error
+}
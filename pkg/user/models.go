@@ -0,0 +1,121 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrInvalidUser   = errors.New("invalid user")
+	ErrUsernameTaken = errors.New("username is already taken")
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+	// phonePattern matches E.164 numbers.
+	phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+type User struct {
+	ID       string
+	Username string
+	Name     string
+	Email    string
+	// Phone is an E.164 number, which has to be verified before SMS and
+	// calls are sent to it.
+	Phone         string
+	PhoneVerified bool
+	TimeZone      string
+	Role          Role
+	// ContactMethods are the addresses of the user in chat and messaging
+	// services, such as their Slack member ID.
+	ContactMethods []*ContactMethod
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type ContactMethod struct {
+	// Type names the service, such as slack or telegram.
+	Type       string
+	Address    string
+	Verified   bool
+	VerifiedAt *time.Time
+}
+
+// linkedContactMethods are the types of contact methods whose address is only
+// set by the service itself, once the user linked their account.
+var linkedContactMethods = []string{"telegram"}
+
+// Linked reports whether the address of the contact method can only be set by
+// linking the account, rather than by the user.
+func (cm *ContactMethod) Linked() bool {
+	return slices.Contains(linkedContactMethods, cm.Type)
+}
+
+// Trusted reports whether the address can be used to identify or reach the
+// user. Linked contact methods are only trusted once verified.
+func (cm *ContactMethod) Trusted() bool {
+	return cm.Verified || !cm.Linked()
+}
+
+// ContactMethod returns the contact method of the type, or nil if the user
+// has none.
+func (u *User) ContactMethod(t string) *ContactMethod {
+	for _, cm := range u.ContactMethods {
+		if cm.Type == t {
+			return cm
+		}
+	}
+	return nil
+}
+
+func (u *User) Validate() error {
+	if !usernamePattern.MatchString(u.Username) {
+		return fmt.Errorf("%w: username must only contain letters, digits, '.', '_', '@' and '-'", ErrInvalidUser)
+	}
+
+	if u.Email != "" {
+		if _, err := mail.ParseAddress(u.Email); err != nil {
+			return fmt.Errorf("%w: invalid email address %q", ErrInvalidUser, u.Email)
+		}
+	}
+
+	if u.Phone != "" && !phonePattern.MatchString(u.Phone) {
+		return fmt.Errorf("%w: phone number must be in E.164 format, such as +14155550100", ErrInvalidUser)
+	}
+
+	if _, err := time.LoadLocation(u.TimeZone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidUser, u.TimeZone)
+	}
+
+	if !slices.Contains([]Role{RoleAdmin, RoleEditor, RoleViewer}, u.Role) {
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidUser, u.Role)
+	}
+
+	var types []string
+	for _, cm := range u.ContactMethods {
+		if cm.Type == "" || cm.Address == "" {
+			return fmt.Errorf("%w: contact methods need a type and an address", ErrInvalidUser)
+		}
+
+		if slices.Contains(types, cm.Type) {
+			return fmt.Errorf("%w: only one %s contact method is allowed", ErrInvalidUser, cm.Type)
+		}
+		types = append(types, cm.Type)
+	}
+
+	return nil
+}
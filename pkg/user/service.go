@@ -0,0 +1,177 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	mtx    sync.Mutex
+	store  Store
+	worker worker.Worker
+	sms    SMSSender
+	now    func() time.Time
+}
+
+// NewService creates the user service. Phone verification codes are sent by
+// sms, which may be nil when no SMS provider is configured.
+func NewService(store Store, w worker.Worker, sms SMSSender) *Service {
+	return &Service{
+		store:  store,
+		worker: w,
+		sms:    sms,
+		now:    time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, u *User) error {
+	// Verification is never taken from the caller.
+	u.PhoneVerified = false
+	for _, cm := range u.ContactMethods {
+		if cm.Linked() {
+			return fmt.Errorf("%w: %s contact methods can only be set by linking the account", ErrInvalidUser, cm.Type)
+		}
+
+		cm.Verified = false
+		cm.VerifiedAt = nil
+	}
+
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	now := s.now()
+	u.ID = uuid.NewString()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	return s.store.Create(ctx, u)
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*User, error) {
+	return s.store.Get(ctx, id)
+}
+
+func (s *Service) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return s.store.GetByUsername(ctx, username)
+}
+
+// GetByContactMethod returns the user with the address for the type of
+// contact method, such as their Slack member ID. Linked contact methods only
+// match once verified.
+func (s *Service) GetByContactMethod(ctx context.Context, t, address string) (*User, error) {
+	users, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if cm := u.ContactMethod(t); cm != nil && cm.Trusted() && cm.Address == address {
+			return u, nil
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
+
+// GetByPhone returns the user with the verified phone number.
+func (s *Service) GetByPhone(ctx context.Context, phone string) (*User, error) {
+	users, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if u.PhoneVerified && u.Phone == phone {
+			return u, nil
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
+
+func (s *Service) List(ctx context.Context) ([]*User, error) {
+	return s.store.List(ctx)
+}
+
+// Update replaces the details of a user. Verification is kept for the phone
+// number and contact methods whose address is unchanged. Linked contact
+// methods can be removed, but not changed.
+func (s *Service) Update(ctx context.Context, u *User) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	existing, err := s.store.Get(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.PhoneVerified = existing.PhoneVerified && existing.Phone == u.Phone
+
+	for _, cm := range u.ContactMethods {
+		cm.Verified = false
+		cm.VerifiedAt = nil
+
+		old := existing.ContactMethod(cm.Type)
+		if old != nil && old.Address == cm.Address {
+			cm.Verified = old.Verified
+			cm.VerifiedAt = old.VerifiedAt
+		} else if cm.Linked() {
+			return fmt.Errorf("%w: %s contact methods can only be set by linking the account", ErrInvalidUser, cm.Type)
+		}
+	}
+
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	u.CreatedAt = existing.CreatedAt
+	u.UpdatedAt = s.now()
+
+	return s.store.Update(ctx, u)
+}
+
+// VerifyContactMethod sets the address of the contact method of the type,
+// marked as verified, for addresses confirmed by the service itself, such as
+// a chat account the user linked.
+func (s *Service) VerifyContactMethod(ctx context.Context, userID, t, address string) (*User, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	u, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	cm := u.ContactMethod(t)
+	if cm == nil {
+		cm = &ContactMethod{Type: t}
+		u.ContactMethods = append(u.ContactMethods, cm)
+	}
+
+	cm.Address = address
+	cm.Verified = true
+	cm.VerifiedAt = &now
+	u.UpdatedAt = now
+
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
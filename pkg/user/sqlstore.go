@@ -0,0 +1,143 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps users in the SQL database, which the server and workers
+// share.
+type SQLStore struct {
+	db            *sqlstore.DB
+	users         *sqlstore.Table[User]
+	verifications *sqlstore.Table[Verification]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	users, err := sqlstore.NewTable(db, "users", func(u *User) string { return u.ID },
+		sqlstore.Column[User]{Name: "username", Value: usernameKey, Unique: true})
+	if err != nil {
+		return nil, err
+	}
+
+	verifications, err := sqlstore.NewTable(db, "user_verifications", func(v *Verification) string { return v.UserID })
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, users: users, verifications: verifications}, nil
+}
+
+func (st *SQLStore) Create(ctx context.Context, u *User) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := st.checkUsername(ctx, u); err != nil {
+			return err
+		}
+
+		return st.users.Insert(ctx, u)
+	})
+}
+
+func (st *SQLStore) Get(ctx context.Context, id string) (*User, error) {
+	u, err := st.users.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+
+	return u, err
+}
+
+// GetByUsername looks up a user by username, ignoring case.
+func (st *SQLStore) GetByUsername(ctx context.Context, username string) (*User, error) {
+	u, err := st.users.FindOne(ctx, sqlstore.Where{"username": strings.ToLower(username)})
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+
+	return u, err
+}
+
+func (st *SQLStore) List(ctx context.Context) ([]*User, error) {
+	users, err := st.users.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	return users, nil
+}
+
+func (st *SQLStore) Update(ctx context.Context, u *User) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := st.checkUsername(ctx, u); err != nil {
+			return err
+		}
+
+		err := st.users.Update(ctx, u)
+		if errors.Is(err, sqlstore.ErrNotFound) {
+			return ErrUserNotFound
+		}
+
+		return err
+	})
+}
+
+func (st *SQLStore) Delete(ctx context.Context, id string) error {
+	return st.db.InTransaction(ctx, func(ctx context.Context) error {
+		err := st.users.Delete(ctx, id)
+		if errors.Is(err, sqlstore.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = st.verifications.DeleteWhere(ctx, sqlstore.Where{"id": id})
+		return err
+	})
+}
+
+func (st *SQLStore) GetVerification(ctx context.Context, userID string) (*Verification, error) {
+	v, err := st.verifications.Get(ctx, userID)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, nil
+	}
+
+	return v, err
+}
+
+func (st *SQLStore) SaveVerification(ctx context.Context, v *Verification) error {
+	return st.verifications.Save(ctx, v)
+}
+
+// checkUsername returns ErrUsernameTaken when another user has the username
+// of the user.
+func (st *SQLStore) checkUsername(ctx context.Context, u *User) error {
+	existing, err := st.users.FindOne(ctx, sqlstore.Where{"username": usernameKey(u)})
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != u.ID {
+		return ErrUsernameTaken
+	}
+
+	return nil
+}
+
+// usernameKey is the username of the user as looked up, ignoring case.
+func usernameKey(u *User) string {
+	return strings.ToLower(u.Username)
+}
@@ -0,0 +1,18 @@
+package user
+
+import "context"
+
+type Store interface {
+	Create(ctx context.Context, u *User) error
+	Get(ctx context.Context, id string) (*User, error)
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	// List returns all users ordered by username.
+	List(ctx context.Context) ([]*User, error)
+	Update(ctx context.Context, u *User) error
+	Delete(ctx context.Context, id string) error
+
+	// GetVerification returns the pending phone verification of the user,
+	// or nil if there is none.
+	GetVerification(ctx context.Context, userID string) (*Verification, error)
+	SaveVerification(ctx context.Context, v *Verification) error
+}
@@ -0,0 +1,254 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/worker"
+)
+
+// JobSendVerificationCode sends the code of the pending phone verification by
+// SMS, with the user ID as argument.
+const JobSendVerificationCode worker.JobType = "phone_verification_send"
+
+const (
+	// VerificationCodeTTL is how long a verification code can be used.
+	VerificationCodeTTL = 10 * time.Minute
+	// VerificationResendInterval is how long users wait before requesting
+	// another code.
+	VerificationResendInterval = time.Minute
+	// MaxVerificationRequests limits the codes sent to a user per day.
+	MaxVerificationRequests = 5
+	// MaxVerificationAttempts limits the guesses at each code.
+	MaxVerificationAttempts = 5
+
+	verificationCodeDigits = 6
+)
+
+var (
+	ErrNoPhone                 = errors.New("user has no phone number")
+	ErrPhoneAlreadyVerified    = errors.New("phone number is already verified")
+	ErrVerificationRateLimited = errors.New("too many verification codes requested, try again later")
+	ErrInvalidVerificationCode = errors.New("invalid verification code")
+	ErrVerificationCodeExpired = errors.New("verification code has expired, request a new one")
+	ErrNoSMSProvider           = errors.New("no SMS provider is configured")
+)
+
+// SMSSender sends text messages.
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// Verification is a pending phone number verification.
+type Verification struct {
+	UserID string
+	Phone  string
+	// CodeHash is the SHA-256 hash of the code sent to the phone.
+	CodeHash string
+	// Code is only kept until it was sent.
+	Code      string
+	ExpiresAt time.Time
+	Attempts  int
+	// RequestedAt holds when codes were requested during the last day.
+	RequestedAt []time.Time
+}
+
+// RequestPhoneVerification sends a new verification code to the phone
+// number of the user.
+func (s *Service) RequestPhoneVerification(ctx context.Context, userID string) (*Verification, error) {
+	if s.sms == nil {
+		return nil, ErrNoSMSProvider
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	u, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Phone == "" {
+		return nil, ErrNoPhone
+	}
+
+	if u.PhoneVerified {
+		return nil, ErrPhoneAlreadyVerified
+	}
+
+	now := s.now()
+
+	v, err := s.store.GetVerification(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		v = &Verification{UserID: userID}
+	}
+
+	var recent []time.Time
+	for _, t := range v.RequestedAt {
+		if now.Sub(t) < 24*time.Hour {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= MaxVerificationRequests {
+		return nil, ErrVerificationRateLimited
+	}
+
+	if n := len(recent); n > 0 && now.Sub(recent[n-1]) < VerificationResendInterval {
+		return nil, ErrVerificationRateLimited
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		return nil, err
+	}
+
+	v.Phone = u.Phone
+	v.CodeHash = hashCode(code)
+	v.Code = code
+	v.ExpiresAt = now.Add(VerificationCodeTTL)
+	v.Attempts = 0
+	v.RequestedAt = append(recent, now)
+
+	if err := s.store.SaveVerification(ctx, v); err != nil {
+		return nil, err
+	}
+
+	if err := s.worker.Enqueue(ctx, JobSendVerificationCode, userID); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ConfirmPhoneVerification marks the phone number of the user as verified
+// when the code matches the last one sent to it.
+func (s *Service) ConfirmPhoneVerification(ctx context.Context, userID, code string) (*User, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	u, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := s.store.GetVerification(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A code sent to a number the user has since changed is no good.
+	if v == nil || v.CodeHash == "" || v.Phone != u.Phone {
+		return nil, ErrInvalidVerificationCode
+	}
+
+	if !s.now().Before(v.ExpiresAt) || v.Attempts >= MaxVerificationAttempts {
+		return nil, ErrVerificationCodeExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashCode(code)), []byte(v.CodeHash)) != 1 {
+		v.Attempts++
+		if err := s.store.SaveVerification(ctx, v); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidVerificationCode
+	}
+
+	u.PhoneVerified = true
+	u.UpdatedAt = s.now()
+
+	if err := s.store.Update(ctx, u); err != nil {
+		return nil, err
+	}
+
+	// Keep the request times for rate limiting but spend the code.
+	v.CodeHash = ""
+	v.Code = ""
+	if err := s.store.SaveVerification(ctx, v); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// HandleSendVerificationCode sends the code of the pending verification of
+// the user, which is forgotten once sent.
+func (s *Service) HandleSendVerificationCode(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, expected 1 argument\n", job.Type, job.ID)
+		return
+	}
+
+	userID := job.Args[0]
+
+	if s.sms == nil {
+		fmt.Printf("Failed to send verification code to user %s: %s\n", userID, ErrNoSMSProvider)
+		return
+	}
+
+	phone, code, err := s.takeVerificationCode(ctx, userID)
+	if err != nil {
+		fmt.Printf("Failed to send verification code to user %s: %s\n", userID, err)
+		return
+	}
+
+	// The code was already sent, or spent.
+	if code == "" {
+		return
+	}
+
+	body := fmt.Sprintf("Your OnCall verification code is %s", code)
+	if err := s.sms.SendSMS(ctx, phone, body); err != nil {
+		fmt.Printf("Failed to send verification code to user %s: %s\n", userID, err)
+	}
+}
+
+// takeVerificationCode returns the phone number and code of the pending
+// verification of the user, and forgets the code so it is only sent once.
+func (s *Service) takeVerificationCode(ctx context.Context, userID string) (string, string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	v, err := s.store.GetVerification(ctx, userID)
+	if err != nil || v == nil || v.Code == "" {
+		return "", "", err
+	}
+
+	code := v.Code
+	v.Code = ""
+	if err := s.store.SaveVerification(ctx, v); err != nil {
+		return "", "", err
+	}
+
+	return v.Phone, code, nil
+}
+
+func newVerificationCode() (string, error) {
+	max := big.NewInt(1)
+	for range verificationCodeDigits {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	return fmt.Sprintf("%0*d", verificationCodeDigits, n), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
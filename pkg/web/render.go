@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"golang.org/x/sync/singleflight"
 )
@@ -25,7 +26,7 @@ func Renderer(dir, leftDelim, rightDelim string) Middleware {
 	fs := os.DirFS(dir)
 	t, err := compileTemplates(fs, leftDelim, rightDelim)
 	if err != nil {
-		panic(fmt.Sprintf("Renderer: %w", err))
+		panic(fmt.Sprintf("Renderer: %s", err))
 	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -50,7 +51,17 @@ func Renderer(dir, leftDelim, rightDelim string) Middleware {
 func compileTemplates(filesystem fs.FS, leftDelim, rightDelim string) (*template.Template, error) {
 	t := template.New("")
 	t.Delims(leftDelim, rightDelim)
-	err := fs.WalkDir(filesystem, ".", func(path string, d fs.DirEntry, e error) error {
+	err := walkTemplates(filesystem, []string{".html", ".tmpl"}, func(name, data string) error {
+		_, err := t.New(name).Parse(data)
+		return err
+	})
+	return t, err
+}
+
+// walkTemplates calls parse with the name, which is the path without the
+// extension, and contents of every file with one of the extensions.
+func walkTemplates(filesystem fs.FS, exts []string, parse func(name, data string) error) error {
+	return fs.WalkDir(filesystem, ".", func(path string, d fs.DirEntry, e error) error {
 		if e != nil {
 			return nil // skip unreadable or erroneous filesystem items
 		}
@@ -58,7 +69,7 @@ func compileTemplates(filesystem fs.FS, leftDelim, rightDelim string) (*template
 			return nil
 		}
 		ext := filepath.Ext(path)
-		if ext != ".html" && ext != ".tmpl" {
+		if !slices.Contains(exts, ext) {
 			return nil
 		}
 		data, err := fs.ReadFile(filesystem, path)
@@ -66,8 +77,6 @@ func compileTemplates(filesystem fs.FS, leftDelim, rightDelim string) (*template
 			return err
 		}
 		basename := path[:len(path)-len(ext)]
-		_, err = t.New(basename).Parse(string(data))
-		return err
+		return parse(basename, string(data))
 	})
-	return t, err
 }
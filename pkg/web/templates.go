@@ -0,0 +1,49 @@
+package web
+
+import (
+	"html/template"
+	"io"
+	"os"
+	texttemplate "text/template"
+)
+
+// Templates renders the templates of a directory outside of HTTP handlers,
+// such as the bodies of emails. Files ending in .html or .tmpl are HTML
+// templates, and files ending in .txt are plain text templates.
+type Templates struct {
+	html *template.Template
+	text *texttemplate.Template
+}
+
+func LoadTemplates(dir, leftDelim, rightDelim string) (*Templates, error) {
+	filesystem := os.DirFS(dir)
+
+	html, err := compileTemplates(filesystem, leftDelim, rightDelim)
+	if err != nil {
+		return nil, err
+	}
+
+	text := texttemplate.New("")
+	text.Delims(leftDelim, rightDelim)
+	err = walkTemplates(filesystem, []string{".txt"}, func(name, data string) error {
+		_, err := text.New(name).Parse(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Templates{html: html, text: text}, nil
+}
+
+// HTML renders the HTML template with the name, which is its path without
+// the extension.
+func (t *Templates) HTML(w io.Writer, name string, data any) error {
+	return t.html.ExecuteTemplate(w, name, data)
+}
+
+// Text renders the plain text template with the name, which is its path
+// without the extension.
+func (t *Templates) Text(w io.Writer, name string, data any) error {
+	return t.text.ExecuteTemplate(w, name, data)
+}
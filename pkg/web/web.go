@@ -8,6 +8,8 @@ import (
 	"html/template"
 	"net/http"
 	"syscall"
+
+	"github.com/go-chi/chi/v5"
 )
 
 const (
@@ -63,6 +65,13 @@ func (ctx *Context) JSON(status int, data any) {
 	}
 }
 
+// Bytes writes data with the given content type.
+func (ctx *Context) Bytes(status int, contentType string, data []byte) {
+	ctx.Response.Header().Set(headerContentType, contentType)
+	ctx.Response.WriteHeader(status)
+	_, _ = ctx.Response.Write(data)
+}
+
 func (ctx *Context) Redirect(location string, status ...int) {
 	code := http.StatusFound
 	if len(status) == 1 {
@@ -71,3 +80,13 @@ func (ctx *Context) Redirect(location string, status ...int) {
 
 	http.Redirect(ctx.Response, ctx.Request, location, code)
 }
+
+// Param returns the value of a URL parameter from the matched route pattern.
+func (ctx *Context) Param(name string) string {
+	return chi.URLParam(ctx.Request, name)
+}
+
+// Query returns the first value of a query string parameter.
+func (ctx *Context) Query(name string) string {
+	return ctx.Request.URL.Query().Get(name)
+}
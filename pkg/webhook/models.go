@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Trigger is an alert group event that triggers webhooks.
+type Trigger string
+
+const (
+	// TriggerFiring is an alert group being created, or unresolved.
+	TriggerFiring       Trigger = "firing"
+	TriggerAcknowledged Trigger = "acknowledged"
+	TriggerResolved     Trigger = "resolved"
+	TriggerSilenced     Trigger = "silenced"
+	// TriggerEscalationStep is a trigger webhook step of an escalation
+	// chain, which triggers its webhook whatever its triggers are.
+	TriggerEscalationStep Trigger = "escalation_step"
+)
+
+var Triggers = []Trigger{TriggerFiring, TriggerAcknowledged, TriggerResolved, TriggerSilenced, TriggerEscalationStep}
+
+type AuthType string
+
+const (
+	AuthNone   AuthType = ""
+	AuthBasic  AuthType = "basic"
+	AuthBearer AuthType = "bearer"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrResponseNotFound = errors.New("webhook response not found")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+)
+
+// Webhook is an outgoing webhook, sending a request to URL when an alert
+// group event it is triggered by happens.
+type Webhook struct {
+	ID      string
+	Name    string
+	TeamID  string
+	Enabled bool
+	URL     string
+	// Method defaults to POST.
+	Method  string
+	Headers map[string]string
+	// AuthType selects whether Username and Password are sent with basic
+	// authentication, or Token as a bearer token.
+	AuthType AuthType
+	Username string
+	Password string
+	Token    string
+	// Body is a text/template rendered with a Payload. The payload is sent
+	// as JSON when it is empty.
+	Body     string
+	Triggers []Trigger
+	// IntegrationIDs limits the webhook to alert groups of the integrations,
+	// when set.
+	IntegrationIDs []string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Response records a request sent by a webhook, and its response or the
+// error that kept it from getting one.
+type Response struct {
+	ID           string
+	WebhookID    string
+	AlertGroupID string
+	Trigger      Trigger
+	// Attempt counts from 1, and grows as failed requests are retried.
+	Attempt     int
+	Method      string
+	URL         string
+	RequestBody string
+	StatusCode  int
+	// ResponseBody is truncated to MaxLoggedBodySize.
+	ResponseBody string
+	Error        string
+	CreatedAt    time.Time
+}
+
+func (w *Webhook) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("%w: webhook name is required", ErrInvalidWebhook)
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an http or https URL", ErrInvalidWebhook)
+	}
+
+	switch w.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("%w: unsupported method %q", ErrInvalidWebhook, w.Method)
+	}
+
+	for name := range w.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("%w: invalid header name %q", ErrInvalidWebhook, name)
+		}
+	}
+
+	switch w.AuthType {
+	case AuthNone:
+	case AuthBasic:
+		if w.Username == "" {
+			return fmt.Errorf("%w: username is required for basic authentication", ErrInvalidWebhook)
+		}
+	case AuthBearer:
+		if w.Token == "" {
+			return fmt.Errorf("%w: token is required for bearer authentication", ErrInvalidWebhook)
+		}
+	default:
+		return fmt.Errorf("%w: unknown auth type %q", ErrInvalidWebhook, w.AuthType)
+	}
+
+	for _, t := range w.Triggers {
+		if !slices.Contains(Triggers, t) {
+			return fmt.Errorf("%w: unknown trigger %q", ErrInvalidWebhook, t)
+		}
+	}
+
+	if _, err := w.template(); err != nil {
+		return fmt.Errorf("%w: invalid body template: %s", ErrInvalidWebhook, err)
+	}
+
+	return nil
+}
+
+// template parses the body template, nil when the body is empty.
+func (w *Webhook) template() (*template.Template, error) {
+	if w.Body == "" {
+		return nil, nil
+	}
+
+	return template.New("body").Funcs(templateFuncs).Option("missingkey=zero").Parse(w.Body)
+}
+
+// triggeredBy reports whether the webhook is triggered by the event on an
+// alert group of the integration.
+func (w *Webhook) triggeredBy(t Trigger, integrationID string) bool {
+	if !w.Enabled || !slices.Contains(w.Triggers, t) {
+		return false
+	}
+
+	return len(w.IntegrationIDs) == 0 || slices.Contains(w.IntegrationIDs, integrationID)
+}
@@ -0,0 +1,343 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+	"github.com/InariTheFox/oncall/pkg/httpclient"
+	"github.com/InariTheFox/oncall/pkg/worker"
+	"github.com/google/uuid"
+)
+
+// JobSend sends the request of a webhook, with the webhook ID, the alert
+// group ID, the trigger and the attempt as arguments. Retries also carry the
+// ID of the response of the previous attempt, whose request body they send
+// again.
+const JobSend worker.JobType = "webhook_send"
+
+// MaxLoggedBodySize limits the response bodies kept in responses.
+const MaxLoggedBodySize = 4096
+
+// retryDelays are the delays before failed requests are retried, so a
+// request is attempted at most len(retryDelays)+1 times.
+var retryDelays = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute}
+
+// triggers maps the alert group actions to the triggers of webhooks.
+var triggers = map[alertgroup.Action]Trigger{
+	alertgroup.ActionAcknowledge: TriggerAcknowledged,
+	alertgroup.ActionResolve:     TriggerResolved,
+	alertgroup.ActionUnresolve:   TriggerFiring,
+	alertgroup.ActionSilence:     TriggerSilenced,
+}
+
+type Service struct {
+	store       Store
+	alertGroups *alertgroup.Service
+	worker      worker.Worker
+	http        *http.Client
+	now         func() time.Time
+}
+
+// NewService returns the service of outgoing webhooks, whose requests may
+// still reach the internal networks in allowed.
+func NewService(store Store, alertGroups *alertgroup.Service, w worker.Worker, allowed []netip.Prefix) *Service {
+	return &Service{
+		store:       store,
+		alertGroups: alertGroups,
+		worker:      w,
+		http:        httpclient.New(10*time.Second, allowed),
+		now:         time.Now,
+	}
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, w *Webhook) error {
+	if w.Method == "" {
+		w.Method = http.MethodPost
+	}
+
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	w.ID = uuid.NewString()
+	w.CreatedAt = s.now()
+	w.UpdatedAt = w.CreatedAt
+
+	return s.store.CreateWebhook(ctx, w)
+}
+
+func (s *Service) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	return s.store.GetWebhook(ctx, id)
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) ([]*Webhook, error) {
+	return s.store.ListWebhooks(ctx)
+}
+
+// UpdateWebhook replaces the webhook. An empty password or token keeps the
+// current one, so clients need not know the secrets to update a webhook, as
+// long as the webhook keeps sending them to the same host.
+func (s *Service) UpdateWebhook(ctx context.Context, w *Webhook) error {
+	existing, err := s.store.GetWebhook(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+
+	if w.Method == "" {
+		w.Method = http.MethodPost
+	}
+
+	if w.AuthType == existing.AuthType && sameHost(w.URL, existing.URL) {
+		if w.Password == "" {
+			w.Password = existing.Password
+		}
+
+		if w.Token == "" {
+			w.Token = existing.Token
+		}
+	}
+
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	w.CreatedAt = existing.CreatedAt
+	w.UpdatedAt = s.now()
+
+	return s.store.UpdateWebhook(ctx, w)
+}
+
+// sameHost reports whether both URLs point to the same host and port.
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(ua.Host, ub.Host)
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id string) error {
+	return s.store.DeleteWebhook(ctx, id)
+}
+
+// Responses returns the latest requests sent by the webhook, latest first.
+func (s *Service) Responses(ctx context.Context, id string) ([]*Response, error) {
+	if _, err := s.store.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListResponses(ctx, id)
+}
+
+// HandleAlertGroupCreated triggers the webhooks triggered by firing alert
+// groups.
+func (s *Service) HandleAlertGroupCreated(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 1 {
+		fmt.Printf("Invalid %s job %s, missing alert group ID\n", job.Type, job.ID)
+		return
+	}
+
+	s.trigger(ctx, job.Args[0], TriggerFiring)
+}
+
+// HandleStateChanged triggers the webhooks triggered by the new state of the
+// alert group.
+func (s *Service) HandleStateChanged(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	if t, ok := triggers[alertgroup.Action(job.Args[1])]; ok {
+		s.trigger(ctx, job.Args[0], t)
+	}
+}
+
+// HandleTriggerWebhook sends the request of the webhook of a trigger webhook
+// escalation step.
+func (s *Service) HandleTriggerWebhook(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 2 {
+		fmt.Printf("Invalid %s job %s, expected 2 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	s.send(ctx, job.Args[0], job.Args[1], TriggerEscalationStep, 1, "")
+}
+
+func (s *Service) HandleSend(ctx context.Context, job *worker.Job) {
+	if len(job.Args) < 4 {
+		fmt.Printf("Invalid %s job %s, expected 4 arguments\n", job.Type, job.ID)
+		return
+	}
+
+	attempt, err := strconv.Atoi(job.Args[3])
+	if err != nil {
+		fmt.Printf("Invalid %s job %s: %s\n", job.Type, job.ID, err)
+		return
+	}
+
+	previousID := ""
+	if len(job.Args) > 4 {
+		previousID = job.Args[4]
+	}
+
+	s.send(ctx, job.Args[0], job.Args[1], Trigger(job.Args[2]), attempt, previousID)
+}
+
+// trigger queues the requests of the webhooks triggered by the event on the
+// alert group.
+func (s *Service) trigger(ctx context.Context, alertGroupID string, t Trigger) {
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	webhooks, err := s.store.ListWebhooks(ctx)
+	if err != nil {
+		fmt.Printf("Failed to list webhooks: %s\n", err)
+		return
+	}
+
+	for _, w := range webhooks {
+		if !w.triggeredBy(t, g.IntegrationID) {
+			continue
+		}
+
+		if err := s.worker.Enqueue(ctx, JobSend, w.ID, g.ID, string(t), "1"); err != nil {
+			fmt.Printf("Failed to trigger webhook %s for alert group %s: %s\n", w.ID, g.ID, err)
+		}
+	}
+}
+
+// send sends the request of the webhook and records the response, retrying
+// later when the request failed in a way that may pass. The body is rendered
+// on the first attempt only, retries send the body of the previous attempt so
+// the payload describes the alert group as it was when the webhook was
+// triggered.
+func (s *Service) send(ctx context.Context, webhookID, alertGroupID string, t Trigger, attempt int, previousID string) {
+	w, err := s.store.GetWebhook(ctx, webhookID)
+	if err != nil {
+		fmt.Printf("Failed to load webhook %s: %s\n", webhookID, err)
+		return
+	}
+
+	if !w.Enabled {
+		return
+	}
+
+	g, err := s.alertGroups.Get(ctx, alertGroupID)
+	if err != nil {
+		fmt.Printf("Failed to load alert group %s: %s\n", alertGroupID, err)
+		return
+	}
+
+	r := &Response{
+		ID:           uuid.NewString(),
+		WebhookID:    w.ID,
+		AlertGroupID: g.ID,
+		Trigger:      t,
+		Attempt:      attempt,
+		Method:       w.Method,
+		URL:          w.URL,
+		CreatedAt:    s.now(),
+	}
+
+	if previousID != "" {
+		previous, err := s.store.GetResponse(ctx, previousID)
+		if err != nil {
+			fmt.Printf("Failed to load the previous attempt of webhook %s for alert group %s: %s\n", w.ID, g.ID, err)
+			return
+		}
+
+		r.RequestBody = previous.RequestBody
+	} else if r.RequestBody, err = w.render(newPayload(w, g, t, r.CreatedAt)); err != nil {
+		r.Error = fmt.Sprintf("failed to render body: %s", err)
+	}
+
+	retry := false
+	if r.Error == "" {
+		retry = s.do(ctx, w, r)
+	}
+
+	if err := s.store.SaveResponse(ctx, r); err != nil {
+		fmt.Printf("Failed to record response of webhook %s: %s\n", w.ID, err)
+	}
+
+	if !retry || attempt > len(retryDelays) {
+		return
+	}
+
+	if err := s.worker.EnqueueIn(ctx, retryDelays[attempt-1], JobSend, w.ID, g.ID, string(t), strconv.Itoa(attempt+1), r.ID); err != nil {
+		fmt.Printf("Failed to retry webhook %s for alert group %s: %s\n", w.ID, g.ID, err)
+	}
+}
+
+// do sends the request and records its outcome in r, and reports whether it
+// is worth retrying.
+func (s *Service) do(ctx context.Context, w *Webhook, r *Response) bool {
+	var body io.Reader
+	if w.Method != http.MethodGet {
+		body = strings.NewReader(r.RequestBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.Method, w.URL, body)
+	if err != nil {
+		r.Error = err.Error()
+		return false
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "OnCall-Webhook")
+
+	for name, value := range w.Headers {
+		req.Header.Set(name, value)
+	}
+
+	switch w.AuthType {
+	case AuthBasic:
+		req.SetBasicAuth(w.Username, w.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+
+	res, err := s.http.Do(req)
+	if err != nil {
+		r.Error = err.Error()
+		return true
+	}
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(io.LimitReader(res.Body, MaxLoggedBodySize))
+	for !utf8.Valid(b) && len(b) > 0 {
+		b = b[:len(b)-1]
+	}
+
+	r.StatusCode = res.StatusCode
+	r.ResponseBody = string(b)
+
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return false
+	}
+
+	r.Error = fmt.Sprintf("unexpected status %s", res.Status)
+
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
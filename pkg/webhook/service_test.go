@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	store, err := NewSQLStore(sqlstore.InitTestDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore() error = %v", err)
+	}
+
+	return NewService(store, nil, nil, nil)
+}
+
+func TestUpdateWebhookKeepsCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		update       Webhook
+		wantPassword string
+		wantToken    string
+		wantErr      bool
+	}{
+		{
+			name:         "same host keeps the password",
+			update:       Webhook{URL: "https://hooks.example.com/other", AuthType: AuthBasic, Username: "alice"},
+			wantPassword: "secret",
+		},
+		{
+			name:         "new password replaces it",
+			update:       Webhook{URL: "https://hooks.example.com/hook", AuthType: AuthBasic, Username: "alice", Password: "changed"},
+			wantPassword: "changed",
+		},
+		{
+			name:   "new host drops the password",
+			update: Webhook{URL: "https://attacker.example.net/hook", AuthType: AuthBasic, Username: "alice"},
+		},
+		{
+			name:   "new port drops the password",
+			update: Webhook{URL: "https://hooks.example.com:8443/hook", AuthType: AuthBasic, Username: "alice"},
+		},
+		{
+			name:    "new auth type needs a token",
+			update:  Webhook{URL: "https://hooks.example.com/hook", AuthType: AuthBearer},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+
+			w := &Webhook{
+				Name:     "hook",
+				URL:      "https://hooks.example.com/hook",
+				AuthType: AuthBasic,
+				Username: "alice",
+				Password: "secret",
+			}
+			if err := s.CreateWebhook(ctx, w); err != nil {
+				t.Fatalf("CreateWebhook() error = %v", err)
+			}
+
+			update := tt.update
+			update.ID = w.ID
+			update.Name = w.Name
+
+			err := s.UpdateWebhook(ctx, &update)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWebhook) {
+					t.Errorf("UpdateWebhook() error = %v, want %v", err, ErrInvalidWebhook)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("UpdateWebhook() error = %v", err)
+			}
+
+			got, err := s.GetWebhook(ctx, w.ID)
+			if err != nil {
+				t.Fatalf("GetWebhook() error = %v", err)
+			}
+
+			if got.Password != tt.wantPassword || got.Token != tt.wantToken {
+				t.Errorf("credentials = %q, %q, want %q, %q", got.Password, got.Token, tt.wantPassword, tt.wantToken)
+			}
+		})
+	}
+}
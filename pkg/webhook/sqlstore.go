@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/InariTheFox/oncall/pkg/sqlstore"
+)
+
+// SQLStore keeps webhooks and their latest responses in the SQL database,
+// which the server and workers share.
+type SQLStore struct {
+	db        *sqlstore.DB
+	webhooks  *sqlstore.Table[Webhook]
+	responses *sqlstore.Table[Response]
+}
+
+var _ Store = &SQLStore{}
+
+func NewSQLStore(db *sqlstore.DB) (*SQLStore, error) {
+	webhooks, err := sqlstore.NewTable(db, "webhooks", func(w *Webhook) string { return w.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := sqlstore.NewTable(db, "webhook_responses", func(r *Response) string { return r.ID },
+		sqlstore.Column[Response]{Name: "webhook_id", Value: func(r *Response) string { return r.WebhookID }})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLStore{db: db, webhooks: webhooks, responses: responses}, nil
+}
+
+func (s *SQLStore) CreateWebhook(ctx context.Context, w *Webhook) error {
+	return s.webhooks.Insert(ctx, w)
+}
+
+func (s *SQLStore) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	w, err := s.webhooks.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+
+	return w, err
+}
+
+func (s *SQLStore) ListWebhooks(ctx context.Context) ([]*Webhook, error) {
+	webhooks, err := s.webhooks.Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].Name < webhooks[j].Name
+	})
+
+	return webhooks, nil
+}
+
+func (s *SQLStore) UpdateWebhook(ctx context.Context, w *Webhook) error {
+	err := s.webhooks.Update(ctx, w)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+
+	return err
+}
+
+func (s *SQLStore) DeleteWebhook(ctx context.Context, id string) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		err := s.webhooks.Delete(ctx, id)
+		if errors.Is(err, sqlstore.ErrNotFound) {
+			return ErrWebhookNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = s.responses.DeleteWhere(ctx, sqlstore.Where{"webhook_id": id})
+		return err
+	})
+}
+
+func (s *SQLStore) SaveResponse(ctx context.Context, r *Response) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.responses.Insert(ctx, r); err != nil {
+			return err
+		}
+
+		return s.responses.Trim(ctx, sqlstore.Where{"webhook_id": r.WebhookID}, maxResponses)
+	})
+}
+
+func (s *SQLStore) GetResponse(ctx context.Context, id string) (*Response, error) {
+	r, err := s.responses.Get(ctx, id)
+	if errors.Is(err, sqlstore.ErrNotFound) {
+		return nil, ErrResponseNotFound
+	}
+
+	return r, err
+}
+
+func (s *SQLStore) ListResponses(ctx context.Context, webhookID string) ([]*Response, error) {
+	responses, err := s.responses.Find(ctx, sqlstore.Where{"webhook_id": webhookID})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(responses)
+	if responses == nil {
+		responses = []*Response{}
+	}
+
+	return responses, nil
+}
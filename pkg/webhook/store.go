@@ -0,0 +1,20 @@
+package webhook
+
+import "context"
+
+// maxResponses is how many of the latest responses of each webhook the
+// store keeps.
+const maxResponses = 100
+
+type Store interface {
+	CreateWebhook(ctx context.Context, w *Webhook) error
+	GetWebhook(ctx context.Context, id string) (*Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*Webhook, error)
+	UpdateWebhook(ctx context.Context, w *Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+
+	SaveResponse(ctx context.Context, r *Response) error
+	GetResponse(ctx context.Context, id string) (*Response, error)
+	// ListResponses returns the responses of the webhook, latest first.
+	ListResponses(ctx context.Context, webhookID string) ([]*Response, error)
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/InariTheFox/oncall/pkg/alertgroup"
+)
+
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// json encodes a value as JSON, such as a quoted and escaped string to
+	// embed in a JSON body.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Payload is what body templates are rendered with, and what is sent as
+// JSON by webhooks without a body template.
+type Payload struct {
+	Event      *Event       `json:"event"`
+	AlertGroup *AlertGroup  `json:"alert_group"`
+	Webhook    *WebhookInfo `json:"webhook"`
+}
+
+type Event struct {
+	Type Trigger   `json:"type"`
+	Time time.Time `json:"time"`
+}
+
+type AlertGroup struct {
+	ID            string            `json:"id"`
+	Number        int               `json:"number"`
+	IntegrationID string            `json:"integration_id"`
+	TeamID        string            `json:"team_id,omitempty"`
+	Title         string            `json:"title"`
+	Message       string            `json:"message,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	State         string            `json:"state"`
+	AlertsCount   int               `json:"alerts_count"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type WebhookInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newPayload(w *Webhook, g *alertgroup.AlertGroup, t Trigger, now time.Time) *Payload {
+	return &Payload{
+		Event: &Event{Type: t, Time: now},
+		AlertGroup: &AlertGroup{
+			ID:            g.ID,
+			Number:        g.Number,
+			IntegrationID: g.IntegrationID,
+			TeamID:        g.TeamID,
+			Title:         g.Title,
+			Message:       g.Message,
+			Labels:        g.Labels,
+			State:         string(g.State),
+			AlertsCount:   g.AlertsCount,
+			CreatedAt:     g.CreatedAt,
+		},
+		Webhook: &WebhookInfo{ID: w.ID, Name: w.Name},
+	}
+}
+
+// render renders the body of the request of the webhook for the payload.
+func (w *Webhook) render(p *Payload) (string, error) {
+	tmpl, err := w.template()
+	if err != nil {
+		return "", err
+	}
+
+	if tmpl == nil {
+		b, err := json.Marshal(p)
+		return string(b), err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, p); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// delayedQueueExpiry is how long a delay queue may go unused before the
+// broker removes it, on top of the delay of the messages it holds.
+const delayedQueueExpiry = time.Minute
+
 type RabbitWorker struct {
 	client       *amqp.Channel
 	closeChannel chan *amqp.Error
 	context      context.Context
+	exchangeName string
 	handlers     map[JobType]JobHandler
 	pollInterval time.Duration
 	queue        amqp.Queue
@@ -84,7 +91,7 @@ func NewRabbitWorker(host, username, password, vhost string, port int, queueName
 			if !ok {
 				nc = nil
 			} else {
-				fmt.Errorf("Channel closed %w\n", err)
+				fmt.Printf("Channel closed %s\n", err)
 			}
 		}
 	}()
@@ -93,11 +100,71 @@ func NewRabbitWorker(host, username, password, vhost string, port int, queueName
 		client:       ch,
 		closeChannel: nc,
 		context:      ctx,
+		exchangeName: exchangeName,
 		queue:        q,
 		queueName:    queueName,
 	}, nil
 }
 
+func (w *RabbitWorker) Enqueue(ctx context.Context, t JobType, args ...string) error {
+	return w.publish(ctx, w.exchangeName, string(t), "", t, args)
+}
+
+// EnqueueIn publishes the job to a per type and delay queue which holds it
+// for the delay and then dead letters it back onto the worker exchange.
+// Keeping one queue per delay avoids short delays being stuck behind longer
+// ones, as RabbitMQ only expires messages at the head of a queue.
+func (w *RabbitWorker) EnqueueIn(ctx context.Context, delay time.Duration, t JobType, args ...string) error {
+	if delay <= 0 {
+		return w.Enqueue(ctx, t, args...)
+	}
+
+	ttl := delay.Milliseconds()
+	name := fmt.Sprintf("%s.delay.%s.%d", w.queueName, t, ttl)
+
+	_, err := w.client.QueueDeclare(
+		name,
+		true,
+		false,
+		false,
+		false,
+		amqp.Table{
+			"x-message-ttl":             ttl,
+			"x-expires":                 ttl + delayedQueueExpiry.Milliseconds(),
+			"x-dead-letter-exchange":    w.exchangeName,
+			"x-dead-letter-routing-key": string(t),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare delay queue %s, %w", name, err)
+	}
+
+	return w.publish(ctx, "", name, strconv.FormatInt(ttl, 10), t, args)
+}
+
+func (w *RabbitWorker) publish(ctx context.Context, exchange, key, expiration string, t JobType, args []string) error {
+	body, err := json.Marshal(&Job{
+		Args: args,
+		ID:   uuid.New(),
+		Type: t,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s job, %w", t, err)
+	}
+
+	err = w.client.PublishWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Expiration:   expiration,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish %s job, %w", t, err)
+	}
+
+	return nil
+}
+
 func (w *RabbitWorker) RegisterHandler(t JobType, h JobHandler, args any) {
 	if w.handlers == nil {
 		w.handlers = make(map[JobType]JobHandler)
@@ -123,23 +190,29 @@ func (w *RabbitWorker) Run(ctx context.Context) error {
 			nil,
 		)
 		if err != nil {
-			fmt.Errorf("Failed to consume message %w\n", err)
+			fmt.Printf("Failed to consume message %s\n", err)
 		}
 
 		for d := range msg {
-			for t, h := range w.handlers {
-				if d.RoutingKey == string(t) {
-					job := &Job{}
-
-					if err = json.Unmarshal(d.Body, job); err != nil {
-						fmt.Errorf("Unable to deserialize message %s\n", d.Body)
-						return
-					}
-
-					d.Ack(false)
-					h(ctx, job)
-				}
+			h, ok := w.handlers[JobType(d.RoutingKey)]
+			if !ok {
+				// Nobody in this process handles the job, so drop it
+				// instead of leaving it unacknowledged and stalling the
+				// queue.
+				fmt.Printf("No handler registered for job type '%s'\n", d.RoutingKey)
+				d.Nack(false, false)
+				continue
 			}
+
+			job := &Job{}
+
+			if err = json.Unmarshal(d.Body, job); err != nil {
+				fmt.Printf("Unable to deserialize message %s\n", d.Body)
+				return
+			}
+
+			d.Ack(false)
+			h(ctx, job)
 		}
 
 		<-ctx.Done()
@@ -14,4 +14,14 @@ type Job struct {
 
 type JobHandler func(ctx context.Context, job *Job)
 
+// Chain returns a handler running each of the handlers in turn, for job
+// types that several services react to.
+func Chain(handlers ...JobHandler) JobHandler {
+	return func(ctx context.Context, job *Job) {
+		for _, h := range handlers {
+			h(ctx, job)
+		}
+	}
+}
+
 type JobType string
@@ -1,8 +1,19 @@
 package worker
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Worker interface {
+	// Enqueue publishes a job of the given type to be processed as soon as a
+	// consumer is available.
+	Enqueue(ctx context.Context, t JobType, args ...string) error
+
+	// EnqueueIn publishes a job of the given type that will not be delivered
+	// to a consumer until the delay has elapsed.
+	EnqueueIn(ctx context.Context, delay time.Duration, t JobType, args ...string) error
+
 	RegisterHandler(JobType, JobHandler, any)
 
 	Stop(ctx context.Context)